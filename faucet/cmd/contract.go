@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/contract"
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+)
+
+const contractUsage = `usage: faucet contract <command> [flags]
+
+commands:
+  deploy        deploy the faucet contract and print its address
+  init          authorize the funding accounts as operators and deposit funds
+  deploy-claim  deploy the voucher claim contract and print its address`
+
+// contractTimeout bounds the wait for a contract transaction to be mined.
+const contractTimeout = 5 * time.Minute
+
+// runContract runs the subcommands that manage the faucet contract.
+// Transactions are sent from the owner key.
+func runContract(args []string) error {
+	if len(args) == 0 {
+		return errors.New(contractUsage)
+	}
+
+	fs := flag.NewFlagSet("contract "+args[0], flag.ContinueOnError)
+	api := fs.String("api", "http://localhost:8545", "Ethereum API")
+	key := fs.String("private-key", "", "private key of the contract owner")
+	keyFile := fs.String("private-key-file", "", "file with the private key of the contract owner")
+
+	switch args[0] {
+	case "deploy":
+		maxDrip := fs.Uint64("max-drip", 30, "maximum amount of a drip in Ether")
+		period := fs.Duration("period", 24*time.Hour, "length of the recipient window")
+		limit := fs.Uint64("recipient-limit", 90, "amount a recipient receives in a window in Ether")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+
+		return withOwner(*api, *key, *keyFile, func(ctx context.Context, client *ethclient.Client, owner *bind.TransactOpts) error {
+			addr, err := deployFaucet(ctx, client, owner, faucet.TransferAmount(*maxDrip), *period, faucet.TransferAmount(*limit))
+			if err != nil {
+				return err
+			}
+			fmt.Println(addr.Hex())
+			return nil
+		})
+	case "init":
+		addr := fs.String("contract", "", "address of the faucet contract")
+		operators := fs.String("operators", "", "comma-separated addresses of the funding accounts")
+		deposit := fs.Uint64("deposit", 0, "amount deposited to the contract in Ether")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if !common.IsHexAddress(*addr) {
+			return fmt.Errorf("invalid contract address %q", *addr)
+		}
+		ops, err := parseOperators(*operators)
+		if err != nil {
+			return err
+		}
+
+		return withOwner(*api, *key, *keyFile, func(ctx context.Context, client *ethclient.Client, owner *bind.TransactOpts) error {
+			return initFaucet(ctx, client, owner, common.HexToAddress(*addr), ops, faucet.TransferAmount(*deposit))
+		})
+	case "deploy-claim":
+		signer := fs.String("signer", "", "address of the voucher signer, the owner by default")
+		deposit := fs.Uint64("deposit", 0, "amount deposited to the contract in Ether")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *signer != "" && !common.IsHexAddress(*signer) {
+			return fmt.Errorf("invalid signer address %q", *signer)
+		}
+
+		return withOwner(*api, *key, *keyFile, func(ctx context.Context, client *ethclient.Client, owner *bind.TransactOpts) error {
+			s := owner.From
+			if *signer != "" {
+				s = common.HexToAddress(*signer)
+			}
+			addr, err := deployClaim(ctx, client, owner, s, faucet.TransferAmount(*deposit))
+			if err != nil {
+				return err
+			}
+			fmt.Println(addr.Hex())
+			return nil
+		})
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], contractUsage)
+	}
+}
+
+// withOwner connects to the API and calls fn with the transactor of the owner key.
+func withOwner(api, key, keyFile string, fn func(context.Context, *ethclient.Client, *bind.TransactOpts) error) error {
+	if key == "" && keyFile != "" {
+		k, err := os.ReadFile(keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read private key file %s: %w", keyFile, err)
+		}
+		key = string(k)
+	}
+	if key == "" {
+		return fmt.Errorf("no private key")
+	}
+
+	owner, err := data.NewAccount(key)
+	if err != nil {
+		return fmt.Errorf("failed to initialize owner account: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), contractTimeout)
+	defer cancel()
+
+	client, err := ethclient.Dial(api)
+	if err != nil {
+		return fmt.Errorf("failed to connect to API: %w", err)
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chainID: %w", err)
+	}
+
+	opts, err := bind.NewKeyedTransactorWithChainID(owner.PrivateKey, chainID)
+	if err != nil {
+		return err
+	}
+	opts.Context = ctx
+
+	return fn(ctx, client, opts)
+}
+
+func parseOperators(s string) ([]common.Address, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var ops []common.Address
+	for _, a := range strings.Split(s, ",") {
+		if !common.IsHexAddress(a) {
+			return nil, fmt.Errorf("invalid operator address %q", a)
+		}
+		ops = append(ops, common.HexToAddress(a))
+	}
+	return ops, nil
+}
+
+// deployFaucet deploys the faucet contract with the limits. The owner becomes its first operator.
+func deployFaucet(ctx context.Context, client faucet.Backend, owner *bind.TransactOpts, maxDrip *big.Int, period time.Duration, limit *big.Int) (common.Address, error) {
+	_, tx, _, err := contract.DeployFaucet(owner, client, maxDrip, big.NewInt(int64(period/time.Second)), limit)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to deploy faucet contract: %w", err)
+	}
+
+	addr, err := bind.WaitDeployed(ctx, client, tx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to deploy faucet contract: %w", err)
+	}
+	return addr, nil
+}
+
+// initFaucet authorizes the operators that are not authorized yet and deposits the amount to the contract.
+func initFaucet(ctx context.Context, client faucet.Backend, owner *bind.TransactOpts, addr common.Address, operators []common.Address, deposit *big.Int) error {
+	f, err := contract.NewFaucet(addr, client)
+	if err != nil {
+		return err
+	}
+
+	for _, op := range operators {
+		ok, err := f.IsOperator(&bind.CallOpts{Context: ctx}, op)
+		if err != nil {
+			return fmt.Errorf("failed to check operator %s: %w", op, err)
+		}
+		if ok {
+			continue
+		}
+		tx, err := f.SetOperator(owner, op, true)
+		if err != nil {
+			return fmt.Errorf("failed to authorize operator %s: %w", op, err)
+		}
+		if err := waitSuccess(ctx, client, tx); err != nil {
+			return fmt.Errorf("failed to authorize operator %s: %w", op, err)
+		}
+	}
+
+	if deposit.Sign() == 0 {
+		return nil
+	}
+
+	deposited := *owner
+	deposited.Value = deposit
+	tx, err := f.Receive(&deposited)
+	if err != nil {
+		return fmt.Errorf("failed to deposit funds: %w", err)
+	}
+	if err := waitSuccess(ctx, client, tx); err != nil {
+		return fmt.Errorf("failed to deposit funds: %w", err)
+	}
+	return nil
+}
+
+// deployClaim deploys the claim contract with the voucher signer and deposits the amount to it.
+func deployClaim(ctx context.Context, client faucet.Backend, owner *bind.TransactOpts, signer common.Address, deposit *big.Int) (common.Address, error) {
+	_, tx, c, err := contract.DeployClaim(owner, client, signer)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to deploy claim contract: %w", err)
+	}
+
+	addr, err := bind.WaitDeployed(ctx, client, tx)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to deploy claim contract: %w", err)
+	}
+
+	if deposit.Sign() == 0 {
+		return addr, nil
+	}
+
+	deposited := *owner
+	deposited.Value = deposit
+	tx, err = c.Receive(&deposited)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to deposit funds: %w", err)
+	}
+	if err := waitSuccess(ctx, client, tx); err != nil {
+		return common.Address{}, fmt.Errorf("failed to deposit funds: %w", err)
+	}
+	return addr, nil
+}
+
+func waitSuccess(ctx context.Context, client faucet.Backend, tx *types.Transaction) error {
+	receipt, err := bind.WaitMined(ctx, client, tx)
+	if err != nil {
+		return err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return fmt.Errorf("transaction %s reverted", tx.Hash())
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/types"
+)
+
+const keysUsage = `usage: faucet keys <command> [flags]
+
+commands:
+  new     create a key in an encrypted keystore and print its address
+  import  encrypt a plaintext private key into a keystore and print its address
+  derive  print the accounts derived from a BIP-39 mnemonic`
+
+// keysPasswordEnv is the environment variable with the keystore passphrase, the same as the one of the service.
+const keysPasswordEnv = "FAUCET_ETHEREUM_KEYSTORE_PASSWORD"
+
+// runKeys runs the subcommands that manage the faucet keys: encrypted keystores and mnemonics.
+func runKeys(args []string) error {
+	if len(args) == 0 {
+		return errors.New(keysUsage)
+	}
+
+	fs := flag.NewFlagSet("keys "+args[0], flag.ContinueOnError)
+	dir := fs.String("keystore", "./keystore", "directory the keystore file is written to")
+	passwordFile := fs.String("password-file", "", "file with the keystore passphrase, "+keysPasswordEnv+" by default")
+	light := fs.Bool("light", false, "use the light scrypt parameters, for test keys only")
+
+	ks := func() (*keystore.KeyStore, string, error) {
+		password, err := keystorePassword("", *passwordFile, keysPasswordEnv)
+		if err != nil {
+			return nil, "", err
+		}
+		if password == "" {
+			return nil, "", fmt.Errorf("empty keystore password")
+		}
+		n, p := keystore.StandardScryptN, keystore.StandardScryptP
+		if *light {
+			n, p = keystore.LightScryptN, keystore.LightScryptP
+		}
+		return keystore.NewKeyStore(*dir, n, p), password, nil
+	}
+
+	switch args[0] {
+	case "new":
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		store, password, err := ks()
+		if err != nil {
+			return err
+		}
+
+		account, err := store.NewAccount(password)
+		if err != nil {
+			return fmt.Errorf("failed to create key: %w", err)
+		}
+		fmt.Println(account.Address.Hex(), account.URL.Path)
+		return nil
+	case "import":
+		keyFile := fs.String("private-key-file", "", "file with the hex or Lotus exported private key to import")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *keyFile == "" {
+			return fmt.Errorf("no private key file")
+		}
+		k, err := os.ReadFile(*keyFile)
+		if err != nil {
+			return fmt.Errorf("failed to read private key file %s: %w", *keyFile, err)
+		}
+		key, err := data.NewAccount(string(k))
+		if err != nil {
+			return fmt.Errorf("failed to parse private key: %w", err)
+		}
+
+		store, password, err := ks()
+		if err != nil {
+			return err
+		}
+		account, err := store.ImportECDSA(key.PrivateKey, password)
+		if err != nil {
+			return fmt.Errorf("failed to import key: %w", err)
+		}
+		fmt.Println(account.Address.Hex(), account.URL.Path)
+		return nil
+	case "derive":
+		mnemonicFile := fs.String("mnemonic-file", "", "file with the BIP-39 mnemonic")
+		passphrase := fs.Bool("passphrase", false, "read the BIP-39 passphrase from the password file or variable")
+		path := fs.String("path", data.EthereumBasePath, "BIP-44 base path, "+data.FilecoinBasePath+" for Filecoin wallets")
+		count := fs.Int("count", 1, "number of accounts")
+		prefix := fs.String("network-prefix", "t", "prefix of the f4 addresses, f on mainnet")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if *mnemonicFile == "" {
+			return fmt.Errorf("no mnemonic file")
+		}
+		m, err := os.ReadFile(*mnemonicFile)
+		if err != nil {
+			return fmt.Errorf("failed to read mnemonic file %s: %w", *mnemonicFile, err)
+		}
+		var password string
+		if *passphrase {
+			if password, err = keystorePassword("", *passwordFile, keysPasswordEnv); err != nil {
+				return err
+			}
+		}
+
+		derived, err := data.NewMnemonicAccounts(strings.TrimSpace(string(m)), password, *path, *count)
+		if err != nil {
+			return err
+		}
+		for i, account := range derived {
+			fmt.Printf("%s/%d %s %s\n", *path, i, account.Address.Hex(), delegatedAddress(account.Address, *prefix))
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], keysUsage)
+	}
+}
+
+// delegatedAddress returns the f4 address of the Ethereum address with the network prefix.
+func delegatedAddress(addr common.Address, prefix string) string {
+	f4, err := types.EthAddress(addr).ToFilecoinAddress()
+	if err != nil {
+		return ""
+	}
+	return types.FilecoinAddressString(f4, prefix)
+}
+
+// secp256k1Address returns the f1 address of the key of the account with the network prefix.
+func secp256k1Address(account *data.EthereumAccount, prefix string) string {
+	f1, err := account.FilecoinAddress()
+	if err != nil {
+		return ""
+	}
+	return types.FilecoinAddressString(f1, prefix)
+}
@@ -5,24 +5,27 @@ import (
 	"crypto/tls"
 	"expvar"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/ardanlabs/conf/v3"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/filecoin-project/go-address"
 	"github.com/gorilla/handlers"
-	datastore "github.com/ipfs/go-ds-leveldb"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/pkg/errors"
-	ldbopts "github.com/syndtr/goleveldb/leveldb/opt"
 	"go.uber.org/zap"
 
-	"github.com/consensus-shipyard/calibration/faucet/internal/data"
 	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
 	app "github.com/consensus-shipyard/calibration/faucet/internal/http"
+	"github.com/consensus-shipyard/calibration/faucet/internal/store"
+	"github.com/consensus-shipyard/calibration/faucet/internal/types"
 )
 
 var build = "develop"
@@ -36,6 +39,20 @@ func main() {
 	}
 	logging.SetAllLoggers(lvl)
 
+	if len(os.Args) > 1 && os.Args[1] == "contract" {
+		if err := runContract(os.Args[2:]); err != nil {
+			logger.Fatalln("contract: error:", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "keys" {
+		if err := runKeys(os.Args[2:]); err != nil {
+			logger.Fatalln("keys: error:", err)
+		}
+		return
+	}
+
 	if err := run(logger); err != nil {
 		logger.Fatalln("main: error:", err)
 	}
@@ -55,6 +72,14 @@ func run(log *logging.ZapEventLogger) error {
 			Host            string        `conf:"default:0.0.0.0:8000"`
 			BackendHost     string        `conf:"required"`
 			AllowedOrigins  []string      `conf:"required"`
+			// Bearer token of the admin API, which is disabled without one.
+			AdminToken string `conf:"mask"`
+			// Lets the admin API take private keys in request bodies. Only enable it behind TLS.
+			AdminPrivateKeys bool
+			// Addresses or CIDR networks of the reverse proxies in front of the faucet. The IP address of
+			// a requester is read from the forwarded header of the requests they forward.
+			TrustedProxies  []string
+			ForwardedHeader string `conf:"default:X-Forwarded-For"`
 		}
 		TLS struct {
 			Disabled bool   `conf:"default:true"`
@@ -66,13 +91,83 @@ func run(log *logging.ZapEventLogger) error {
 			TotalTransferLimit   uint64 `conf:"default:9000"` // 9000 Ether
 			AddressTransferLimit uint64 `conf:"default:90"`   // 90 Ether
 			TransferAmount       uint64 `conf:"default:30"`   // 30 Ether
+			// Quota reset mode: rolling, daily or weekly.
+			ResetMode     string `conf:"default:rolling"`
+			ResetTimeZone string `conf:"default:UTC"`
+			// Addresses that are never funded.
+			Denylist []string
+			// Reject 0x addresses without an EIP-55 checksum.
+			RequireChecksum bool `conf:"default:false"`
+			// ERC-20 tokens in the SYMBOL:ADDRESS:DECIMALS:AMOUNT:ADDRESS_LIMIT:TOTAL_LIMIT format.
+			Tokens []string
+			// Bundles in the NAME=ASSET[:AMOUNT],ASSET[:AMOUNT] format.
+			Bundles []string
 		}
 		Ethereum struct {
-			API            string `conf:"required"`
-			PrivateKey     string
+			API string
+			// IPC subnet ID of the chain, /r<chain ID> by default.
+			Subnet         string
+			PrivateKey     string `conf:"mask"`
 			PrivateKeyFile string
+			// Additional keys of the funding account pool.
+			PrivateKeys     []string `conf:"mask"`
+			PrivateKeyFiles []string
+			// Encrypted JSON key used instead of the private key.
+			Keystore             string
+			KeystorePassword     string `conf:"mask"`
+			KeystorePasswordFile string
+			// BIP-39 mnemonic the funding accounts are derived from, along the BIP-44 base path.
+			Mnemonic           string `conf:"mask"`
+			MnemonicFile       string
+			MnemonicPassphrase string `conf:"mask"`
+			MnemonicPath       string `conf:"default:m/44'/60'/0'/0"`
+			MnemonicCount      int    `conf:"default:1"`
+			// External signer holding the keys of the signer accounts: Clef or an eth_signTransaction API.
+			SignerURL      string
+			SignerMethod   string `conf:"default:account_signTransaction"`
+			SignerAccounts []string
+		}
+		Filecoin struct {
+			// Lotus API used to fund f1 and f3 addresses.
+			API   string
+			Token string `conf:"mask"`
+		}
+		IPC struct {
+			// Gateway of the parent used to fund recipients on a child subnet.
+			Gateway string
+			// Gateway of the child subnet, the address of Gateway by default.
+			ChildGateway string
+			Subnet       string
+			ChildAPI     string
+		}
+		Contract struct {
+			// Faucet contract the native coin is dispensed through. The funding accounts must be its operators.
+			Address string
+		}
+		Vouchers struct {
+			// Claim contract the vouchers are redeemed at. Its signer must be the first funding account.
+			Contract string
+			TTL      time.Duration `conf:"default:1h"`
+		}
+		Refill struct {
+			// Treasury key that tops up the funding accounts. Refills are disabled without one.
+			TreasuryKey     string `conf:"mask"`
+			TreasuryKeyFile string
+			// Amounts are in Ether.
+			LowWater uint64
+			Amount   uint64
+			DailyCap uint64
+			Interval time.Duration `conf:"default:1m"`
+		}
+		Networks struct {
+			// JSON file with the networks served in multi-network mode.
+			File string
 		}
 		DB struct {
+			// Storage backend: leveldb, badger, sqlite or memory. sqlite keeps the key-value entries in a table
+			// with JSON-decoding views for reporting, and is only built with cgo.
+			Backend string `conf:"default:leveldb,help:leveldb or badger or sqlite (a kv table of JSON blobs with JSON-decoding views; needs cgo) or memory"`
+			// Directory of LevelDB and Badger, database file of SQLite.
 			Path     string `conf:"default:./_db_data"`
 			Readonly bool   `conf:"default:false"`
 		}
@@ -122,61 +217,127 @@ func run(log *logging.ZapEventLogger) error {
 	// =========================================================================
 	// Database Support
 
-	log.Infow("startup", "status", "initializing database support", "path", cfg.DB.Path)
+	log.Infow("startup", "status", "initializing database support", "backend", cfg.DB.Backend, "path", cfg.DB.Path)
 
-	db, err := datastore.NewDatastore(cfg.DB.Path, &datastore.Options{
-		Compression: ldbopts.NoCompression,
-		NoSync:      false,
-		Strict:      ldbopts.StrictAll,
-		ReadOnly:    cfg.DB.Readonly,
+	db, err := store.Open(cfg.DB.Backend, store.Options{
+		Path:     cfg.DB.Path,
+		Readonly: cfg.DB.Readonly,
 	})
 	if err != nil {
-		return fmt.Errorf("couldn't initialize leveldb database: %w", err)
+		return err
 	}
 
 	defer func() {
-		log.Infow("shutdown", "status", "stopping leveldb")
+		log.Infow("shutdown", "status", "stopping "+cfg.DB.Backend)
 		err = db.Close()
 		if err != nil {
 			log.Errorf("closing DB error: %s", err)
 		}
 	}()
 
+	// The background work of the faucet services stops before the database is closed.
+	svcCtx, stopServices := context.WithCancel(ctx)
+	defer stopServices()
+
 	// =========================================================================
-	// Start Ethereum client
+	// Faucet Configuration
 
-	client, err := ethclient.Dial(cfg.Ethereum.API)
+	window, err := faucet.NewWindow(cfg.Faucet.ResetMode, cfg.Faucet.ResetTimeZone)
 	if err != nil {
-		return fmt.Errorf("failed to connect to API: %w", err)
+		return fmt.Errorf("failed to initialize quota window: %w", err)
 	}
 
-	if cfg.Ethereum.PrivateKey == "" {
-		if cfg.Ethereum.PrivateKeyFile == "" {
-			return fmt.Errorf("no private key")
-		}
-		k, err := os.ReadFile(cfg.Ethereum.PrivateKeyFile)
-		if err != nil {
-			return fmt.Errorf("failed to read private key file %s: %w", cfg.Ethereum.PrivateKeyFile, err)
-		}
-		cfg.Ethereum.PrivateKey = string(k)
-	}
-
-	account, err := data.NewAccount(cfg.Ethereum.PrivateKey)
+	denylist, err := parseAddresses(cfg.Faucet.Denylist)
 	if err != nil {
-		return fmt.Errorf("failed to initialize account: %w", err)
+		return fmt.Errorf("failed to parse denylist: %w", err)
 	}
 
-	chainID, err := client.ChainID(ctx)
+	proxies, err := parseNetworks(cfg.Web.TrustedProxies)
 	if err != nil {
-		return fmt.Errorf("failed to get chainID: %w", err)
+		return fmt.Errorf("failed to parse trusted proxies: %w", err)
 	}
 
-	networkID, err := client.NetworkID(ctx)
-	if err != nil {
-		return fmt.Errorf("failed to get networkID: %w", err)
+	base := faucet.Config{
+		AllowedOrigins:   cfg.Web.AllowedOrigins,
+		BackendAddress:   cfg.Web.BackendHost,
+		TrustedProxies:   proxies,
+		ForwardedHeader:  cfg.Web.ForwardedHeader,
+		AdminToken:       cfg.Web.AdminToken,
+		AdminPrivateKeys: cfg.Web.AdminPrivateKeys,
+		Window:           window,
+		Denylist:         denylist,
+		RequireChecksum:  cfg.Faucet.RequireChecksum,
 	}
 
-	log.Infow("startup", "ChainID", chainID, "NetworkID", networkID)
+	// =========================================================================
+	// Start Ethereum clients
+
+	var handler http.Handler
+
+	switch cfg.Networks.File {
+	case "":
+		if cfg.Ethereum.API == "" {
+			return fmt.Errorf("no Ethereum API")
+		}
+		network, err := newNetwork(ctx, log, networkSpec{
+			EthereumAPI:          cfg.Ethereum.API,
+			Subnet:               cfg.Ethereum.Subnet,
+			PrivateKey:           cfg.Ethereum.PrivateKey,
+			PrivateKeyFile:       cfg.Ethereum.PrivateKeyFile,
+			PrivateKeys:          cfg.Ethereum.PrivateKeys,
+			PrivateKeyFiles:      cfg.Ethereum.PrivateKeyFiles,
+			Keystore:             cfg.Ethereum.Keystore,
+			KeystorePassword:     cfg.Ethereum.KeystorePassword,
+			KeystorePasswordFile: cfg.Ethereum.KeystorePasswordFile,
+			Mnemonic:             cfg.Ethereum.Mnemonic,
+			MnemonicFile:         cfg.Ethereum.MnemonicFile,
+			MnemonicPassphrase:   cfg.Ethereum.MnemonicPassphrase,
+			MnemonicPath:         cfg.Ethereum.MnemonicPath,
+			MnemonicCount:        cfg.Ethereum.MnemonicCount,
+			SignerURL:            cfg.Ethereum.SignerURL,
+			SignerMethod:         cfg.Ethereum.SignerMethod,
+			SignerAccounts:       cfg.Ethereum.SignerAccounts,
+			TransferAmount:       cfg.Faucet.TransferAmount,
+			AddressTransferLimit: cfg.Faucet.AddressTransferLimit,
+			TotalTransferLimit:   cfg.Faucet.TotalTransferLimit,
+			Tokens:               cfg.Faucet.Tokens,
+			Bundles:              cfg.Faucet.Bundles,
+			LotusAPI:             cfg.Filecoin.API,
+			LotusToken:           cfg.Filecoin.Token,
+			IPCGateway:           cfg.IPC.Gateway,
+			IPCChildGateway:      cfg.IPC.ChildGateway,
+			IPCSubnet:            cfg.IPC.Subnet,
+			IPCChildAPI:          cfg.IPC.ChildAPI,
+			FaucetContract:       cfg.Contract.Address,
+			ClaimContract:        cfg.Vouchers.Contract,
+			VoucherTTL:           cfg.Vouchers.TTL.String(),
+			TreasuryKey:          cfg.Refill.TreasuryKey,
+			TreasuryKeyFile:      cfg.Refill.TreasuryKeyFile,
+			RefillLowWater:       cfg.Refill.LowWater,
+			RefillAmount:         cfg.Refill.Amount,
+			RefillDailyCap:       cfg.Refill.DailyCap,
+			RefillInterval:       cfg.Refill.Interval.String(),
+		}, base)
+		if err != nil {
+			return err
+		}
+		handler = app.FaucetHandler(svcCtx, log, network.Client, db, build, network.Config)
+	default:
+		specs, err := loadNetworkSpecs(cfg.Networks.File)
+		if err != nil {
+			return fmt.Errorf("failed to load networks: %w", err)
+		}
+		networks := make([]app.Network, 0, len(specs))
+		for _, spec := range specs {
+			network, err := newNetwork(ctx, log, spec, base)
+			if err != nil {
+				return fmt.Errorf("failed to initialize network %s: %w", spec.Name, err)
+			}
+			networks = append(networks, network)
+		}
+		handler = app.NetworksHandler(svcCtx, log, networks, db, build, cfg.Web.AllowedOrigins, cfg.Web.BackendHost,
+			app.Proxies{Networks: proxies, Header: cfg.Web.ForwardedHeader})
+	}
 
 	// =========================================================================
 	// Start API Service
@@ -186,6 +347,10 @@ func run(log *logging.ZapEventLogger) error {
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, syscall.SIGINT, syscall.SIGTERM)
 
+	if cfg.Web.AdminToken != "" && cfg.Web.AdminPrivateKeys && cfg.TLS.Disabled {
+		log.Warnw("startup", "status", "the admin API takes private keys without TLS, they are sent in clear text")
+	}
+
 	var tlsConfig *tls.Config
 	if !cfg.TLS.Disabled {
 		log.Infow("startup", "status", "initializing TLS")
@@ -200,17 +365,9 @@ func run(log *logging.ZapEventLogger) error {
 	}
 
 	api := http.Server{
-		TLSConfig: tlsConfig,
-		Addr:      cfg.Web.Host,
-		Handler: handlers.RecoveryHandler()(app.FaucetHandler(log, client, db, build, &faucet.Config{
-			AllowedOrigins:       cfg.Web.AllowedOrigins,
-			BackendAddress:       cfg.Web.BackendHost,
-			TotalTransferLimit:   cfg.Faucet.TotalTransferLimit,
-			AddressTransferLimit: cfg.Faucet.AddressTransferLimit,
-			TransferAmount:       cfg.Faucet.TransferAmount,
-			Account:              account,
-			ChainID:              chainID,
-		})),
+		TLSConfig:    tlsConfig,
+		Addr:         cfg.Web.Host,
+		Handler:      handlers.RecoveryHandler()(handler),
 		ReadTimeout:  cfg.Web.ReadTimeout,
 		WriteTimeout: cfg.Web.WriteTimeout,
 		IdleTimeout:  cfg.Web.IdleTimeout,
@@ -252,3 +409,100 @@ func run(log *logging.ZapEventLogger) error {
 	}
 	return nil
 }
+
+func parseAddresses(addrs []string) ([]address.Address, error) {
+	parsed := make([]address.Address, 0, len(addrs))
+	for _, a := range addrs {
+		addr, err := types.ParseAddress(a)
+		if err != nil {
+			return nil, err
+		}
+		if addr.IsFilecoin() {
+			parsed = append(parsed, addr.Filecoin)
+			continue
+		}
+		filecoinAddr, err := types.EthAddress(addr.Eth).ToFilecoinAddress()
+		if err != nil {
+			return nil, fmt.Errorf("invalid address %s: %w", a, err)
+		}
+		parsed = append(parsed, filecoinAddr)
+	}
+	return parsed, nil
+}
+
+// parseNetworks parses IP addresses and CIDR networks. An address is the network of the address alone.
+func parseNetworks(specs []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(specs))
+	for _, spec := range specs {
+		if ip := net.ParseIP(spec); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid network %q: %w", spec, err)
+		}
+		networks = append(networks, n)
+	}
+	return networks, nil
+}
+
+func parseTokens(specs []string) ([]faucet.TokenConfig, error) {
+	tokens := make([]faucet.TokenConfig, 0, len(specs))
+	for _, spec := range specs {
+		parts := strings.Split(spec, ":")
+		if len(parts) != 6 {
+			return nil, fmt.Errorf("invalid token %q: expected SYMBOL:ADDRESS:DECIMALS:AMOUNT:ADDRESS_LIMIT:TOTAL_LIMIT", spec)
+		}
+		if !common.IsHexAddress(parts[1]) {
+			return nil, fmt.Errorf("invalid token %q address %s", parts[0], parts[1])
+		}
+		decimals, err := strconv.ParseUint(parts[2], 10, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token %q decimals: %w", parts[0], err)
+		}
+		var limits [3]uint64
+		for i := range limits {
+			if limits[i], err = strconv.ParseUint(parts[3+i], 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid token %q amount: %w", parts[0], err)
+			}
+		}
+		tokens = append(tokens, faucet.TokenConfig{
+			Symbol:               parts[0],
+			Address:              common.HexToAddress(parts[1]),
+			Decimals:             uint8(decimals),
+			TransferAmount:       limits[0],
+			AddressTransferLimit: limits[1],
+			TotalTransferLimit:   limits[2],
+		})
+	}
+	return tokens, nil
+}
+
+func parseBundles(specs []string) ([]faucet.BundleConfig, error) {
+	bundles := make([]faucet.BundleConfig, 0, len(specs))
+	for _, spec := range specs {
+		name, legs, ok := strings.Cut(spec, "=")
+		if !ok || name == "" || legs == "" {
+			return nil, fmt.Errorf("invalid bundle %q: expected NAME=ASSET[:AMOUNT],ASSET[:AMOUNT]", spec)
+		}
+		bundle := faucet.BundleConfig{Name: name}
+		for _, leg := range strings.Split(legs, ",") {
+			asset, amount, hasAmount := strings.Cut(leg, ":")
+			l := faucet.BundleLeg{Asset: asset}
+			if hasAmount {
+				var err error
+				if l.Amount, err = strconv.ParseUint(amount, 10, 64); err != nil {
+					return nil, fmt.Errorf("invalid bundle %q amount: %w", name, err)
+				}
+			}
+			bundle.Legs = append(bundle.Legs, l)
+		}
+		bundles = append(bundles, bundle)
+	}
+	return bundles, nil
+}
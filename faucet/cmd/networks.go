@@ -0,0 +1,529 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	logging "github.com/ipfs/go-log/v2"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	app "github.com/consensus-shipyard/calibration/faucet/internal/http"
+	"github.com/consensus-shipyard/calibration/faucet/internal/lotus"
+	"github.com/consensus-shipyard/calibration/faucet/internal/types"
+)
+
+// networkSpec is the configuration of a network served by the faucet.
+type networkSpec struct {
+	Name                 string   `json:"name"`
+	EthereumAPI          string   `json:"ethereum_api"`
+	ChainID              uint64   `json:"chain_id"`
+	Subnet               string   `json:"subnet"`
+	PrivateKey           string   `json:"private_key"`
+	PrivateKeyFile       string   `json:"private_key_file"`
+	PrivateKeys          []string `json:"private_keys"`
+	PrivateKeyFiles      []string `json:"private_key_files"`
+	Keystore             string   `json:"keystore"`
+	KeystorePasswordFile string   `json:"keystore_password_file"`
+	KeystorePasswordEnv  string   `json:"keystore_password_env"`
+	KeystorePassword     string   `json:"-"`
+	Mnemonic             string   `json:"mnemonic"`
+	MnemonicFile         string   `json:"mnemonic_file"`
+	MnemonicPassphrase   string   `json:"mnemonic_passphrase"`
+	MnemonicPath         string   `json:"mnemonic_path"`
+	MnemonicCount        int      `json:"mnemonic_count"`
+	SignerURL            string   `json:"signer_url"`
+	SignerMethod         string   `json:"signer_method"`
+	SignerAccounts       []string `json:"signer_accounts"`
+	TransferAmount       uint64   `json:"transfer_amount"`
+	AddressTransferLimit uint64   `json:"address_transfer_limit"`
+	TotalTransferLimit   uint64   `json:"total_transfer_limit"`
+	Tokens               []string `json:"tokens"`
+	Bundles              []string `json:"bundles"`
+	LotusAPI             string   `json:"lotus_api"`
+	LotusToken           string   `json:"lotus_token"`
+	IPCGateway           string   `json:"ipc_gateway"`
+	IPCChildGateway      string   `json:"ipc_child_gateway"`
+	IPCSubnet            string   `json:"ipc_subnet"`
+	IPCChildAPI          string   `json:"ipc_child_api"`
+	FaucetContract       string   `json:"faucet_contract"`
+	ClaimContract        string   `json:"claim_contract"`
+	VoucherTTL           string   `json:"voucher_ttl"`
+	TreasuryKey          string   `json:"treasury_private_key"`
+	TreasuryKeyFile      string   `json:"treasury_private_key_file"`
+	RefillLowWater       uint64   `json:"refill_low_water"`
+	RefillAmount         uint64   `json:"refill_amount"`
+	RefillDailyCap       uint64   `json:"refill_daily_cap"`
+	RefillInterval       string   `json:"refill_interval"`
+}
+
+var (
+	networkNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`)
+	reservedNames     = map[string]bool{
+		"fund": true, "accounts": true, "admin": true, "crossnet": true, "drips": true, "vouchers": true, "convert": true, "readiness": true, "liveness": true,
+		"js": true, "css": true, "assets": true,
+	}
+)
+
+func loadNetworkSpecs(path string) ([]networkSpec, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var specs []networkSpec
+	if err := json.Unmarshal(b, &specs); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", path, err)
+	}
+	if len(specs) == 0 {
+		return nil, fmt.Errorf("no networks in %s", path)
+	}
+
+	names := make(map[string]bool, len(specs))
+	for _, spec := range specs {
+		if !networkNameRegexp.MatchString(spec.Name) || reservedNames[spec.Name] {
+			return nil, fmt.Errorf("invalid network name %q", spec.Name)
+		}
+		if names[spec.Name] {
+			return nil, fmt.Errorf("duplicate network name %q", spec.Name)
+		}
+		if spec.EthereumAPI == "" {
+			return nil, fmt.Errorf("no Ethereum API for network %s", spec.Name)
+		}
+		names[spec.Name] = true
+	}
+
+	return specs, nil
+}
+
+// newNetwork connects to the network API and initializes the faucet configuration of the network.
+func newNetwork(ctx context.Context, log *logging.ZapEventLogger, spec networkSpec, base faucet.Config) (app.Network, error) {
+	client, err := ethclient.Dial(spec.EthereumAPI)
+	if err != nil {
+		return app.Network{}, fmt.Errorf("failed to connect to API: %w", err)
+	}
+
+	accounts, err := loadAccounts(spec)
+	if err != nil {
+		return app.Network{}, err
+	}
+
+	signers, err := newSigners(ctx, spec)
+	if err != nil {
+		return app.Network{}, fmt.Errorf("failed to initialize signers: %w", err)
+	}
+	if spec.LotusAPI != "" && len(accounts) == 0 {
+		// Filecoin messages are signed with the key itself, which the external signer doesn't expose.
+		return app.Network{}, fmt.Errorf("Filecoin funding needs an account with a private key, the accounts of the signer can't sign Filecoin messages")
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return app.Network{}, fmt.Errorf("failed to get chainID: %w", err)
+	}
+
+	if spec.ChainID != 0 && chainID.Cmp(new(big.Int).SetUint64(spec.ChainID)) != 0 {
+		return app.Network{}, fmt.Errorf("unexpected chainID %v: expected %d", chainID, spec.ChainID)
+	}
+
+	networkID, err := client.NetworkID(ctx)
+	if err != nil {
+		return app.Network{}, fmt.Errorf("failed to get networkID: %w", err)
+	}
+
+	log.Infow("startup", "Network", spec.Name, "ChainID", chainID, "NetworkID", networkID)
+
+	prefix := types.NetworkPrefix(chainID.Uint64())
+	for _, account := range accounts {
+		log.Infow("startup", "Network", spec.Name, "account", account.Address.Hex(), "type", account.KeyType,
+			"f4", delegatedAddress(account.Address, prefix), "f1", secp256k1Address(account, prefix))
+		if account.LotusSecp256k1() {
+			log.Warnw("the account is a Lotus secp256k1 wallet: EVM transfers are sent from its 0x address, not from the f1 address of the wallet",
+				"Network", spec.Name, "account", account.Address.Hex(), "f1", secp256k1Address(account, prefix))
+		}
+	}
+	for _, signer := range signers {
+		log.Infow("startup", "Network", spec.Name, "account", signer.Address().Hex(), "f4", delegatedAddress(signer.Address(), prefix), "signer", spec.SignerURL)
+	}
+
+	tokens, err := parseTokens(spec.Tokens)
+	if err != nil {
+		return app.Network{}, fmt.Errorf("failed to parse tokens: %w", err)
+	}
+
+	bundles, err := parseBundles(spec.Bundles)
+	if err != nil {
+		return app.Network{}, fmt.Errorf("failed to parse bundles: %w", err)
+	}
+
+	crossNet, err := newCrossNet(spec)
+	if err != nil {
+		return app.Network{}, fmt.Errorf("failed to initialize cross-net funding: %w", err)
+	}
+
+	faucetContract, err := newContract(spec)
+	if err != nil {
+		return app.Network{}, fmt.Errorf("failed to initialize faucet contract: %w", err)
+	}
+
+	refill, err := newRefill(spec)
+	if err != nil {
+		return app.Network{}, fmt.Errorf("failed to initialize treasury refills: %w", err)
+	}
+
+	vouchers, err := newVouchers(spec, accounts, signers, bundles)
+	if err != nil {
+		return app.Network{}, fmt.Errorf("failed to initialize vouchers: %w", err)
+	}
+
+	var subnet types.SubnetID
+	if spec.Subnet != "" {
+		if subnet, err = types.ParseSubnetID(spec.Subnet); err != nil {
+			return app.Network{}, err
+		}
+	}
+
+	cfg := base
+	cfg.TotalTransferLimit = spec.TotalTransferLimit
+	cfg.AddressTransferLimit = spec.AddressTransferLimit
+	cfg.TransferAmount = spec.TransferAmount
+	cfg.Accounts = accounts
+	cfg.Signers = signers
+	cfg.ChainID = chainID
+	cfg.Subnet = subnet
+	cfg.Tokens = tokens
+	cfg.Bundles = bundles
+	cfg.CrossNet = crossNet
+	cfg.Contract = faucetContract
+	cfg.Refill = refill
+	cfg.Vouchers = vouchers
+	if spec.LotusAPI != "" {
+		cfg.Filecoin = lotus.NewClient(spec.LotusAPI, spec.LotusToken)
+	}
+
+	return app.Network{
+		Name:   spec.Name,
+		Client: client,
+		Config: &cfg,
+	}, nil
+}
+
+// loadAccounts returns the funding accounts of the network.
+// The account of the private key or the keystore comes first, followed by the accounts of the additional keys
+// and the accounts derived from the mnemonic.
+func loadAccounts(spec networkSpec) ([]*data.EthereumAccount, error) {
+	if spec.Keystore != "" && (spec.PrivateKey != "" || spec.PrivateKeyFile != "") {
+		return nil, fmt.Errorf("both a keystore and a private key are configured")
+	}
+
+	var accounts []*data.EthereumAccount
+	if spec.Keystore != "" {
+		account, err := loadKeystore(spec)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+
+	keys := spec.PrivateKeys
+	if spec.PrivateKey != "" {
+		keys = append([]string{spec.PrivateKey}, keys...)
+	}
+
+	files := spec.PrivateKeyFiles
+	if spec.PrivateKey == "" && spec.PrivateKeyFile != "" {
+		files = append([]string{spec.PrivateKeyFile}, files...)
+	}
+	for _, file := range files {
+		k, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read private key file %s: %w", file, err)
+		}
+		keys = append(keys, string(k))
+	}
+
+	for i, key := range keys {
+		account, err := data.NewAccount(key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize account %d: %w", len(accounts)+i, err)
+		}
+		accounts = append(accounts, account)
+	}
+
+	derived, err := deriveAccounts(spec)
+	if err != nil {
+		return nil, err
+	}
+	accounts = append(accounts, derived...)
+
+	if len(accounts) == 0 && spec.SignerURL == "" {
+		return nil, fmt.Errorf("no private key or signer")
+	}
+
+	seen := make(map[common.Address]bool, len(accounts))
+	for _, account := range accounts {
+		if seen[account.Address] {
+			return nil, fmt.Errorf("duplicate account %s", account.Address)
+		}
+		seen[account.Address] = true
+	}
+
+	return accounts, nil
+}
+
+// newSigners returns the signers of the accounts held by the external signer, if one is configured.
+func newSigners(ctx context.Context, spec networkSpec) ([]faucet.Signer, error) {
+	if spec.SignerURL == "" {
+		if len(spec.SignerAccounts) != 0 {
+			return nil, fmt.Errorf("no signer URL")
+		}
+		return nil, nil
+	}
+	if len(spec.SignerAccounts) == 0 {
+		return nil, fmt.Errorf("no signer accounts")
+	}
+
+	method, err := faucet.SignMethod(spec.SignerMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := rpc.DialContext(ctx, spec.SignerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to signer: %w", err)
+	}
+
+	signers := make([]faucet.Signer, 0, len(spec.SignerAccounts))
+	for _, a := range spec.SignerAccounts {
+		if !common.IsHexAddress(a) {
+			return nil, fmt.Errorf("invalid signer account %q", a)
+		}
+		signers = append(signers, faucet.NewRemoteSigner(client, method, common.HexToAddress(a)))
+	}
+	return signers, nil
+}
+
+// deriveAccounts derives the accounts of the mnemonic, if one is configured.
+// One account is derived along the Ethereum base path by default.
+func deriveAccounts(spec networkSpec) ([]*data.EthereumAccount, error) {
+	mnemonic := spec.Mnemonic
+	if mnemonic == "" {
+		if spec.MnemonicFile == "" {
+			return nil, nil
+		}
+		m, err := os.ReadFile(spec.MnemonicFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read mnemonic file %s: %w", spec.MnemonicFile, err)
+		}
+		mnemonic = strings.TrimSpace(string(m))
+	}
+
+	path := spec.MnemonicPath
+	if path == "" {
+		path = data.EthereumBasePath
+	}
+	count := spec.MnemonicCount
+	if count == 0 {
+		count = 1
+	}
+
+	derived, err := data.NewMnemonicAccounts(mnemonic, spec.MnemonicPassphrase, path, count)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive accounts: %w", err)
+	}
+	return derived, nil
+}
+
+// loadKeystore decrypts the keystore of the network.
+func loadKeystore(spec networkSpec) (*data.EthereumAccount, error) {
+	keyJSON, err := os.ReadFile(spec.Keystore)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read keystore %s: %w", spec.Keystore, err)
+	}
+
+	password, err := keystorePassword(spec.KeystorePassword, spec.KeystorePasswordFile, spec.KeystorePasswordEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := data.NewKeystoreAccount(keyJSON, password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize keystore account: %w", err)
+	}
+	return account, nil
+}
+
+// keystorePassword returns the passphrase given directly, read from the file or from the environment variable,
+// in this order. A trailing newline of the file is not part of the passphrase.
+func keystorePassword(password, file, env string) (string, error) {
+	switch {
+	case password != "":
+		return password, nil
+	case file != "":
+		p, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read keystore password file %s: %w", file, err)
+		}
+		return strings.TrimRight(string(p), "\r\n"), nil
+	case env != "":
+		p, ok := os.LookupEnv(env)
+		if !ok {
+			return "", fmt.Errorf("keystore password variable %s is not set", env)
+		}
+		return p, nil
+	default:
+		return "", fmt.Errorf("no keystore password")
+	}
+}
+
+// newRefill returns the treasury refill configuration if a treasury key is configured.
+func newRefill(spec networkSpec) (*faucet.RefillConfig, error) {
+	key := spec.TreasuryKey
+	if key == "" {
+		if spec.TreasuryKeyFile == "" {
+			return nil, nil
+		}
+		k, err := os.ReadFile(spec.TreasuryKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read treasury key file %s: %w", spec.TreasuryKeyFile, err)
+		}
+		key = string(k)
+	}
+
+	treasury, err := data.NewAccount(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize treasury account: %w", err)
+	}
+
+	if spec.RefillLowWater == 0 || spec.RefillAmount == 0 {
+		return nil, fmt.Errorf("no refill low-water mark or amount")
+	}
+
+	var interval time.Duration
+	if spec.RefillInterval != "" {
+		if interval, err = time.ParseDuration(spec.RefillInterval); err != nil {
+			return nil, fmt.Errorf("invalid refill interval: %w", err)
+		}
+	}
+
+	return &faucet.RefillConfig{
+		Treasury: treasury,
+		LowWater: spec.RefillLowWater,
+		Amount:   spec.RefillAmount,
+		DailyCap: spec.RefillDailyCap,
+		Interval: interval,
+	}, nil
+}
+
+// newContract returns the faucet contract configuration if a contract is configured.
+func newContract(spec networkSpec) (*faucet.ContractConfig, error) {
+	if spec.FaucetContract == "" {
+		return nil, nil
+	}
+	if !common.IsHexAddress(spec.FaucetContract) {
+		return nil, fmt.Errorf("invalid contract address %s", spec.FaucetContract)
+	}
+	if spec.IPCGateway != "" {
+		return nil, fmt.Errorf("the faucet contract can't be used with cross-net funding")
+	}
+	return &faucet.ContractConfig{Address: common.HexToAddress(spec.FaucetContract)}, nil
+}
+
+// newVouchers returns the voucher configuration if a claim contract is configured.
+// Vouchers are signed by the first funding account, which must be the signer of the contract.
+// The native coin is only granted with vouchers then, so no bundle can include it.
+func newVouchers(spec networkSpec, accounts []*data.EthereumAccount, signers []faucet.Signer, bundles []faucet.BundleConfig) (*faucet.VoucherConfig, error) {
+	if spec.ClaimContract == "" {
+		return nil, nil
+	}
+	// The vouchers are signed by the first account of the pool, local keys coming first.
+	var signer faucet.Signer
+	switch {
+	case len(accounts) != 0:
+		signer = faucet.NewLocalSigner(accounts[0])
+	case len(signers) != 0:
+		signer = signers[0]
+	default:
+		return nil, fmt.Errorf("vouchers need a funding account")
+	}
+	if _, ok := signer.(faucet.TypedDataSigner); !ok {
+		return nil, fmt.Errorf("the signer of %s can't sign the typed data of vouchers", signer.Address())
+	}
+	if !common.IsHexAddress(spec.ClaimContract) {
+		return nil, fmt.Errorf("invalid contract address %s", spec.ClaimContract)
+	}
+	if spec.IPCGateway != "" || spec.FaucetContract != "" {
+		return nil, fmt.Errorf("vouchers can't be used with cross-net funding or a faucet contract")
+	}
+	for _, b := range bundles {
+		for _, leg := range b.Legs {
+			if leg.Asset == "" || strings.EqualFold(leg.Asset, faucet.NativeAsset) {
+				return nil, fmt.Errorf("bundle %s can't include the native coin in the voucher mode", b.Name)
+			}
+		}
+	}
+
+	var ttl time.Duration
+	if spec.VoucherTTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(spec.VoucherTTL); err != nil {
+			return nil, fmt.Errorf("invalid voucher TTL: %w", err)
+		}
+	}
+
+	return &faucet.VoucherConfig{
+		Contract: common.HexToAddress(spec.ClaimContract),
+		Signer:   signer,
+		TTL:      ttl,
+	}, nil
+}
+
+// newCrossNet returns the IPC cross-net funding configuration if a gateway is configured.
+func newCrossNet(spec networkSpec) (*faucet.CrossNetConfig, error) {
+	if spec.IPCGateway == "" {
+		return nil, nil
+	}
+	if !common.IsHexAddress(spec.IPCGateway) {
+		return nil, fmt.Errorf("invalid gateway address %s", spec.IPCGateway)
+	}
+
+	subnet, err := types.ParseSubnetID(spec.IPCSubnet)
+	if err != nil {
+		return nil, err
+	}
+	if subnet.IsRoot() {
+		return nil, fmt.Errorf("subnet %s is not a child subnet", spec.IPCSubnet)
+	}
+
+	var childGateway common.Address
+	if spec.IPCChildGateway != "" {
+		if !common.IsHexAddress(spec.IPCChildGateway) {
+			return nil, fmt.Errorf("invalid child gateway address %s", spec.IPCChildGateway)
+		}
+		childGateway = common.HexToAddress(spec.IPCChildGateway)
+	}
+
+	if spec.IPCChildAPI == "" {
+		return nil, fmt.Errorf("no child subnet API")
+	}
+	child, err := ethclient.Dial(spec.IPCChildAPI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to child subnet API: %w", err)
+	}
+
+	return &faucet.CrossNetConfig{
+		Gateway:      common.HexToAddress(spec.IPCGateway),
+		ChildGateway: childGateway,
+		Subnet:       subnet,
+		Child:        child,
+	}, nil
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: MIT
+pragma solidity 0.8.23;
+
+/// @title Claim
+/// @notice Pays out vouchers signed by the faucet. A voucher is the EIP-712 typed data
+/// Voucher(address recipient,uint256 amount,uint256 nonce,uint256 expiry) in the domain named "FaucetClaim",
+/// version "1", of the chain and the contract. Anyone can redeem a voucher before its expiry,
+/// and every nonce is redeemed once.
+/// @dev Storage layout: slot 0 owner, 1 signer, then the claimed (2) mapping.
+contract Claim {
+    bytes32 private constant DOMAIN_TYPEHASH =
+        keccak256("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)");
+    bytes32 private constant NAME_HASH = keccak256("FaucetClaim");
+    bytes32 private constant VERSION_HASH = keccak256("1");
+    bytes32 private constant VOUCHER_TYPEHASH =
+        keccak256("Voucher(address recipient,uint256 amount,uint256 nonce,uint256 expiry)");
+
+    // Signatures with an s above half the curve order are malleable.
+    uint256 private constant MAX_S = 0x7fffffffffffffffffffffffffffffff5d576e7357a4501ddfe92f46681b20a0;
+
+    address public owner;
+    address private _signer;
+    mapping(uint256 => bool) private _claimed;
+
+    event Claimed(address indexed recipient, uint256 indexed nonce, uint256 amount);
+    event Deposit(address indexed from, uint256 amount);
+    event Withdrawal(address indexed to, uint256 amount);
+    event SignerSet(address indexed signer);
+
+    /// @notice The deployer becomes the owner. The signer is a non-zero address.
+    constructor(address signer) {
+        require(signer != address(0));
+        _signer = signer;
+        emit SignerSet(signer);
+
+        owner = msg.sender;
+    }
+
+    modifier onlyOwner() {
+        require(msg.sender == owner);
+        _;
+    }
+
+    /// @notice Plain transfers are deposits.
+    receive() external payable {
+        emit Deposit(msg.sender, msg.value);
+    }
+
+    /// @notice Pays the amount of the voucher to its recipient. The state is updated before the transfer.
+    function claim(address recipient, uint256 amount, uint256 nonce, uint256 expiry, uint8 v, bytes32 r, bytes32 s)
+        external
+    {
+        require(block.timestamp <= expiry);
+        require(!_claimed[nonce]);
+        require(uint256(s) <= MAX_S);
+
+        bytes32 structHash = keccak256(abi.encode(VOUCHER_TYPEHASH, recipient, amount, nonce, expiry));
+        bytes32 digest = keccak256(abi.encodePacked(hex"1901", DOMAIN_SEPARATOR(), structHash));
+        address recovered = ecrecover(digest, v, r, s);
+        require(recovered != address(0) && recovered == _signer);
+
+        _claimed[nonce] = true;
+        emit Claimed(recipient, nonce, amount);
+
+        (bool ok,) = recipient.call{value: amount}("");
+        require(ok);
+    }
+
+    /// @notice Reports whether the voucher with the nonce was redeemed.
+    function claimed(uint256 nonce) external view returns (bool) {
+        return _claimed[nonce];
+    }
+
+    /// @notice Returns the address that signs the vouchers.
+    function signer() external view returns (address) {
+        return _signer;
+    }
+
+    function setSigner(address signer) external onlyOwner {
+        require(signer != address(0));
+        _signer = signer;
+        emit SignerSet(signer);
+    }
+
+    function withdraw(address to, uint256 amount) external onlyOwner {
+        (bool ok,) = to.call{value: amount}("");
+        require(ok);
+        emit Withdrawal(to, amount);
+    }
+
+    /// @notice Returns the EIP-712 domain separator of the contract.
+    function DOMAIN_SEPARATOR() public view returns (bytes32) {
+        return keccak256(abi.encode(DOMAIN_TYPEHASH, NAME_HASH, VERSION_HASH, block.chainid, address(this)));
+    }
+}
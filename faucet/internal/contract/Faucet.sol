@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: MIT
+pragma solidity 0.8.23;
+
+/// @title Faucet
+/// @notice Dispenses the native coin within on-chain limits. Operators drip funds to recipients,
+/// and every recipient can receive up to recipientLimit in a window of period seconds.
+/// A zero period makes the recipient limit apply to the lifetime of the contract.
+/// @dev Storage layout: slot 0 owner, 1 maxDrip, 2 period, 3 recipientLimit, then the operators (4),
+/// used (5) and windows (6) mappings.
+contract Faucet {
+    address public owner;
+    uint256 private _maxDrip;
+    uint256 private _period;
+    uint256 private _recipientLimit;
+    mapping(address => bool) private _operators;
+    // _used is the amount received by a recipient in the window of _windows.
+    mapping(address => uint256) private _used;
+    mapping(address => uint256) private _windows;
+
+    event Drip(address indexed recipient, address indexed operator, uint256 amount);
+    event Deposit(address indexed from, uint256 amount);
+    event Withdrawal(address indexed to, uint256 amount);
+    event OperatorSet(address indexed operator, bool enabled);
+    event LimitsSet(uint256 maxDrip, uint256 period, uint256 recipientLimit);
+
+    /// @notice The deployer becomes the owner and the first operator.
+    constructor(uint256 maxDrip, uint256 period, uint256 recipientLimit) {
+        _maxDrip = maxDrip;
+        _period = period;
+        _recipientLimit = recipientLimit;
+        emit LimitsSet(maxDrip, period, recipientLimit);
+
+        owner = msg.sender;
+        _operators[msg.sender] = true;
+        emit OperatorSet(msg.sender, true);
+    }
+
+    modifier onlyOwner() {
+        require(msg.sender == owner);
+        _;
+    }
+
+    /// @notice Plain transfers are deposits.
+    receive() external payable {
+        emit Deposit(msg.sender, msg.value);
+    }
+
+    /// @notice Sends the amount to the recipient. The state is updated before the transfer.
+    function drip(address recipient, uint256 amount) external {
+        require(_operators[msg.sender]);
+        require(allowed(recipient, amount));
+
+        _used[recipient] = dripped(recipient) + amount;
+        _windows[recipient] = window();
+        emit Drip(recipient, msg.sender, amount);
+
+        (bool ok,) = recipient.call{value: amount}("");
+        require(ok);
+    }
+
+    /// @notice Reports whether a drip of the amount to the recipient is within the limits and the balance.
+    function canDrip(address recipient, uint256 amount) external view returns (bool) {
+        return allowed(recipient, amount);
+    }
+
+    /// @notice Returns the amount received by the recipient in the current window.
+    function dripped(address recipient) public view returns (uint256) {
+        if (_windows[recipient] != window()) {
+            return 0;
+        }
+        return _used[recipient];
+    }
+
+    function setOperator(address operator, bool enabled) external onlyOwner {
+        _operators[operator] = enabled;
+        emit OperatorSet(operator, enabled);
+    }
+
+    function isOperator(address operator) external view returns (bool) {
+        return _operators[operator];
+    }
+
+    function setLimits(uint256 maxDrip, uint256 period, uint256 recipientLimit) external onlyOwner {
+        _maxDrip = maxDrip;
+        _period = period;
+        _recipientLimit = recipientLimit;
+        emit LimitsSet(maxDrip, period, recipientLimit);
+    }
+
+    function limits() external view returns (uint256 maxDrip, uint256 period, uint256 recipientLimit) {
+        return (_maxDrip, _period, _recipientLimit);
+    }
+
+    function withdraw(address to, uint256 amount) external onlyOwner {
+        (bool ok,) = to.call{value: amount}("");
+        require(ok);
+        emit Withdrawal(to, amount);
+    }
+
+    /// @dev The window is the block timestamp divided by the period, or 0 if the period is 0,
+    /// as the EVM division by zero is 0.
+    function window() private view returns (uint256) {
+        if (_period == 0) {
+            return 0;
+        }
+        return block.timestamp / _period;
+    }
+
+    function allowed(address recipient, uint256 amount) private view returns (bool) {
+        uint256 received = dripped(recipient);
+        return received <= _recipientLimit && amount <= _recipientLimit - received && amount <= _maxDrip
+            && amount <= address(this).balance;
+    }
+}
@@ -0,0 +1,999 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// ClaimMetaData contains all meta data concerning the Claim contract.
+var ClaimMetaData = &bind.MetaData{
+	ABI: "[{\"type\":\"constructor\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"signer\",\"type\":\"address\"}]},{\"type\":\"receive\",\"stateMutability\":\"payable\"},{\"type\":\"function\",\"name\":\"claim\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"recipient\",\"type\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\"},{\"name\":\"nonce\",\"type\":\"uint256\"},{\"name\":\"expiry\",\"type\":\"uint256\"},{\"name\":\"v\",\"type\":\"uint8\"},{\"name\":\"r\",\"type\":\"bytes32\"},{\"name\":\"s\",\"type\":\"bytes32\"}],\"outputs\":[]},{\"type\":\"function\",\"name\":\"claimed\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"nonce\",\"type\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}]},{\"type\":\"function\",\"name\":\"signer\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\"}]},{\"type\":\"function\",\"name\":\"setSigner\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"signer\",\"type\":\"address\"}],\"outputs\":[]},{\"type\":\"function\",\"name\":\"owner\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\"}]},{\"type\":\"function\",\"name\":\"withdraw\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"to\",\"type\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\"}],\"outputs\":[]},{\"type\":\"function\",\"name\":\"DOMAIN_SEPARATOR\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"bytes32\"}]},{\"type\":\"event\",\"name\":\"Claimed\",\"anonymous\":false,\"inputs\":[{\"name\":\"recipient\",\"type\":\"address\",\"indexed\":true},{\"name\":\"nonce\",\"type\":\"uint256\",\"indexed\":true},{\"name\":\"amount\",\"type\":\"uint256\",\"indexed\":false}]},{\"type\":\"event\",\"name\":\"Deposit\",\"anonymous\":false,\"inputs\":[{\"name\":\"from\",\"type\":\"address\",\"indexed\":true},{\"name\":\"amount\",\"type\":\"uint256\",\"indexed\":false}]},{\"type\":\"event\",\"name\":\"Withdrawal\",\"anonymous\":false,\"inputs\":[{\"name\":\"to\",\"type\":\"address\",\"indexed\":true},{\"name\":\"amount\",\"type\":\"uint256\",\"indexed\":false}]},{\"type\":\"event\",\"name\":\"SignerSet\",\"anonymous\":false,\"inputs\":[{\"name\":\"signer\",\"type\":\"address\",\"indexed\":true}]}]",
+	Bin: "0x3463000000675760208038036000396000518060a01c6300000067578015630000006757806001557f9eaa897564d022fb8c5efaf0acdb5d9d27b440b2aad44400b6e1c702e65b9ed3600080a233600055630000006c60010180602001380380916000396000f35b600080fd5b3615630000007f5734630000007a5736600411630000007a5760003560e01c8063701422691463000000ac578063dbe7e3bd1463000001e8578063238ac93314630000020b5780636c19e7831463000002155780638da5cb5b14630000026c578063f3fef3a31463000002765780633644e5151463000002dc575b600080fd5b34600052337fe1fffcc4923d04b559f4d29a8bfc6cda04eb5b0d3c460751c2402c5c5cc9109c60206000a2005b5060e43610630000007a5760043560a01c630000007a576064354211630000007a57604435600052600260205260406000208054630000007a5760c4357f7fffffffffffffffffffffffffffffff5d576e7357a4501ddfe92f46681b20a010630000007a577fe70fccf9a2ee99feb5846d53240664b0ec204d53a9fc8f02510688b212f3281761010052608060046101203760a061010020630000015063000002f1565b61190160f01b60005260025260225260426000206103005260606084610320376020610400608061030060015afa15630000007a57610400518015630000007a576001541415630000007a57600190556024356000526044356004357f987d620f307ff6b94d58743cb7a7509f24071586a77759b77c2d4e29f75a2f9a60206000a360008080806024356004355af115630000007a57005b5060243610630000007a57600435600052600260205260406000205463000002e8565b60015463000002e8565b5060243610630000007a57336000541415630000007a576004358060a01c630000007a578015630000007a57806001557f9eaa897564d022fb8c5efaf0acdb5d9d27b440b2aad44400b6e1c702e65b9ed3600080a2005b60005463000002e8565b5060443610630000007a57336000541415630000007a5760008080806024356004358060a01c630000007a575af115630000007a576024356000526004357f7fcf532c15f0a6db0bd6d0e038bea71d30d808c7d98cb3bf7268a95bf5081b6560206000a2005b63000002e863000002f1565b60005260206000f35b7f8b73c3c69bb8fe3d512ecc4cf759cc79239f7b179b0ffacaa9a75d522b39400f610200527ff6f6d343d9b17f2693c1e8790ad532d4e99d1c0e6d834997363d5d6281f5104c610220527fc89efdaa54c0f20c7adf612882df0950f5a951637e0307cdcb4c672f298b8bc6610240524661026052306102805260a0610200209056",
+}
+
+// ClaimABI is the input ABI used to generate the binding from.
+// Deprecated: Use ClaimMetaData.ABI instead.
+var ClaimABI = ClaimMetaData.ABI
+
+// ClaimBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use ClaimMetaData.Bin instead.
+var ClaimBin = ClaimMetaData.Bin
+
+// DeployClaim deploys a new Ethereum contract, binding an instance of Claim to it.
+func DeployClaim(auth *bind.TransactOpts, backend bind.ContractBackend, signer common.Address) (common.Address, *types.Transaction, *Claim, error) {
+	parsed, err := ClaimMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(ClaimBin), backend, signer)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &Claim{ClaimCaller: ClaimCaller{contract: contract}, ClaimTransactor: ClaimTransactor{contract: contract}, ClaimFilterer: ClaimFilterer{contract: contract}}, nil
+}
+
+// Claim is an auto generated Go binding around an Ethereum contract.
+type Claim struct {
+	ClaimCaller     // Read-only binding to the contract
+	ClaimTransactor // Write-only binding to the contract
+	ClaimFilterer   // Log filterer for contract events
+}
+
+// ClaimCaller is an auto generated read-only Go binding around an Ethereum contract.
+type ClaimCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ClaimTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type ClaimTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ClaimFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type ClaimFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// ClaimSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type ClaimSession struct {
+	Contract     *Claim            // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// ClaimCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type ClaimCallerSession struct {
+	Contract *ClaimCaller  // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts // Call options to use throughout this session
+}
+
+// ClaimTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type ClaimTransactorSession struct {
+	Contract     *ClaimTransactor  // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// ClaimRaw is an auto generated low-level Go binding around an Ethereum contract.
+type ClaimRaw struct {
+	Contract *Claim // Generic contract binding to access the raw methods on
+}
+
+// ClaimCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type ClaimCallerRaw struct {
+	Contract *ClaimCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// ClaimTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type ClaimTransactorRaw struct {
+	Contract *ClaimTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewClaim creates a new instance of Claim, bound to a specific deployed contract.
+func NewClaim(address common.Address, backend bind.ContractBackend) (*Claim, error) {
+	contract, err := bindClaim(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Claim{ClaimCaller: ClaimCaller{contract: contract}, ClaimTransactor: ClaimTransactor{contract: contract}, ClaimFilterer: ClaimFilterer{contract: contract}}, nil
+}
+
+// NewClaimCaller creates a new read-only instance of Claim, bound to a specific deployed contract.
+func NewClaimCaller(address common.Address, caller bind.ContractCaller) (*ClaimCaller, error) {
+	contract, err := bindClaim(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ClaimCaller{contract: contract}, nil
+}
+
+// NewClaimTransactor creates a new write-only instance of Claim, bound to a specific deployed contract.
+func NewClaimTransactor(address common.Address, transactor bind.ContractTransactor) (*ClaimTransactor, error) {
+	contract, err := bindClaim(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &ClaimTransactor{contract: contract}, nil
+}
+
+// NewClaimFilterer creates a new log filterer instance of Claim, bound to a specific deployed contract.
+func NewClaimFilterer(address common.Address, filterer bind.ContractFilterer) (*ClaimFilterer, error) {
+	contract, err := bindClaim(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &ClaimFilterer{contract: contract}, nil
+}
+
+// bindClaim binds a generic wrapper to an already deployed contract.
+func bindClaim(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := ClaimMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Claim *ClaimRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Claim.Contract.ClaimCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Claim *ClaimRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Claim.Contract.ClaimTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Claim *ClaimRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Claim.Contract.ClaimTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Claim *ClaimCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Claim.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Claim *ClaimTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Claim.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Claim *ClaimTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Claim.Contract.contract.Transact(opts, method, params...)
+}
+
+// DOMAINSEPARATOR is a free data retrieval call binding the contract method 0x3644e515.
+//
+// Solidity: function DOMAIN_SEPARATOR() view returns(bytes32)
+func (_Claim *ClaimCaller) DOMAINSEPARATOR(opts *bind.CallOpts) ([32]byte, error) {
+	var out []interface{}
+	err := _Claim.contract.Call(opts, &out, "DOMAIN_SEPARATOR")
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// DOMAINSEPARATOR is a free data retrieval call binding the contract method 0x3644e515.
+//
+// Solidity: function DOMAIN_SEPARATOR() view returns(bytes32)
+func (_Claim *ClaimSession) DOMAINSEPARATOR() ([32]byte, error) {
+	return _Claim.Contract.DOMAINSEPARATOR(&_Claim.CallOpts)
+}
+
+// DOMAINSEPARATOR is a free data retrieval call binding the contract method 0x3644e515.
+//
+// Solidity: function DOMAIN_SEPARATOR() view returns(bytes32)
+func (_Claim *ClaimCallerSession) DOMAINSEPARATOR() ([32]byte, error) {
+	return _Claim.Contract.DOMAINSEPARATOR(&_Claim.CallOpts)
+}
+
+// Claimed is a free data retrieval call binding the contract method 0xdbe7e3bd.
+//
+// Solidity: function claimed(uint256 nonce) view returns(bool)
+func (_Claim *ClaimCaller) Claimed(opts *bind.CallOpts, nonce *big.Int) (bool, error) {
+	var out []interface{}
+	err := _Claim.contract.Call(opts, &out, "claimed", nonce)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// Claimed is a free data retrieval call binding the contract method 0xdbe7e3bd.
+//
+// Solidity: function claimed(uint256 nonce) view returns(bool)
+func (_Claim *ClaimSession) Claimed(nonce *big.Int) (bool, error) {
+	return _Claim.Contract.Claimed(&_Claim.CallOpts, nonce)
+}
+
+// Claimed is a free data retrieval call binding the contract method 0xdbe7e3bd.
+//
+// Solidity: function claimed(uint256 nonce) view returns(bool)
+func (_Claim *ClaimCallerSession) Claimed(nonce *big.Int) (bool, error) {
+	return _Claim.Contract.Claimed(&_Claim.CallOpts, nonce)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_Claim *ClaimCaller) Owner(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _Claim.contract.Call(opts, &out, "owner")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_Claim *ClaimSession) Owner() (common.Address, error) {
+	return _Claim.Contract.Owner(&_Claim.CallOpts)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_Claim *ClaimCallerSession) Owner() (common.Address, error) {
+	return _Claim.Contract.Owner(&_Claim.CallOpts)
+}
+
+// Signer is a free data retrieval call binding the contract method 0x238ac933.
+//
+// Solidity: function signer() view returns(address)
+func (_Claim *ClaimCaller) Signer(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _Claim.contract.Call(opts, &out, "signer")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Signer is a free data retrieval call binding the contract method 0x238ac933.
+//
+// Solidity: function signer() view returns(address)
+func (_Claim *ClaimSession) Signer() (common.Address, error) {
+	return _Claim.Contract.Signer(&_Claim.CallOpts)
+}
+
+// Signer is a free data retrieval call binding the contract method 0x238ac933.
+//
+// Solidity: function signer() view returns(address)
+func (_Claim *ClaimCallerSession) Signer() (common.Address, error) {
+	return _Claim.Contract.Signer(&_Claim.CallOpts)
+}
+
+// Claim is a paid mutator transaction binding the contract method 0x70142269.
+//
+// Solidity: function claim(address recipient, uint256 amount, uint256 nonce, uint256 expiry, uint8 v, bytes32 r, bytes32 s) returns()
+func (_Claim *ClaimTransactor) Claim(opts *bind.TransactOpts, recipient common.Address, amount *big.Int, nonce *big.Int, expiry *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _Claim.contract.Transact(opts, "claim", recipient, amount, nonce, expiry, v, r, s)
+}
+
+// Claim is a paid mutator transaction binding the contract method 0x70142269.
+//
+// Solidity: function claim(address recipient, uint256 amount, uint256 nonce, uint256 expiry, uint8 v, bytes32 r, bytes32 s) returns()
+func (_Claim *ClaimSession) Claim(recipient common.Address, amount *big.Int, nonce *big.Int, expiry *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _Claim.Contract.Claim(&_Claim.TransactOpts, recipient, amount, nonce, expiry, v, r, s)
+}
+
+// Claim is a paid mutator transaction binding the contract method 0x70142269.
+//
+// Solidity: function claim(address recipient, uint256 amount, uint256 nonce, uint256 expiry, uint8 v, bytes32 r, bytes32 s) returns()
+func (_Claim *ClaimTransactorSession) Claim(recipient common.Address, amount *big.Int, nonce *big.Int, expiry *big.Int, v uint8, r [32]byte, s [32]byte) (*types.Transaction, error) {
+	return _Claim.Contract.Claim(&_Claim.TransactOpts, recipient, amount, nonce, expiry, v, r, s)
+}
+
+// SetSigner is a paid mutator transaction binding the contract method 0x6c19e783.
+//
+// Solidity: function setSigner(address signer) returns()
+func (_Claim *ClaimTransactor) SetSigner(opts *bind.TransactOpts, signer common.Address) (*types.Transaction, error) {
+	return _Claim.contract.Transact(opts, "setSigner", signer)
+}
+
+// SetSigner is a paid mutator transaction binding the contract method 0x6c19e783.
+//
+// Solidity: function setSigner(address signer) returns()
+func (_Claim *ClaimSession) SetSigner(signer common.Address) (*types.Transaction, error) {
+	return _Claim.Contract.SetSigner(&_Claim.TransactOpts, signer)
+}
+
+// SetSigner is a paid mutator transaction binding the contract method 0x6c19e783.
+//
+// Solidity: function setSigner(address signer) returns()
+func (_Claim *ClaimTransactorSession) SetSigner(signer common.Address) (*types.Transaction, error) {
+	return _Claim.Contract.SetSigner(&_Claim.TransactOpts, signer)
+}
+
+// Withdraw is a paid mutator transaction binding the contract method 0xf3fef3a3.
+//
+// Solidity: function withdraw(address to, uint256 amount) returns()
+func (_Claim *ClaimTransactor) Withdraw(opts *bind.TransactOpts, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Claim.contract.Transact(opts, "withdraw", to, amount)
+}
+
+// Withdraw is a paid mutator transaction binding the contract method 0xf3fef3a3.
+//
+// Solidity: function withdraw(address to, uint256 amount) returns()
+func (_Claim *ClaimSession) Withdraw(to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Claim.Contract.Withdraw(&_Claim.TransactOpts, to, amount)
+}
+
+// Withdraw is a paid mutator transaction binding the contract method 0xf3fef3a3.
+//
+// Solidity: function withdraw(address to, uint256 amount) returns()
+func (_Claim *ClaimTransactorSession) Withdraw(to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Claim.Contract.Withdraw(&_Claim.TransactOpts, to, amount)
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_Claim *ClaimTransactor) Receive(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Claim.contract.RawTransact(opts, nil) // calldata is disallowed for receive function
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_Claim *ClaimSession) Receive() (*types.Transaction, error) {
+	return _Claim.Contract.Receive(&_Claim.TransactOpts)
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_Claim *ClaimTransactorSession) Receive() (*types.Transaction, error) {
+	return _Claim.Contract.Receive(&_Claim.TransactOpts)
+}
+
+// ClaimClaimedIterator is returned from FilterClaimed and is used to iterate over the raw logs and unpacked data for Claimed events raised by the Claim contract.
+type ClaimClaimedIterator struct {
+	Event *ClaimClaimed // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ClaimClaimedIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ClaimClaimed)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ClaimClaimed)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ClaimClaimedIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ClaimClaimedIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ClaimClaimed represents a Claimed event raised by the Claim contract.
+type ClaimClaimed struct {
+	Recipient common.Address
+	Nonce     *big.Int
+	Amount    *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterClaimed is a free log retrieval operation binding the contract event 0x987d620f307ff6b94d58743cb7a7509f24071586a77759b77c2d4e29f75a2f9a.
+//
+// Solidity: event Claimed(address indexed recipient, uint256 indexed nonce, uint256 amount)
+func (_Claim *ClaimFilterer) FilterClaimed(opts *bind.FilterOpts, recipient []common.Address, nonce []*big.Int) (*ClaimClaimedIterator, error) {
+
+	var recipientRule []interface{}
+	for _, recipientItem := range recipient {
+		recipientRule = append(recipientRule, recipientItem)
+	}
+	var nonceRule []interface{}
+	for _, nonceItem := range nonce {
+		nonceRule = append(nonceRule, nonceItem)
+	}
+
+	logs, sub, err := _Claim.contract.FilterLogs(opts, "Claimed", recipientRule, nonceRule)
+	if err != nil {
+		return nil, err
+	}
+	return &ClaimClaimedIterator{contract: _Claim.contract, event: "Claimed", logs: logs, sub: sub}, nil
+}
+
+// WatchClaimed is a free log subscription operation binding the contract event 0x987d620f307ff6b94d58743cb7a7509f24071586a77759b77c2d4e29f75a2f9a.
+//
+// Solidity: event Claimed(address indexed recipient, uint256 indexed nonce, uint256 amount)
+func (_Claim *ClaimFilterer) WatchClaimed(opts *bind.WatchOpts, sink chan<- *ClaimClaimed, recipient []common.Address, nonce []*big.Int) (event.Subscription, error) {
+
+	var recipientRule []interface{}
+	for _, recipientItem := range recipient {
+		recipientRule = append(recipientRule, recipientItem)
+	}
+	var nonceRule []interface{}
+	for _, nonceItem := range nonce {
+		nonceRule = append(nonceRule, nonceItem)
+	}
+
+	logs, sub, err := _Claim.contract.WatchLogs(opts, "Claimed", recipientRule, nonceRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ClaimClaimed)
+				if err := _Claim.contract.UnpackLog(event, "Claimed", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseClaimed is a log parse operation binding the contract event 0x987d620f307ff6b94d58743cb7a7509f24071586a77759b77c2d4e29f75a2f9a.
+//
+// Solidity: event Claimed(address indexed recipient, uint256 indexed nonce, uint256 amount)
+func (_Claim *ClaimFilterer) ParseClaimed(log types.Log) (*ClaimClaimed, error) {
+	event := new(ClaimClaimed)
+	if err := _Claim.contract.UnpackLog(event, "Claimed", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// ClaimDepositIterator is returned from FilterDeposit and is used to iterate over the raw logs and unpacked data for Deposit events raised by the Claim contract.
+type ClaimDepositIterator struct {
+	Event *ClaimDeposit // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ClaimDepositIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ClaimDeposit)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ClaimDeposit)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ClaimDepositIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ClaimDepositIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ClaimDeposit represents a Deposit event raised by the Claim contract.
+type ClaimDeposit struct {
+	From   common.Address
+	Amount *big.Int
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterDeposit is a free log retrieval operation binding the contract event 0xe1fffcc4923d04b559f4d29a8bfc6cda04eb5b0d3c460751c2402c5c5cc9109c.
+//
+// Solidity: event Deposit(address indexed from, uint256 amount)
+func (_Claim *ClaimFilterer) FilterDeposit(opts *bind.FilterOpts, from []common.Address) (*ClaimDepositIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Claim.contract.FilterLogs(opts, "Deposit", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return &ClaimDepositIterator{contract: _Claim.contract, event: "Deposit", logs: logs, sub: sub}, nil
+}
+
+// WatchDeposit is a free log subscription operation binding the contract event 0xe1fffcc4923d04b559f4d29a8bfc6cda04eb5b0d3c460751c2402c5c5cc9109c.
+//
+// Solidity: event Deposit(address indexed from, uint256 amount)
+func (_Claim *ClaimFilterer) WatchDeposit(opts *bind.WatchOpts, sink chan<- *ClaimDeposit, from []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Claim.contract.WatchLogs(opts, "Deposit", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ClaimDeposit)
+				if err := _Claim.contract.UnpackLog(event, "Deposit", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseDeposit is a log parse operation binding the contract event 0xe1fffcc4923d04b559f4d29a8bfc6cda04eb5b0d3c460751c2402c5c5cc9109c.
+//
+// Solidity: event Deposit(address indexed from, uint256 amount)
+func (_Claim *ClaimFilterer) ParseDeposit(log types.Log) (*ClaimDeposit, error) {
+	event := new(ClaimDeposit)
+	if err := _Claim.contract.UnpackLog(event, "Deposit", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// ClaimSignerSetIterator is returned from FilterSignerSet and is used to iterate over the raw logs and unpacked data for SignerSet events raised by the Claim contract.
+type ClaimSignerSetIterator struct {
+	Event *ClaimSignerSet // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ClaimSignerSetIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ClaimSignerSet)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ClaimSignerSet)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ClaimSignerSetIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ClaimSignerSetIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ClaimSignerSet represents a SignerSet event raised by the Claim contract.
+type ClaimSignerSet struct {
+	Signer common.Address
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterSignerSet is a free log retrieval operation binding the contract event 0x9eaa897564d022fb8c5efaf0acdb5d9d27b440b2aad44400b6e1c702e65b9ed3.
+//
+// Solidity: event SignerSet(address indexed signer)
+func (_Claim *ClaimFilterer) FilterSignerSet(opts *bind.FilterOpts, signer []common.Address) (*ClaimSignerSetIterator, error) {
+
+	var signerRule []interface{}
+	for _, signerItem := range signer {
+		signerRule = append(signerRule, signerItem)
+	}
+
+	logs, sub, err := _Claim.contract.FilterLogs(opts, "SignerSet", signerRule)
+	if err != nil {
+		return nil, err
+	}
+	return &ClaimSignerSetIterator{contract: _Claim.contract, event: "SignerSet", logs: logs, sub: sub}, nil
+}
+
+// WatchSignerSet is a free log subscription operation binding the contract event 0x9eaa897564d022fb8c5efaf0acdb5d9d27b440b2aad44400b6e1c702e65b9ed3.
+//
+// Solidity: event SignerSet(address indexed signer)
+func (_Claim *ClaimFilterer) WatchSignerSet(opts *bind.WatchOpts, sink chan<- *ClaimSignerSet, signer []common.Address) (event.Subscription, error) {
+
+	var signerRule []interface{}
+	for _, signerItem := range signer {
+		signerRule = append(signerRule, signerItem)
+	}
+
+	logs, sub, err := _Claim.contract.WatchLogs(opts, "SignerSet", signerRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ClaimSignerSet)
+				if err := _Claim.contract.UnpackLog(event, "SignerSet", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseSignerSet is a log parse operation binding the contract event 0x9eaa897564d022fb8c5efaf0acdb5d9d27b440b2aad44400b6e1c702e65b9ed3.
+//
+// Solidity: event SignerSet(address indexed signer)
+func (_Claim *ClaimFilterer) ParseSignerSet(log types.Log) (*ClaimSignerSet, error) {
+	event := new(ClaimSignerSet)
+	if err := _Claim.contract.UnpackLog(event, "SignerSet", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// ClaimWithdrawalIterator is returned from FilterWithdrawal and is used to iterate over the raw logs and unpacked data for Withdrawal events raised by the Claim contract.
+type ClaimWithdrawalIterator struct {
+	Event *ClaimWithdrawal // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *ClaimWithdrawalIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(ClaimWithdrawal)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(ClaimWithdrawal)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *ClaimWithdrawalIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *ClaimWithdrawalIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// ClaimWithdrawal represents a Withdrawal event raised by the Claim contract.
+type ClaimWithdrawal struct {
+	To     common.Address
+	Amount *big.Int
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterWithdrawal is a free log retrieval operation binding the contract event 0x7fcf532c15f0a6db0bd6d0e038bea71d30d808c7d98cb3bf7268a95bf5081b65.
+//
+// Solidity: event Withdrawal(address indexed to, uint256 amount)
+func (_Claim *ClaimFilterer) FilterWithdrawal(opts *bind.FilterOpts, to []common.Address) (*ClaimWithdrawalIterator, error) {
+
+	var toRule []interface{}
+	for _, toItem := range to {
+		toRule = append(toRule, toItem)
+	}
+
+	logs, sub, err := _Claim.contract.FilterLogs(opts, "Withdrawal", toRule)
+	if err != nil {
+		return nil, err
+	}
+	return &ClaimWithdrawalIterator{contract: _Claim.contract, event: "Withdrawal", logs: logs, sub: sub}, nil
+}
+
+// WatchWithdrawal is a free log subscription operation binding the contract event 0x7fcf532c15f0a6db0bd6d0e038bea71d30d808c7d98cb3bf7268a95bf5081b65.
+//
+// Solidity: event Withdrawal(address indexed to, uint256 amount)
+func (_Claim *ClaimFilterer) WatchWithdrawal(opts *bind.WatchOpts, sink chan<- *ClaimWithdrawal, to []common.Address) (event.Subscription, error) {
+
+	var toRule []interface{}
+	for _, toItem := range to {
+		toRule = append(toRule, toItem)
+	}
+
+	logs, sub, err := _Claim.contract.WatchLogs(opts, "Withdrawal", toRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(ClaimWithdrawal)
+				if err := _Claim.contract.UnpackLog(event, "Withdrawal", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseWithdrawal is a log parse operation binding the contract event 0x7fcf532c15f0a6db0bd6d0e038bea71d30d808c7d98cb3bf7268a95bf5081b65.
+//
+// Solidity: event Withdrawal(address indexed to, uint256 amount)
+func (_Claim *ClaimFilterer) ParseWithdrawal(log types.Log) (*ClaimWithdrawal, error) {
+	event := new(ClaimWithdrawal)
+	if err := _Claim.contract.UnpackLog(event, "Withdrawal", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
@@ -0,0 +1,196 @@
+package contract
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+)
+
+type claimChain struct {
+	*testChain
+	signer *ecdsa.PrivateKey
+	claim  *Claim
+}
+
+func newClaimChain(t *testing.T) *claimChain {
+	c := &claimChain{testChain: newTestChain(t)}
+
+	var err error
+	c.signer, err = crypto.GenerateKey()
+	require.NoError(t, err)
+
+	c.addr, _, _, err = bind.DeployContract(c.owner, mustParseABI(t, ClaimMetaData.ABI), common.FromHex(ClaimMetaData.Bin), c.sim, crypto.PubkeyToAddress(c.signer.PublicKey))
+	require.NoError(t, err)
+	c.sim.Commit()
+	c.claim, err = NewClaim(c.addr, c.sim)
+	require.NoError(t, err)
+
+	c.owner.Value = new(big.Int).Mul(big.NewInt(100), ether)
+	c.mined(c.claim.Receive(c.owner))
+	c.owner.Value = nil
+
+	return c
+}
+
+func (c *claimChain) typedData(recipient common.Address, amount, nonce *big.Int, expiry int64) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Voucher": {
+				{Name: "recipient", Type: "address"},
+				{Name: "amount", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "expiry", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Voucher",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "FaucetClaim",
+			Version:           "1",
+			ChainId:           (*math.HexOrDecimal256)(params.AllEthashProtocolChanges.ChainID),
+			VerifyingContract: c.addr.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"recipient": recipient.Hex(),
+			"amount":    amount.String(),
+			"nonce":     nonce.String(),
+			"expiry":    strconv.FormatInt(expiry, 10),
+		},
+	}
+}
+
+// voucher signs a voucher with the key and returns its v, r and s.
+func (c *claimChain) voucher(key *ecdsa.PrivateKey, recipient common.Address, amount, nonce *big.Int, expiry int64) (uint8, [32]byte, [32]byte) {
+	hash, _, err := apitypes.TypedDataAndHash(c.typedData(recipient, amount, nonce, expiry))
+	require.NoError(c.t, err)
+	sig, err := crypto.Sign(hash, key)
+	require.NoError(c.t, err)
+	return sig[crypto.RecoveryIDOffset] + 27, [32]byte(sig[:32]), [32]byte(sig[32:64])
+}
+
+// expiry returns a time after the latest block.
+func (c *claimChain) expiry(d time.Duration) int64 {
+	return int64(c.sim.Blockchain().CurrentBlock().Time) + int64(d/time.Second)
+}
+
+func TestClaimDeploy(t *testing.T) {
+	c := newClaimChain(t)
+
+	owner, err := c.claim.Owner(nil)
+	require.NoError(t, err)
+	require.Equal(t, c.owner.From, owner)
+
+	signer, err := c.claim.Signer(nil)
+	require.NoError(t, err)
+	require.Equal(t, crypto.PubkeyToAddress(c.signer.PublicKey), signer)
+
+	domain, err := c.claim.DOMAINSEPARATOR(nil)
+	require.NoError(t, err)
+	td := c.typedData(common.Address{}, new(big.Int), new(big.Int), 0)
+	expected, err := td.HashStruct("EIP712Domain", td.Domain.Map())
+	require.NoError(t, err)
+	require.Equal(t, [32]byte(expected), domain)
+}
+
+func TestClaimVoucher(t *testing.T) {
+	c := newClaimChain(t)
+	to := newAddress(t)
+	amount := new(big.Int).Mul(big.NewInt(10), ether)
+	nonce := big.NewInt(42)
+	expiry := c.expiry(time.Hour)
+
+	v, r, s := c.voucher(c.signer, to, amount, nonce, expiry)
+
+	// Anyone can redeem the voucher for the recipient.
+	receipt := c.mined(c.claim.Claim(c.other, to, amount, nonce, big.NewInt(expiry), v, r, s))
+	require.Equal(t, amount, c.balance(t, to))
+
+	require.Len(t, receipt.Logs, 1)
+	event, err := c.claim.ParseClaimed(*receipt.Logs[0])
+	require.NoError(t, err)
+	require.Equal(t, to, event.Recipient)
+	require.Equal(t, nonce, event.Nonce)
+	require.Equal(t, amount, event.Amount)
+
+	claimed, err := c.claim.Claimed(nil, nonce)
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	// A voucher is redeemed once.
+	_, err = c.claim.Claim(c.other, to, amount, nonce, big.NewInt(expiry), v, r, s)
+	require.Error(t, err)
+
+	// The signature covers the whole voucher.
+	nonce = big.NewInt(43)
+	v, r, s = c.voucher(c.signer, to, amount, nonce, expiry)
+	_, err = c.claim.Claim(c.other, to, new(big.Int).Add(amount, big.NewInt(1)), nonce, big.NewInt(expiry), v, r, s)
+	require.Error(t, err)
+	_, err = c.claim.Claim(c.other, newAddress(t), amount, nonce, big.NewInt(expiry), v, r, s)
+	require.Error(t, err)
+
+	claimed, err = c.claim.Claimed(nil, nonce)
+	require.NoError(t, err)
+	require.False(t, claimed)
+}
+
+func TestClaimRejected(t *testing.T) {
+	c := newClaimChain(t)
+	to := newAddress(t)
+	amount := ether
+
+	// Vouchers of other keys are rejected.
+	other, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	expiry := c.expiry(time.Hour)
+	v, r, s := c.voucher(other, to, amount, big.NewInt(1), expiry)
+	_, err = c.claim.Claim(c.other, to, amount, big.NewInt(1), big.NewInt(expiry), v, r, s)
+	require.Error(t, err)
+
+	// Expired vouchers are rejected.
+	expiry = c.expiry(time.Minute)
+	v, r, s = c.voucher(c.signer, to, amount, big.NewInt(2), expiry)
+	require.NoError(t, c.sim.AdjustTime(time.Hour))
+	c.sim.Commit()
+	_, err = c.claim.Claim(c.other, to, amount, big.NewInt(2), big.NewInt(expiry), v, r, s)
+	require.Error(t, err)
+
+	// Vouchers exceeding the balance are rejected.
+	expiry = c.expiry(time.Hour)
+	much := new(big.Int).Mul(big.NewInt(1000), ether)
+	v, r, s = c.voucher(c.signer, to, much, big.NewInt(3), expiry)
+	_, err = c.claim.Claim(c.other, to, much, big.NewInt(3), big.NewInt(expiry), v, r, s)
+	require.Error(t, err)
+
+	// The signer is changed by the owner, which invalidates the vouchers of the previous one.
+	v, r, s = c.voucher(c.signer, to, amount, big.NewInt(4), expiry)
+	_, err = c.claim.SetSigner(c.other, c.other.From)
+	require.Error(t, err)
+	c.mined(c.claim.SetSigner(c.owner, crypto.PubkeyToAddress(other.PublicKey)))
+	_, err = c.claim.Claim(c.other, to, amount, big.NewInt(4), big.NewInt(expiry), v, r, s)
+	require.Error(t, err)
+
+	v, r, s = c.voucher(other, to, amount, big.NewInt(4), expiry)
+	c.mined(c.claim.Claim(c.other, to, amount, big.NewInt(4), big.NewInt(expiry), v, r, s))
+	require.Equal(t, amount, c.balance(t, to))
+
+	// Only the owner withdraws.
+	_, err = c.claim.Withdraw(c.other, c.other.From, ether)
+	require.Error(t, err)
+	c.mined(c.claim.Withdraw(c.owner, to, ether))
+	require.Equal(t, new(big.Int).Mul(big.NewInt(2), ether), c.balance(t, to))
+}
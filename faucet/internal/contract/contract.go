@@ -0,0 +1,11 @@
+// Package contract contains the faucet contracts and their Go bindings.
+//
+// Faucet.sol dispenses funds within on-chain limits, and Claim.sol pays out vouchers signed by the faucet.
+// They are compiled by solc 0.8.23, the version their pragmas pin, and the bindings are generated from
+// the ABI and the bytecode solc writes. Run go generate with that solc on the PATH after changing them,
+// and commit the output: CI fails if it is not the solc output.
+package contract
+
+//go:generate solc --optimize --overwrite --abi --bin -o . Faucet.sol Claim.sol
+//go:generate go run github.com/ethereum/go-ethereum/cmd/abigen --abi Faucet.abi --bin Faucet.bin --pkg contract --type Faucet --out faucet.go
+//go:generate go run github.com/ethereum/go-ethereum/cmd/abigen --abi Claim.abi --bin Claim.bin --pkg contract --type Claim --out claim.go
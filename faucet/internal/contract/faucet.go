@@ -0,0 +1,1207 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package contract
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// FaucetMetaData contains all meta data concerning the Faucet contract.
+var FaucetMetaData = &bind.MetaData{
+	ABI: "[{\"type\":\"constructor\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"maxDrip\",\"type\":\"uint256\"},{\"name\":\"period\",\"type\":\"uint256\"},{\"name\":\"recipientLimit\",\"type\":\"uint256\"}]},{\"type\":\"receive\",\"stateMutability\":\"payable\"},{\"type\":\"function\",\"name\":\"drip\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"recipient\",\"type\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\"}],\"outputs\":[]},{\"type\":\"function\",\"name\":\"canDrip\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"recipient\",\"type\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}]},{\"type\":\"function\",\"name\":\"dripped\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"recipient\",\"type\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"setOperator\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"operator\",\"type\":\"address\"},{\"name\":\"enabled\",\"type\":\"bool\"}],\"outputs\":[]},{\"type\":\"function\",\"name\":\"isOperator\",\"stateMutability\":\"view\",\"inputs\":[{\"name\":\"operator\",\"type\":\"address\"}],\"outputs\":[{\"name\":\"\",\"type\":\"bool\"}]},{\"type\":\"function\",\"name\":\"setLimits\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"maxDrip\",\"type\":\"uint256\"},{\"name\":\"period\",\"type\":\"uint256\"},{\"name\":\"recipientLimit\",\"type\":\"uint256\"}],\"outputs\":[]},{\"type\":\"function\",\"name\":\"limits\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"maxDrip\",\"type\":\"uint256\"},{\"name\":\"period\",\"type\":\"uint256\"},{\"name\":\"recipientLimit\",\"type\":\"uint256\"}]},{\"type\":\"function\",\"name\":\"owner\",\"stateMutability\":\"view\",\"inputs\":[],\"outputs\":[{\"name\":\"\",\"type\":\"address\"}]},{\"type\":\"function\",\"name\":\"withdraw\",\"stateMutability\":\"nonpayable\",\"inputs\":[{\"name\":\"to\",\"type\":\"address\"},{\"name\":\"amount\",\"type\":\"uint256\"}],\"outputs\":[]},{\"type\":\"event\",\"name\":\"Drip\",\"anonymous\":false,\"inputs\":[{\"name\":\"recipient\",\"type\":\"address\",\"indexed\":true},{\"name\":\"operator\",\"type\":\"address\",\"indexed\":true},{\"name\":\"amount\",\"type\":\"uint256\",\"indexed\":false}]},{\"type\":\"event\",\"name\":\"Deposit\",\"anonymous\":false,\"inputs\":[{\"name\":\"from\",\"type\":\"address\",\"indexed\":true},{\"name\":\"amount\",\"type\":\"uint256\",\"indexed\":false}]},{\"type\":\"event\",\"name\":\"Withdrawal\",\"anonymous\":false,\"inputs\":[{\"name\":\"to\",\"type\":\"address\",\"indexed\":true},{\"name\":\"amount\",\"type\":\"uint256\",\"indexed\":false}]},{\"type\":\"event\",\"name\":\"OperatorSet\",\"anonymous\":false,\"inputs\":[{\"name\":\"operator\",\"type\":\"address\",\"indexed\":true},{\"name\":\"enabled\",\"type\":\"bool\",\"indexed\":false}]},{\"type\":\"event\",\"name\":\"LimitsSet\",\"anonymous\":false,\"inputs\":[{\"name\":\"maxDrip\",\"type\":\"uint256\",\"indexed\":false},{\"name\":\"period\",\"type\":\"uint256\",\"indexed\":false},{\"name\":\"recipientLimit\",\"type\":\"uint256\",\"indexed\":false}]}]",
+	Bin: "0x34630000009e5760608038036000396000516001556020516002556040516003557f413cd67004cf87db26db7dda972f65ebdc94ede365868d411463ceee90aeeae160606000a13360005533600052600460205260016040600020556001600052337f1a594081ae893ab78e67d9b9e843547318164322d32c65369d78a96172d9dc8f60206000a263000000a360010180606001380380916000396000f35b600080fd5b3615630000009957346300000094573660041163000000945760003560e01c80639e353a1e1463000000c6578063b085a31b14630000017e5780639531946f1463000001a5578063558a72971463000001c95780636d70f7ae146300000236578063189ae5f2146300000263578063860aefcf1463000002bb5780638da5cb5b1463000002d3578063f3fef3a31463000002dd575b600080fd5b34600052337fe1fffcc4923d04b559f4d29a8bfc6cda04eb5b0d3c460751c2402c5c5cc9109c60206000a2005b50604436106300000094573360005260046020526040600020541563000000945763000001036004358060a01c630000009457602435630000036f565b156300000094576300000119600435630000034c565b60243501600560205260406000205560025442046006602052604060002055602435600052336004357fdfc93ac43234c3993e5d23f2a1a4c52b2ac70f67cf1f534bd7ceb92328bf949a60206000a360008080806024356004355af115630000009457005b506044361063000000945763000003436004358060a01c630000009457602435630000036f565b506024361063000000945763000003436004358060a01c630000009457630000034c565b5060443610630000009457336000541415630000009457602435806001106300000094576004358060a01c63000000945780600052600460205281604060002055906000527f1a594081ae893ab78e67d9b9e843547318164322d32c65369d78a96172d9dc8f60206000a2005b50602436106300000094576004358060a01c63000000945760005260046020526040600020546300000343565b5060643610630000009457336000541415630000009457600435600155602435600255604435600355606060046000377f413cd67004cf87db26db7dda972f65ebdc94ede365868d411463ceee90aeeae160606000a1005b60015460005260025460205260035460405260606000f35b6000546300000343565b506044361063000000945733600054141563000000945760008080806024356004358060a01c6300000094575af1156300000094576024356000526004357f7fcf532c15f0a6db0bd6d0e038bea71d30d808c7d98cb3bf7268a95bf5081b6560206000a2005b60005260206000f35b600052600660205260406000205460025442041460056020526040600020540290565b90630000037d90630000034c565b600354818110159190038211151660015482111516478211151690509056",
+}
+
+// FaucetABI is the input ABI used to generate the binding from.
+// Deprecated: Use FaucetMetaData.ABI instead.
+var FaucetABI = FaucetMetaData.ABI
+
+// FaucetBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use FaucetMetaData.Bin instead.
+var FaucetBin = FaucetMetaData.Bin
+
+// DeployFaucet deploys a new Ethereum contract, binding an instance of Faucet to it.
+func DeployFaucet(auth *bind.TransactOpts, backend bind.ContractBackend, maxDrip *big.Int, period *big.Int, recipientLimit *big.Int) (common.Address, *types.Transaction, *Faucet, error) {
+	parsed, err := FaucetMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(FaucetBin), backend, maxDrip, period, recipientLimit)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &Faucet{FaucetCaller: FaucetCaller{contract: contract}, FaucetTransactor: FaucetTransactor{contract: contract}, FaucetFilterer: FaucetFilterer{contract: contract}}, nil
+}
+
+// Faucet is an auto generated Go binding around an Ethereum contract.
+type Faucet struct {
+	FaucetCaller     // Read-only binding to the contract
+	FaucetTransactor // Write-only binding to the contract
+	FaucetFilterer   // Log filterer for contract events
+}
+
+// FaucetCaller is an auto generated read-only Go binding around an Ethereum contract.
+type FaucetCaller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FaucetTransactor is an auto generated write-only Go binding around an Ethereum contract.
+type FaucetTransactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FaucetFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type FaucetFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// FaucetSession is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type FaucetSession struct {
+	Contract     *Faucet           // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// FaucetCallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type FaucetCallerSession struct {
+	Contract *FaucetCaller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts // Call options to use throughout this session
+}
+
+// FaucetTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type FaucetTransactorSession struct {
+	Contract     *FaucetTransactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// FaucetRaw is an auto generated low-level Go binding around an Ethereum contract.
+type FaucetRaw struct {
+	Contract *Faucet // Generic contract binding to access the raw methods on
+}
+
+// FaucetCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type FaucetCallerRaw struct {
+	Contract *FaucetCaller // Generic read-only contract binding to access the raw methods on
+}
+
+// FaucetTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type FaucetTransactorRaw struct {
+	Contract *FaucetTransactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewFaucet creates a new instance of Faucet, bound to a specific deployed contract.
+func NewFaucet(address common.Address, backend bind.ContractBackend) (*Faucet, error) {
+	contract, err := bindFaucet(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &Faucet{FaucetCaller: FaucetCaller{contract: contract}, FaucetTransactor: FaucetTransactor{contract: contract}, FaucetFilterer: FaucetFilterer{contract: contract}}, nil
+}
+
+// NewFaucetCaller creates a new read-only instance of Faucet, bound to a specific deployed contract.
+func NewFaucetCaller(address common.Address, caller bind.ContractCaller) (*FaucetCaller, error) {
+	contract, err := bindFaucet(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FaucetCaller{contract: contract}, nil
+}
+
+// NewFaucetTransactor creates a new write-only instance of Faucet, bound to a specific deployed contract.
+func NewFaucetTransactor(address common.Address, transactor bind.ContractTransactor) (*FaucetTransactor, error) {
+	contract, err := bindFaucet(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &FaucetTransactor{contract: contract}, nil
+}
+
+// NewFaucetFilterer creates a new log filterer instance of Faucet, bound to a specific deployed contract.
+func NewFaucetFilterer(address common.Address, filterer bind.ContractFilterer) (*FaucetFilterer, error) {
+	contract, err := bindFaucet(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &FaucetFilterer{contract: contract}, nil
+}
+
+// bindFaucet binds a generic wrapper to an already deployed contract.
+func bindFaucet(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := FaucetMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Faucet *FaucetRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Faucet.Contract.FaucetCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Faucet *FaucetRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Faucet.Contract.FaucetTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Faucet *FaucetRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Faucet.Contract.FaucetTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Faucet *FaucetCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Faucet.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Faucet *FaucetTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Faucet.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Faucet *FaucetTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Faucet.Contract.contract.Transact(opts, method, params...)
+}
+
+// CanDrip is a free data retrieval call binding the contract method 0xb085a31b.
+//
+// Solidity: function canDrip(address recipient, uint256 amount) view returns(bool)
+func (_Faucet *FaucetCaller) CanDrip(opts *bind.CallOpts, recipient common.Address, amount *big.Int) (bool, error) {
+	var out []interface{}
+	err := _Faucet.contract.Call(opts, &out, "canDrip", recipient, amount)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// CanDrip is a free data retrieval call binding the contract method 0xb085a31b.
+//
+// Solidity: function canDrip(address recipient, uint256 amount) view returns(bool)
+func (_Faucet *FaucetSession) CanDrip(recipient common.Address, amount *big.Int) (bool, error) {
+	return _Faucet.Contract.CanDrip(&_Faucet.CallOpts, recipient, amount)
+}
+
+// CanDrip is a free data retrieval call binding the contract method 0xb085a31b.
+//
+// Solidity: function canDrip(address recipient, uint256 amount) view returns(bool)
+func (_Faucet *FaucetCallerSession) CanDrip(recipient common.Address, amount *big.Int) (bool, error) {
+	return _Faucet.Contract.CanDrip(&_Faucet.CallOpts, recipient, amount)
+}
+
+// Dripped is a free data retrieval call binding the contract method 0x9531946f.
+//
+// Solidity: function dripped(address recipient) view returns(uint256)
+func (_Faucet *FaucetCaller) Dripped(opts *bind.CallOpts, recipient common.Address) (*big.Int, error) {
+	var out []interface{}
+	err := _Faucet.contract.Call(opts, &out, "dripped", recipient)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// Dripped is a free data retrieval call binding the contract method 0x9531946f.
+//
+// Solidity: function dripped(address recipient) view returns(uint256)
+func (_Faucet *FaucetSession) Dripped(recipient common.Address) (*big.Int, error) {
+	return _Faucet.Contract.Dripped(&_Faucet.CallOpts, recipient)
+}
+
+// Dripped is a free data retrieval call binding the contract method 0x9531946f.
+//
+// Solidity: function dripped(address recipient) view returns(uint256)
+func (_Faucet *FaucetCallerSession) Dripped(recipient common.Address) (*big.Int, error) {
+	return _Faucet.Contract.Dripped(&_Faucet.CallOpts, recipient)
+}
+
+// IsOperator is a free data retrieval call binding the contract method 0x6d70f7ae.
+//
+// Solidity: function isOperator(address operator) view returns(bool)
+func (_Faucet *FaucetCaller) IsOperator(opts *bind.CallOpts, operator common.Address) (bool, error) {
+	var out []interface{}
+	err := _Faucet.contract.Call(opts, &out, "isOperator", operator)
+
+	if err != nil {
+		return *new(bool), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(bool)).(*bool)
+
+	return out0, err
+
+}
+
+// IsOperator is a free data retrieval call binding the contract method 0x6d70f7ae.
+//
+// Solidity: function isOperator(address operator) view returns(bool)
+func (_Faucet *FaucetSession) IsOperator(operator common.Address) (bool, error) {
+	return _Faucet.Contract.IsOperator(&_Faucet.CallOpts, operator)
+}
+
+// IsOperator is a free data retrieval call binding the contract method 0x6d70f7ae.
+//
+// Solidity: function isOperator(address operator) view returns(bool)
+func (_Faucet *FaucetCallerSession) IsOperator(operator common.Address) (bool, error) {
+	return _Faucet.Contract.IsOperator(&_Faucet.CallOpts, operator)
+}
+
+// Limits is a free data retrieval call binding the contract method 0x860aefcf.
+//
+// Solidity: function limits() view returns(uint256 maxDrip, uint256 period, uint256 recipientLimit)
+func (_Faucet *FaucetCaller) Limits(opts *bind.CallOpts) (struct {
+	MaxDrip        *big.Int
+	Period         *big.Int
+	RecipientLimit *big.Int
+}, error) {
+	var out []interface{}
+	err := _Faucet.contract.Call(opts, &out, "limits")
+
+	outstruct := new(struct {
+		MaxDrip        *big.Int
+		Period         *big.Int
+		RecipientLimit *big.Int
+	})
+	if err != nil {
+		return *outstruct, err
+	}
+
+	outstruct.MaxDrip = *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	outstruct.Period = *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+	outstruct.RecipientLimit = *abi.ConvertType(out[2], new(*big.Int)).(**big.Int)
+
+	return *outstruct, err
+
+}
+
+// Limits is a free data retrieval call binding the contract method 0x860aefcf.
+//
+// Solidity: function limits() view returns(uint256 maxDrip, uint256 period, uint256 recipientLimit)
+func (_Faucet *FaucetSession) Limits() (struct {
+	MaxDrip        *big.Int
+	Period         *big.Int
+	RecipientLimit *big.Int
+}, error) {
+	return _Faucet.Contract.Limits(&_Faucet.CallOpts)
+}
+
+// Limits is a free data retrieval call binding the contract method 0x860aefcf.
+//
+// Solidity: function limits() view returns(uint256 maxDrip, uint256 period, uint256 recipientLimit)
+func (_Faucet *FaucetCallerSession) Limits() (struct {
+	MaxDrip        *big.Int
+	Period         *big.Int
+	RecipientLimit *big.Int
+}, error) {
+	return _Faucet.Contract.Limits(&_Faucet.CallOpts)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_Faucet *FaucetCaller) Owner(opts *bind.CallOpts) (common.Address, error) {
+	var out []interface{}
+	err := _Faucet.contract.Call(opts, &out, "owner")
+
+	if err != nil {
+		return *new(common.Address), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(common.Address)).(*common.Address)
+
+	return out0, err
+
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_Faucet *FaucetSession) Owner() (common.Address, error) {
+	return _Faucet.Contract.Owner(&_Faucet.CallOpts)
+}
+
+// Owner is a free data retrieval call binding the contract method 0x8da5cb5b.
+//
+// Solidity: function owner() view returns(address)
+func (_Faucet *FaucetCallerSession) Owner() (common.Address, error) {
+	return _Faucet.Contract.Owner(&_Faucet.CallOpts)
+}
+
+// Drip is a paid mutator transaction binding the contract method 0x9e353a1e.
+//
+// Solidity: function drip(address recipient, uint256 amount) returns()
+func (_Faucet *FaucetTransactor) Drip(opts *bind.TransactOpts, recipient common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Faucet.contract.Transact(opts, "drip", recipient, amount)
+}
+
+// Drip is a paid mutator transaction binding the contract method 0x9e353a1e.
+//
+// Solidity: function drip(address recipient, uint256 amount) returns()
+func (_Faucet *FaucetSession) Drip(recipient common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Faucet.Contract.Drip(&_Faucet.TransactOpts, recipient, amount)
+}
+
+// Drip is a paid mutator transaction binding the contract method 0x9e353a1e.
+//
+// Solidity: function drip(address recipient, uint256 amount) returns()
+func (_Faucet *FaucetTransactorSession) Drip(recipient common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Faucet.Contract.Drip(&_Faucet.TransactOpts, recipient, amount)
+}
+
+// SetLimits is a paid mutator transaction binding the contract method 0x189ae5f2.
+//
+// Solidity: function setLimits(uint256 maxDrip, uint256 period, uint256 recipientLimit) returns()
+func (_Faucet *FaucetTransactor) SetLimits(opts *bind.TransactOpts, maxDrip *big.Int, period *big.Int, recipientLimit *big.Int) (*types.Transaction, error) {
+	return _Faucet.contract.Transact(opts, "setLimits", maxDrip, period, recipientLimit)
+}
+
+// SetLimits is a paid mutator transaction binding the contract method 0x189ae5f2.
+//
+// Solidity: function setLimits(uint256 maxDrip, uint256 period, uint256 recipientLimit) returns()
+func (_Faucet *FaucetSession) SetLimits(maxDrip *big.Int, period *big.Int, recipientLimit *big.Int) (*types.Transaction, error) {
+	return _Faucet.Contract.SetLimits(&_Faucet.TransactOpts, maxDrip, period, recipientLimit)
+}
+
+// SetLimits is a paid mutator transaction binding the contract method 0x189ae5f2.
+//
+// Solidity: function setLimits(uint256 maxDrip, uint256 period, uint256 recipientLimit) returns()
+func (_Faucet *FaucetTransactorSession) SetLimits(maxDrip *big.Int, period *big.Int, recipientLimit *big.Int) (*types.Transaction, error) {
+	return _Faucet.Contract.SetLimits(&_Faucet.TransactOpts, maxDrip, period, recipientLimit)
+}
+
+// SetOperator is a paid mutator transaction binding the contract method 0x558a7297.
+//
+// Solidity: function setOperator(address operator, bool enabled) returns()
+func (_Faucet *FaucetTransactor) SetOperator(opts *bind.TransactOpts, operator common.Address, enabled bool) (*types.Transaction, error) {
+	return _Faucet.contract.Transact(opts, "setOperator", operator, enabled)
+}
+
+// SetOperator is a paid mutator transaction binding the contract method 0x558a7297.
+//
+// Solidity: function setOperator(address operator, bool enabled) returns()
+func (_Faucet *FaucetSession) SetOperator(operator common.Address, enabled bool) (*types.Transaction, error) {
+	return _Faucet.Contract.SetOperator(&_Faucet.TransactOpts, operator, enabled)
+}
+
+// SetOperator is a paid mutator transaction binding the contract method 0x558a7297.
+//
+// Solidity: function setOperator(address operator, bool enabled) returns()
+func (_Faucet *FaucetTransactorSession) SetOperator(operator common.Address, enabled bool) (*types.Transaction, error) {
+	return _Faucet.Contract.SetOperator(&_Faucet.TransactOpts, operator, enabled)
+}
+
+// Withdraw is a paid mutator transaction binding the contract method 0xf3fef3a3.
+//
+// Solidity: function withdraw(address to, uint256 amount) returns()
+func (_Faucet *FaucetTransactor) Withdraw(opts *bind.TransactOpts, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Faucet.contract.Transact(opts, "withdraw", to, amount)
+}
+
+// Withdraw is a paid mutator transaction binding the contract method 0xf3fef3a3.
+//
+// Solidity: function withdraw(address to, uint256 amount) returns()
+func (_Faucet *FaucetSession) Withdraw(to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Faucet.Contract.Withdraw(&_Faucet.TransactOpts, to, amount)
+}
+
+// Withdraw is a paid mutator transaction binding the contract method 0xf3fef3a3.
+//
+// Solidity: function withdraw(address to, uint256 amount) returns()
+func (_Faucet *FaucetTransactorSession) Withdraw(to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return _Faucet.Contract.Withdraw(&_Faucet.TransactOpts, to, amount)
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_Faucet *FaucetTransactor) Receive(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Faucet.contract.RawTransact(opts, nil) // calldata is disallowed for receive function
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_Faucet *FaucetSession) Receive() (*types.Transaction, error) {
+	return _Faucet.Contract.Receive(&_Faucet.TransactOpts)
+}
+
+// Receive is a paid mutator transaction binding the contract receive function.
+//
+// Solidity: receive() payable returns()
+func (_Faucet *FaucetTransactorSession) Receive() (*types.Transaction, error) {
+	return _Faucet.Contract.Receive(&_Faucet.TransactOpts)
+}
+
+// FaucetDepositIterator is returned from FilterDeposit and is used to iterate over the raw logs and unpacked data for Deposit events raised by the Faucet contract.
+type FaucetDepositIterator struct {
+	Event *FaucetDeposit // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FaucetDepositIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FaucetDeposit)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FaucetDeposit)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FaucetDepositIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FaucetDepositIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FaucetDeposit represents a Deposit event raised by the Faucet contract.
+type FaucetDeposit struct {
+	From   common.Address
+	Amount *big.Int
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterDeposit is a free log retrieval operation binding the contract event 0xe1fffcc4923d04b559f4d29a8bfc6cda04eb5b0d3c460751c2402c5c5cc9109c.
+//
+// Solidity: event Deposit(address indexed from, uint256 amount)
+func (_Faucet *FaucetFilterer) FilterDeposit(opts *bind.FilterOpts, from []common.Address) (*FaucetDepositIterator, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Faucet.contract.FilterLogs(opts, "Deposit", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return &FaucetDepositIterator{contract: _Faucet.contract, event: "Deposit", logs: logs, sub: sub}, nil
+}
+
+// WatchDeposit is a free log subscription operation binding the contract event 0xe1fffcc4923d04b559f4d29a8bfc6cda04eb5b0d3c460751c2402c5c5cc9109c.
+//
+// Solidity: event Deposit(address indexed from, uint256 amount)
+func (_Faucet *FaucetFilterer) WatchDeposit(opts *bind.WatchOpts, sink chan<- *FaucetDeposit, from []common.Address) (event.Subscription, error) {
+
+	var fromRule []interface{}
+	for _, fromItem := range from {
+		fromRule = append(fromRule, fromItem)
+	}
+
+	logs, sub, err := _Faucet.contract.WatchLogs(opts, "Deposit", fromRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FaucetDeposit)
+				if err := _Faucet.contract.UnpackLog(event, "Deposit", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseDeposit is a log parse operation binding the contract event 0xe1fffcc4923d04b559f4d29a8bfc6cda04eb5b0d3c460751c2402c5c5cc9109c.
+//
+// Solidity: event Deposit(address indexed from, uint256 amount)
+func (_Faucet *FaucetFilterer) ParseDeposit(log types.Log) (*FaucetDeposit, error) {
+	event := new(FaucetDeposit)
+	if err := _Faucet.contract.UnpackLog(event, "Deposit", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FaucetDripIterator is returned from FilterDrip and is used to iterate over the raw logs and unpacked data for Drip events raised by the Faucet contract.
+type FaucetDripIterator struct {
+	Event *FaucetDrip // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FaucetDripIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FaucetDrip)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FaucetDrip)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FaucetDripIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FaucetDripIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FaucetDrip represents a Drip event raised by the Faucet contract.
+type FaucetDrip struct {
+	Recipient common.Address
+	Operator  common.Address
+	Amount    *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterDrip is a free log retrieval operation binding the contract event 0xdfc93ac43234c3993e5d23f2a1a4c52b2ac70f67cf1f534bd7ceb92328bf949a.
+//
+// Solidity: event Drip(address indexed recipient, address indexed operator, uint256 amount)
+func (_Faucet *FaucetFilterer) FilterDrip(opts *bind.FilterOpts, recipient []common.Address, operator []common.Address) (*FaucetDripIterator, error) {
+
+	var recipientRule []interface{}
+	for _, recipientItem := range recipient {
+		recipientRule = append(recipientRule, recipientItem)
+	}
+	var operatorRule []interface{}
+	for _, operatorItem := range operator {
+		operatorRule = append(operatorRule, operatorItem)
+	}
+
+	logs, sub, err := _Faucet.contract.FilterLogs(opts, "Drip", recipientRule, operatorRule)
+	if err != nil {
+		return nil, err
+	}
+	return &FaucetDripIterator{contract: _Faucet.contract, event: "Drip", logs: logs, sub: sub}, nil
+}
+
+// WatchDrip is a free log subscription operation binding the contract event 0xdfc93ac43234c3993e5d23f2a1a4c52b2ac70f67cf1f534bd7ceb92328bf949a.
+//
+// Solidity: event Drip(address indexed recipient, address indexed operator, uint256 amount)
+func (_Faucet *FaucetFilterer) WatchDrip(opts *bind.WatchOpts, sink chan<- *FaucetDrip, recipient []common.Address, operator []common.Address) (event.Subscription, error) {
+
+	var recipientRule []interface{}
+	for _, recipientItem := range recipient {
+		recipientRule = append(recipientRule, recipientItem)
+	}
+	var operatorRule []interface{}
+	for _, operatorItem := range operator {
+		operatorRule = append(operatorRule, operatorItem)
+	}
+
+	logs, sub, err := _Faucet.contract.WatchLogs(opts, "Drip", recipientRule, operatorRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FaucetDrip)
+				if err := _Faucet.contract.UnpackLog(event, "Drip", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseDrip is a log parse operation binding the contract event 0xdfc93ac43234c3993e5d23f2a1a4c52b2ac70f67cf1f534bd7ceb92328bf949a.
+//
+// Solidity: event Drip(address indexed recipient, address indexed operator, uint256 amount)
+func (_Faucet *FaucetFilterer) ParseDrip(log types.Log) (*FaucetDrip, error) {
+	event := new(FaucetDrip)
+	if err := _Faucet.contract.UnpackLog(event, "Drip", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FaucetLimitsSetIterator is returned from FilterLimitsSet and is used to iterate over the raw logs and unpacked data for LimitsSet events raised by the Faucet contract.
+type FaucetLimitsSetIterator struct {
+	Event *FaucetLimitsSet // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FaucetLimitsSetIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FaucetLimitsSet)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FaucetLimitsSet)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FaucetLimitsSetIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FaucetLimitsSetIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FaucetLimitsSet represents a LimitsSet event raised by the Faucet contract.
+type FaucetLimitsSet struct {
+	MaxDrip        *big.Int
+	Period         *big.Int
+	RecipientLimit *big.Int
+	Raw            types.Log // Blockchain specific contextual infos
+}
+
+// FilterLimitsSet is a free log retrieval operation binding the contract event 0x413cd67004cf87db26db7dda972f65ebdc94ede365868d411463ceee90aeeae1.
+//
+// Solidity: event LimitsSet(uint256 maxDrip, uint256 period, uint256 recipientLimit)
+func (_Faucet *FaucetFilterer) FilterLimitsSet(opts *bind.FilterOpts) (*FaucetLimitsSetIterator, error) {
+
+	logs, sub, err := _Faucet.contract.FilterLogs(opts, "LimitsSet")
+	if err != nil {
+		return nil, err
+	}
+	return &FaucetLimitsSetIterator{contract: _Faucet.contract, event: "LimitsSet", logs: logs, sub: sub}, nil
+}
+
+// WatchLimitsSet is a free log subscription operation binding the contract event 0x413cd67004cf87db26db7dda972f65ebdc94ede365868d411463ceee90aeeae1.
+//
+// Solidity: event LimitsSet(uint256 maxDrip, uint256 period, uint256 recipientLimit)
+func (_Faucet *FaucetFilterer) WatchLimitsSet(opts *bind.WatchOpts, sink chan<- *FaucetLimitsSet) (event.Subscription, error) {
+
+	logs, sub, err := _Faucet.contract.WatchLogs(opts, "LimitsSet")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FaucetLimitsSet)
+				if err := _Faucet.contract.UnpackLog(event, "LimitsSet", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseLimitsSet is a log parse operation binding the contract event 0x413cd67004cf87db26db7dda972f65ebdc94ede365868d411463ceee90aeeae1.
+//
+// Solidity: event LimitsSet(uint256 maxDrip, uint256 period, uint256 recipientLimit)
+func (_Faucet *FaucetFilterer) ParseLimitsSet(log types.Log) (*FaucetLimitsSet, error) {
+	event := new(FaucetLimitsSet)
+	if err := _Faucet.contract.UnpackLog(event, "LimitsSet", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FaucetOperatorSetIterator is returned from FilterOperatorSet and is used to iterate over the raw logs and unpacked data for OperatorSet events raised by the Faucet contract.
+type FaucetOperatorSetIterator struct {
+	Event *FaucetOperatorSet // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FaucetOperatorSetIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FaucetOperatorSet)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FaucetOperatorSet)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FaucetOperatorSetIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FaucetOperatorSetIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FaucetOperatorSet represents a OperatorSet event raised by the Faucet contract.
+type FaucetOperatorSet struct {
+	Operator common.Address
+	Enabled  bool
+	Raw      types.Log // Blockchain specific contextual infos
+}
+
+// FilterOperatorSet is a free log retrieval operation binding the contract event 0x1a594081ae893ab78e67d9b9e843547318164322d32c65369d78a96172d9dc8f.
+//
+// Solidity: event OperatorSet(address indexed operator, bool enabled)
+func (_Faucet *FaucetFilterer) FilterOperatorSet(opts *bind.FilterOpts, operator []common.Address) (*FaucetOperatorSetIterator, error) {
+
+	var operatorRule []interface{}
+	for _, operatorItem := range operator {
+		operatorRule = append(operatorRule, operatorItem)
+	}
+
+	logs, sub, err := _Faucet.contract.FilterLogs(opts, "OperatorSet", operatorRule)
+	if err != nil {
+		return nil, err
+	}
+	return &FaucetOperatorSetIterator{contract: _Faucet.contract, event: "OperatorSet", logs: logs, sub: sub}, nil
+}
+
+// WatchOperatorSet is a free log subscription operation binding the contract event 0x1a594081ae893ab78e67d9b9e843547318164322d32c65369d78a96172d9dc8f.
+//
+// Solidity: event OperatorSet(address indexed operator, bool enabled)
+func (_Faucet *FaucetFilterer) WatchOperatorSet(opts *bind.WatchOpts, sink chan<- *FaucetOperatorSet, operator []common.Address) (event.Subscription, error) {
+
+	var operatorRule []interface{}
+	for _, operatorItem := range operator {
+		operatorRule = append(operatorRule, operatorItem)
+	}
+
+	logs, sub, err := _Faucet.contract.WatchLogs(opts, "OperatorSet", operatorRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FaucetOperatorSet)
+				if err := _Faucet.contract.UnpackLog(event, "OperatorSet", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOperatorSet is a log parse operation binding the contract event 0x1a594081ae893ab78e67d9b9e843547318164322d32c65369d78a96172d9dc8f.
+//
+// Solidity: event OperatorSet(address indexed operator, bool enabled)
+func (_Faucet *FaucetFilterer) ParseOperatorSet(log types.Log) (*FaucetOperatorSet, error) {
+	event := new(FaucetOperatorSet)
+	if err := _Faucet.contract.UnpackLog(event, "OperatorSet", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// FaucetWithdrawalIterator is returned from FilterWithdrawal and is used to iterate over the raw logs and unpacked data for Withdrawal events raised by the Faucet contract.
+type FaucetWithdrawalIterator struct {
+	Event *FaucetWithdrawal // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *FaucetWithdrawalIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(FaucetWithdrawal)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(FaucetWithdrawal)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *FaucetWithdrawalIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *FaucetWithdrawalIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// FaucetWithdrawal represents a Withdrawal event raised by the Faucet contract.
+type FaucetWithdrawal struct {
+	To     common.Address
+	Amount *big.Int
+	Raw    types.Log // Blockchain specific contextual infos
+}
+
+// FilterWithdrawal is a free log retrieval operation binding the contract event 0x7fcf532c15f0a6db0bd6d0e038bea71d30d808c7d98cb3bf7268a95bf5081b65.
+//
+// Solidity: event Withdrawal(address indexed to, uint256 amount)
+func (_Faucet *FaucetFilterer) FilterWithdrawal(opts *bind.FilterOpts, to []common.Address) (*FaucetWithdrawalIterator, error) {
+
+	var toRule []interface{}
+	for _, toItem := range to {
+		toRule = append(toRule, toItem)
+	}
+
+	logs, sub, err := _Faucet.contract.FilterLogs(opts, "Withdrawal", toRule)
+	if err != nil {
+		return nil, err
+	}
+	return &FaucetWithdrawalIterator{contract: _Faucet.contract, event: "Withdrawal", logs: logs, sub: sub}, nil
+}
+
+// WatchWithdrawal is a free log subscription operation binding the contract event 0x7fcf532c15f0a6db0bd6d0e038bea71d30d808c7d98cb3bf7268a95bf5081b65.
+//
+// Solidity: event Withdrawal(address indexed to, uint256 amount)
+func (_Faucet *FaucetFilterer) WatchWithdrawal(opts *bind.WatchOpts, sink chan<- *FaucetWithdrawal, to []common.Address) (event.Subscription, error) {
+
+	var toRule []interface{}
+	for _, toItem := range to {
+		toRule = append(toRule, toItem)
+	}
+
+	logs, sub, err := _Faucet.contract.WatchLogs(opts, "Withdrawal", toRule)
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(FaucetWithdrawal)
+				if err := _Faucet.contract.UnpackLog(event, "Withdrawal", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseWithdrawal is a log parse operation binding the contract event 0x7fcf532c15f0a6db0bd6d0e038bea71d30d808c7d98cb3bf7268a95bf5081b65.
+//
+// Solidity: event Withdrawal(address indexed to, uint256 amount)
+func (_Faucet *FaucetFilterer) ParseWithdrawal(log types.Log) (*FaucetWithdrawal, error) {
+	event := new(FaucetWithdrawal)
+	if err := _Faucet.contract.UnpackLog(event, "Withdrawal", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
@@ -0,0 +1,219 @@
+package contract
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ether     = big.NewInt(params.Ether)
+	maxDrip   = new(big.Int).Mul(big.NewInt(10), ether)
+	period    = big.NewInt(3600)
+	recipient = new(big.Int).Mul(big.NewInt(15), ether)
+)
+
+type testChain struct {
+	t      *testing.T
+	sim    *backends.SimulatedBackend
+	owner  *bind.TransactOpts
+	other  *bind.TransactOpts
+	addr   common.Address
+	faucet *Faucet
+}
+
+func newTestChain(t *testing.T) *testChain {
+	ownerKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	otherKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	funds := new(big.Int).Mul(big.NewInt(1000), ether)
+	sim := backends.NewSimulatedBackend(core.GenesisAlloc{
+		crypto.PubkeyToAddress(ownerKey.PublicKey): {Balance: funds},
+		crypto.PubkeyToAddress(otherKey.PublicKey): {Balance: funds},
+	}, 30_000_000)
+	t.Cleanup(func() {
+		require.NoError(t, sim.Close())
+	})
+
+	c := &testChain{
+		t:     t,
+		sim:   sim,
+		owner: transactor(t, ownerKey),
+		other: transactor(t, otherKey),
+	}
+
+	c.addr, _, _, err = bind.DeployContract(c.owner, mustParseABI(t, FaucetMetaData.ABI), common.FromHex(FaucetMetaData.Bin), sim, maxDrip, period, recipient)
+	require.NoError(t, err)
+	sim.Commit()
+	c.faucet, err = NewFaucet(c.addr, sim)
+	require.NoError(t, err)
+
+	c.owner.Value = new(big.Int).Mul(big.NewInt(100), ether)
+	c.mined(c.faucet.Receive(c.owner))
+	c.owner.Value = nil
+
+	return c
+}
+
+func transactor(t *testing.T, key *ecdsa.PrivateKey) *bind.TransactOpts {
+	opts, err := bind.NewKeyedTransactorWithChainID(key, params.AllEthashProtocolChanges.ChainID)
+	require.NoError(t, err)
+	return opts
+}
+
+// mined commits the transaction and returns its receipt.
+func (c *testChain) mined(tx *types.Transaction, err error) *types.Receipt {
+	t := c.t
+	require.NoError(t, err)
+	c.sim.Commit()
+
+	receipt, err := c.sim.TransactionReceipt(context.Background(), tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+	return receipt
+}
+
+func (c *testChain) balance(t *testing.T, addr common.Address) *big.Int {
+	balance, err := c.sim.BalanceAt(context.Background(), addr, nil)
+	require.NoError(t, err)
+	return balance
+}
+
+func mustParseABI(t *testing.T, abiJSON string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	require.NoError(t, err)
+	return parsed
+}
+
+func newAddress(t *testing.T) common.Address {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	return crypto.PubkeyToAddress(key.PublicKey)
+}
+
+func TestFaucetDeploy(t *testing.T) {
+	c := newTestChain(t)
+
+	owner, err := c.faucet.Owner(nil)
+	require.NoError(t, err)
+	require.Equal(t, c.owner.From, owner)
+
+	ok, err := c.faucet.IsOperator(nil, c.owner.From)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	limits, err := c.faucet.Limits(nil)
+	require.NoError(t, err)
+	require.Equal(t, maxDrip, limits.MaxDrip)
+	require.Equal(t, period, limits.Period)
+	require.Equal(t, recipient, limits.RecipientLimit)
+
+	require.Equal(t, new(big.Int).Mul(big.NewInt(100), ether), c.balance(t, c.addr))
+}
+
+func TestFaucetDrip(t *testing.T) {
+	c := newTestChain(t)
+	to := newAddress(t)
+	amount := new(big.Int).Mul(big.NewInt(10), ether)
+
+	ok, err := c.faucet.CanDrip(nil, to, amount)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	receipt := c.mined(c.faucet.Drip(c.owner, to, amount))
+	require.Equal(t, amount, c.balance(t, to))
+
+	require.Len(t, receipt.Logs, 1)
+	event, err := c.faucet.ParseDrip(*receipt.Logs[0])
+	require.NoError(t, err)
+	require.Equal(t, to, event.Recipient)
+	require.Equal(t, c.owner.From, event.Operator)
+	require.Equal(t, amount, event.Amount)
+
+	dripped, err := c.faucet.Dripped(nil, to)
+	require.NoError(t, err)
+	require.Equal(t, amount, dripped)
+
+	// The recipient limit leaves 5 coins in the window.
+	ok, err = c.faucet.CanDrip(nil, to, amount)
+	require.NoError(t, err)
+	require.False(t, ok)
+	_, err = c.faucet.Drip(c.owner, to, amount)
+	require.Error(t, err)
+
+	five := new(big.Int).Mul(big.NewInt(5), ether)
+	c.mined(c.faucet.Drip(c.owner, to, five))
+
+	// The limit is reset in the next window.
+	require.NoError(t, c.sim.AdjustTime(time.Hour))
+	c.sim.Commit()
+
+	dripped, err = c.faucet.Dripped(nil, to)
+	require.NoError(t, err)
+	require.Zero(t, dripped.Sign())
+	c.mined(c.faucet.Drip(c.owner, to, amount))
+	require.Equal(t, new(big.Int).Mul(big.NewInt(25), ether), c.balance(t, to))
+}
+
+func TestFaucetLimits(t *testing.T) {
+	c := newTestChain(t)
+	to := newAddress(t)
+
+	// A single drip is limited.
+	ok, err := c.faucet.CanDrip(nil, to, new(big.Int).Add(maxDrip, big.NewInt(1)))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Only operators drip.
+	_, err = c.faucet.Drip(c.other, to, ether)
+	require.Error(t, err)
+
+	_, err = c.faucet.SetOperator(c.other, c.other.From, true)
+	require.Error(t, err)
+	c.mined(c.faucet.SetOperator(c.owner, c.other.From, true))
+	c.mined(c.faucet.Drip(c.other, to, ether))
+
+	c.mined(c.faucet.SetOperator(c.owner, c.other.From, false))
+	_, err = c.faucet.Drip(c.other, to, ether)
+	require.Error(t, err)
+
+	// Limits are changed by the owner.
+	_, err = c.faucet.SetLimits(c.other, ether, period, ether)
+	require.Error(t, err)
+	c.mined(c.faucet.SetLimits(c.owner, ether, period, ether))
+
+	ok, err = c.faucet.CanDrip(nil, newAddress(t), ether)
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = c.faucet.CanDrip(nil, to, big.NewInt(1))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// Drips are limited by the balance of the contract.
+	c.mined(c.faucet.SetLimits(c.owner, maxDrip, period, recipient))
+	drained := newAddress(t)
+	c.mined(c.faucet.Withdraw(c.owner, drained, new(big.Int).Sub(c.balance(t, c.addr), ether)))
+	require.Equal(t, ether, c.balance(t, c.addr))
+
+	ok, err = c.faucet.CanDrip(nil, to, new(big.Int).Add(ether, big.NewInt(1)))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = c.faucet.Withdraw(c.other, c.other.From, ether)
+	require.Error(t, err)
+}
@@ -2,27 +2,92 @@ package data
 
 import (
 	"crypto/ecdsa"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"strings"
 
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/filecoin-project/go-address"
+)
+
+// Types of the secp256k1 keys. A delegated key controls an f4 address of the EVM.
+const (
+	KeyTypeSecp256k1 = "secp256k1"
+	KeyTypeDelegated = "delegated"
 )
 
 type EthereumAccount struct {
 	PrivateKey *ecdsa.PrivateKey
 	PublicKey  *ecdsa.PublicKey
 	Address    common.Address
+	// KeyType is the type of the key, KeyTypeDelegated for the delegated keys of Lotus wallets.
+	KeyType string
+	// LotusExport is set for the keys exported by lotus wallet export.
+	LotusExport bool
 }
 
+// lotusKeyInfo is a key exported by lotus wallet export, as hex-encoded JSON.
+type lotusKeyInfo struct {
+	Type       string
+	PrivateKey []byte
+}
+
+// NewAccount parses a hex secp256k1 key, with or without 0x, or a key exported by lotus wallet export.
+// Surrounding whitespace is ignored.
 func NewAccount(key string) (*EthereumAccount, error) {
+	key = strings.TrimSpace(key)
 	if key == "" {
 		return nil, fmt.Errorf("empty private key")
 	}
+
+	// A bare key is 32 bytes long, an export is longer JSON.
+	if b, err := hex.DecodeString(key); err == nil && len(b) > 32 && b[0] == '{' {
+		return newLotusAccount(b)
+	}
+
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "0x"), "0X")
 	privateKey, err := crypto.HexToECDSA(key)
 	if err != nil {
 		return nil, err
 	}
+	return accountFromKey(privateKey)
+}
+
+func newLotusAccount(export []byte) (*EthereumAccount, error) {
+	var info lotusKeyInfo
+	if err := json.Unmarshal(export, &info); err != nil {
+		return nil, fmt.Errorf("invalid Lotus key: %w", err)
+	}
+	if info.Type != KeyTypeSecp256k1 && info.Type != KeyTypeDelegated {
+		return nil, fmt.Errorf("unsupported Lotus key type %q", info.Type)
+	}
+
+	privateKey, err := crypto.ToECDSA(info.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Lotus key: %w", err)
+	}
+	account, err := accountFromKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+	account.KeyType = info.Type
+	account.LotusExport = true
+	return account, nil
+}
+
+// NewKeystoreAccount decrypts a key in the encrypted JSON keystore format of go-ethereum.
+func NewKeystoreAccount(keyJSON []byte, passphrase string) (*EthereumAccount, error) {
+	key, err := keystore.DecryptKey(keyJSON, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt keystore: %w", err)
+	}
+	return accountFromKey(key.PrivateKey)
+}
 
+func accountFromKey(privateKey *ecdsa.PrivateKey) (*EthereumAccount, error) {
 	publicKey := privateKey.Public()
 	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
 	if !ok {
@@ -35,5 +100,59 @@ func NewAccount(key string) (*EthereumAccount, error) {
 		PrivateKey: privateKey,
 		PublicKey:  publicKeyECDSA,
 		Address:    addr,
+		KeyType:    KeyTypeSecp256k1,
 	}, nil
 }
+
+// String returns the address of the account, so that printing or logging an account never reveals its key.
+func (a *EthereumAccount) String() string {
+	return a.Address.Hex()
+}
+
+// GoString is String for the %#v verb.
+func (a *EthereumAccount) GoString() string {
+	return a.String()
+}
+
+// LotusSecp256k1 reports whether the key is the secp256k1 key of a Lotus wallet. The wallet holds its funds
+// on its f1 address, while the account sends EVM transactions from its 0x address, which is unrelated to it.
+func (a *EthereumAccount) LotusSecp256k1() bool {
+	return a.LotusExport && a.KeyType == KeyTypeSecp256k1
+}
+
+// FilecoinAddress returns the f1 address controlled by the key of the account.
+func (a *EthereumAccount) FilecoinAddress() (address.Address, error) {
+	return address.NewSecp256k1Address(crypto.FromECDSAPub(a.PublicKey))
+}
+
+// PoolAccount is the state of an account of the funding pool.
+type PoolAccount struct {
+	Address string `json:"address"`
+	Balance string `json:"balance"`
+	Nonce   uint64 `json:"nonce"`
+	Pending uint64 `json:"pending"`
+}
+
+// AccountKey references the key of an account of the funding pool: a private key file on the faucet host,
+// an account of the external signer at SignerURL, or the private key itself if the faucet accepts private keys.
+// Exactly one of KeyFile, SignerAccount and PrivateKey is set.
+type AccountKey struct {
+	KeyFile   string `json:"key_file,omitempty"`
+	SignerURL string `json:"signer_url,omitempty"`
+	// SignerMethod is the signing method of the external signer, account_signTransaction (Clef) by default.
+	SignerMethod  string `json:"signer_method,omitempty"`
+	SignerAccount string `json:"signer_account,omitempty"`
+	PrivateKey    string `json:"private_key,omitempty"`
+}
+
+// AddAccountRequest adds the account of the key to the funding pool.
+type AddAccountRequest struct {
+	AccountKey
+}
+
+// RotateAccountRequest replaces an account of the funding pool with the account of the key.
+// Sweep sends the balance left on the old account to the new one.
+type RotateAccountRequest struct {
+	AccountKey
+	Sweep bool `json:"sweep"`
+}
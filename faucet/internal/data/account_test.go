@@ -0,0 +1,93 @@
+package data
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const testKey = "4f3edf983ac636a65a842ce7c78d9aa706d3b113bce9c46f30d7d21715b23b1d"
+
+func TestKeystoreAccount(t *testing.T) {
+	account, err := NewAccount(testKey)
+	require.NoError(t, err)
+
+	ks := keystore.NewKeyStore(t.TempDir(), keystore.LightScryptN, keystore.LightScryptP)
+	imported, err := ks.ImportECDSA(account.PrivateKey, "passphrase")
+	require.NoError(t, err)
+	keyJSON, err := os.ReadFile(imported.URL.Path)
+	require.NoError(t, err)
+
+	decrypted, err := NewKeystoreAccount(keyJSON, "passphrase")
+	require.NoError(t, err)
+	require.Equal(t, account.Address, decrypted.Address)
+	require.Equal(t, testKey, hex.EncodeToString(crypto.FromECDSA(decrypted.PrivateKey)))
+
+	_, err = NewKeystoreAccount(keyJSON, "wrong")
+	require.Error(t, err)
+	_, err = NewKeystoreAccount([]byte("{}"), "passphrase")
+	require.Error(t, err)
+}
+
+func TestAccountString(t *testing.T) {
+	account, err := NewAccount(testKey)
+	require.NoError(t, err)
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		out := fmt.Sprintf(format, account)
+		require.Equal(t, account.Address.Hex(), out, format)
+	}
+}
+
+func TestAccountFormats(t *testing.T) {
+	account, err := NewAccount(testKey)
+	require.NoError(t, err)
+	require.Equal(t, KeyTypeSecp256k1, account.KeyType)
+
+	require.False(t, account.LotusSecp256k1())
+
+	for _, tc := range []struct {
+		name           string
+		key            string
+		keyType        string
+		lotusSecp256k1 bool
+	}{
+		{"prefixed", "0x" + testKey, KeyTypeSecp256k1, false},
+		{"whitespace", " " + testKey + "\n", KeyTypeSecp256k1, false},
+		{
+			"lotus secp256k1",
+			"7b2254797065223a22736563703235366b31222c22507269766174654b6579223a22547a37666d4472474e715a6168437a6e783432617077625473524f38366352764d4e6653467857794f78303d227d\n",
+			KeyTypeSecp256k1,
+			true,
+		},
+		{
+			"lotus delegated",
+			"7b2254797065223a2264656c656761746564222c22507269766174654b6579223a22547a37666d4472474e715a6168437a6e783432617077625473524f38366352764d4e6653467857794f78303d227d",
+			KeyTypeDelegated,
+			false,
+		},
+	} {
+		parsed, err := NewAccount(tc.key)
+		require.NoError(t, err, tc.name)
+		require.Equal(t, account.Address, parsed.Address, tc.name)
+		require.Equal(t, tc.keyType, parsed.KeyType, tc.name)
+		require.Equal(t, tc.lotusSecp256k1, parsed.LotusSecp256k1(), tc.name)
+	}
+
+	for _, key := range []string{
+		"",
+		" \n",
+		// BLS keys are not supported.
+		"7b2254797065223a22626c73222c22507269766174654b6579223a22547a37666d4472474e715a6168437a6e783432617077625473524f38366352764d4e6653467857794f78303d227d",
+		hex.EncodeToString([]byte(`{"Type":"secp256k1","PrivateKey":"AAE="}`)),
+		hex.EncodeToString([]byte(`{"Type":"secp256k1",`)),
+	} {
+		_, err := NewAccount(key)
+		require.Error(t, err, key)
+	}
+}
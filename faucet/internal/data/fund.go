@@ -4,14 +4,160 @@ import "time"
 
 type FundRequest struct {
 	Address string `json:"address"`
+	Asset   string `json:"asset,omitempty"`
+	Bundle  string `json:"bundle,omitempty"`
+	Network string `json:"network,omitempty"`
+}
+
+type FundResponse struct {
+	Asset     string    `json:"asset"`
+	TxHash    string    `json:"tx_hash"`
+	NextReset time.Time `json:"next_reset"`
+	// Voucher is returned instead of a transaction in the voucher mode.
+	Voucher *Voucher `json:"voucher,omitempty"`
+}
+
+type BundleResponse struct {
+	Bundle string      `json:"bundle"`
+	Legs   []LegStatus `json:"legs"`
+}
+
+type LegStatus struct {
+	Asset  string `json:"asset"`
+	Amount uint64 `json:"amount"`
+	Status string `json:"status"`
+	TxHash string `json:"tx_hash,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// Failed returns the number of failed legs.
+func (r BundleResponse) Failed() int {
+	n := 0
+	for _, l := range r.Legs {
+		if l.Error != "" {
+			n++
+		}
+	}
+	return n
+}
+
+type AccountResponse struct {
+	Address         string     `json:"address"`
+	FilecoinAddress string     `json:"filecoin_address"`
+	Identity        string     `json:"identity"`
+	Asset           string     `json:"asset"`
+	Balance         string     `json:"balance"`
+	Received        uint64     `json:"received"`
+	Remaining       uint64     `json:"remaining"`
+	NextReset       *time.Time `json:"next_reset,omitempty"`
+	LastGrant       *time.Time `json:"last_grant,omitempty"`
+	LastTxHash      string     `json:"last_tx_hash,omitempty"`
+	TotalRemaining  uint64     `json:"total_remaining"`
+}
+
+// ConvertResponse lists the equivalent representations of an address.
+// Filecoin, ID and MaskedID are set when they are known.
+type ConvertResponse struct {
+	Input    string `json:"input"`
+	Type     string `json:"type"`
+	Ethereum string `json:"ethereum,omitempty"`
+	Filecoin string `json:"filecoin,omitempty"`
+	Mainnet  string `json:"mainnet"`
+	Testnet  string `json:"testnet"`
+	ID       string `json:"id,omitempty"`
+	MaskedID string `json:"masked_id,omitempty"`
+}
+
+// RefillRecord is an entry of the audit trail of treasury refills.
+// Balance is the balance of the account in wei when the refill was decided.
+type RefillRecord struct {
+	Time     time.Time `json:"time"`
+	Treasury string    `json:"treasury"`
+	Account  string    `json:"account"`
+	Balance  string    `json:"balance"`
+	Amount   uint64    `json:"amount"`
+	Status   string    `json:"status"`
+	TxHash   string    `json:"tx_hash,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// RotationRecord is an entry of the audit trail of funding account rotations.
+// Swept is the balance in wei sent from the old account to the new one.
+type RotationRecord struct {
+	Time      time.Time  `json:"time"`
+	Completed *time.Time `json:"completed,omitempty"`
+	Old       string     `json:"old"`
+	New       string     `json:"new"`
+	Sweep     bool       `json:"sweep"`
+	Status    string     `json:"status"`
+	Swept     string     `json:"swept,omitempty"`
+	TxHash    string     `json:"tx_hash,omitempty"`
+	Error     string     `json:"error,omitempty"`
 }
 
 type AddrInfo struct {
 	Amount         uint64    `json:"amount"`
 	LatestTransfer time.Time `json:"latest_transfer"`
+	LastGrant      time.Time `json:"last_grant"`
+	LastTxHash     string    `json:"last_tx_hash"`
 }
 
 type TotalInfo struct {
 	Amount         uint64    `json:"amount"`
 	LatestTransfer time.Time `json:"latest_transfer"`
 }
+
+// CrossNetMessage is a top-down message funding a recipient on a child subnet.
+// Nonce is the top-down nonce of the message, known once the transaction sending it is mined.
+type CrossNetMessage struct {
+	TxHash    string     `json:"tx_hash"`
+	Subnet    string     `json:"subnet"`
+	Recipient string     `json:"recipient"`
+	Amount    uint64     `json:"amount"`
+	Status    string     `json:"status"`
+	Nonce     *uint64    `json:"nonce,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	Sent      time.Time  `json:"sent"`
+	Delivered *time.Time `json:"delivered,omitempty"`
+}
+
+// Voucher is an EIP-712 claim of funds signed by the faucet and redeemed at the claim contract.
+// Amount and Nonce are decimal, Expiry is a Unix time in seconds.
+// Calldata is the input of the claim transaction redeeming the voucher.
+type Voucher struct {
+	Contract  string `json:"contract"`
+	ChainID   uint64 `json:"chain_id"`
+	Recipient string `json:"recipient"`
+	Amount    string `json:"amount"`
+	Nonce     string `json:"nonce"`
+	Expiry    int64  `json:"expiry"`
+	Signature string `json:"signature"`
+	Calldata  string `json:"calldata"`
+}
+
+// VoucherRecord is the state of a voucher issued by the faucet.
+// Granted is the amount charged to the quotas of the recipient identity.
+type VoucherRecord struct {
+	Voucher
+	Identity string     `json:"identity"`
+	Granted  uint64     `json:"granted"`
+	Issued   time.Time  `json:"issued"`
+	Status   string     `json:"status"`
+	Resolved *time.Time `json:"resolved,omitempty"`
+}
+
+// DripRecord is the confirmation state of a drip through the faucet contract.
+type DripRecord struct {
+	TxHash    string     `json:"tx_hash"`
+	Contract  string     `json:"contract"`
+	Recipient string     `json:"recipient"`
+	Amount    uint64     `json:"amount"`
+	Status    string     `json:"status"`
+	Sent      time.Time  `json:"sent"`
+	Confirmed *time.Time `json:"confirmed,omitempty"`
+	Block     uint64     `json:"block,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	// Identity keys the quota the drip was granted from. A failed drip is credited back to it.
+	Identity string `json:"identity,omitempty"`
+	Credited bool   `json:"credited,omitempty"`
+}
@@ -0,0 +1,102 @@
+package data
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// BIP-44 base paths of the accounts derived from a mnemonic. The index of an account is appended to the base path.
+const (
+	EthereumBasePath = "m/44'/60'/0'/0"
+	FilecoinBasePath = "m/44'/461'/0'/0"
+)
+
+// hardenedOffset is the first index of hardened keys.
+const hardenedOffset = 0x80000000
+
+// NewMnemonicAccounts derives count accounts from the BIP-39 mnemonic along the BIP-44 base path,
+// at the indexes from 0 to count-1.
+func NewMnemonicAccounts(mnemonic, passphrase, basePath string, count int) ([]*EthereumAccount, error) {
+	base, err := accounts.ParseDerivationPath(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid derivation path %q: %w", basePath, err)
+	}
+	if count <= 0 {
+		return nil, fmt.Errorf("invalid number of accounts %d", count)
+	}
+
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mnemonic: %w", err)
+	}
+
+	derived := make([]*EthereumAccount, 0, count)
+	for i := 0; i < count; i++ {
+		path := append(accounts.DerivationPath{}, base...)
+		path = append(path, uint32(i))
+
+		key, err := deriveKey(seed, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive %s: %w", path, err)
+		}
+		account, err := accountFromKey(key)
+		if err != nil {
+			return nil, err
+		}
+		derived = append(derived, account)
+	}
+	return derived, nil
+}
+
+// deriveKey derives the private key at the path from the seed, as specified by BIP-32.
+func deriveKey(seed []byte, path accounts.DerivationPath) (*ecdsa.PrivateKey, error) {
+	n := crypto.S256().Params().N
+
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := new(big.Int).SetBytes(sum[:32]), sum[32:]
+	if key.Sign() == 0 || key.Cmp(n) >= 0 {
+		return nil, fmt.Errorf("invalid master key")
+	}
+
+	for _, index := range path {
+		var data []byte
+		if index >= hardenedOffset {
+			data = append([]byte{0}, math.PaddedBigBytes(key, 32)...)
+		} else {
+			priv, err := crypto.ToECDSA(math.PaddedBigBytes(key, 32))
+			if err != nil {
+				return nil, err
+			}
+			data = crypto.CompressPubkey(&priv.PublicKey)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		sum := mac.Sum(nil)
+
+		// BIP-32 skips the indexes with invalid keys, which happen with a negligible probability.
+		tweak := new(big.Int).SetBytes(sum[:32])
+		if tweak.Cmp(n) >= 0 {
+			return nil, fmt.Errorf("invalid key at index %d", index)
+		}
+		key = tweak.Add(tweak, key).Mod(tweak, n)
+		if key.Sign() == 0 {
+			return nil, fmt.Errorf("invalid key at index %d", index)
+		}
+		chainCode = sum[32:]
+	}
+
+	return crypto.ToECDSA(math.PaddedBigBytes(key, 32))
+}
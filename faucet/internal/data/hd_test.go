@@ -0,0 +1,64 @@
+package data
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const testMnemonic = "test test test test test test test test test test test junk"
+
+func TestDeriveKey(t *testing.T) {
+	// Test vector 1 of BIP-32.
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	require.NoError(t, err)
+
+	for _, tc := range []struct {
+		path string
+		key  string
+	}{
+		{"m/0'", "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		{"m/0'/1", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{"m/0'/1/2'", "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+		{"m/0'/1/2'/2", "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4"},
+		{"m/0'/1/2'/2/1000000000", "471b76e389e528d6de6d816857e012c5455051cad6660850e58372a6c3e6e7c8"},
+	} {
+		path, err := accounts.ParseDerivationPath(tc.path)
+		require.NoError(t, err)
+
+		key, err := deriveKey(seed, path)
+		require.NoError(t, err)
+		require.Equal(t, tc.key, hex.EncodeToString(crypto.FromECDSA(key)), tc.path)
+	}
+}
+
+func TestMnemonicAccounts(t *testing.T) {
+	derived, err := NewMnemonicAccounts(testMnemonic, "", EthereumBasePath, 3)
+	require.NoError(t, err)
+	require.Len(t, derived, 3)
+
+	// The default accounts of Hardhat and Anvil.
+	require.Equal(t, common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"), derived[0].Address)
+	require.Equal(t, common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"), derived[1].Address)
+	require.Equal(t, common.HexToAddress("0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC"), derived[2].Address)
+
+	// Other paths and passphrases derive other accounts.
+	filecoin, err := NewMnemonicAccounts(testMnemonic, "", FilecoinBasePath, 1)
+	require.NoError(t, err)
+	require.NotEqual(t, derived[0].Address, filecoin[0].Address)
+
+	protected, err := NewMnemonicAccounts(testMnemonic, "passphrase", EthereumBasePath, 1)
+	require.NoError(t, err)
+	require.NotEqual(t, derived[0].Address, protected[0].Address)
+
+	_, err = NewMnemonicAccounts("test test test test test test test test test test test test", "", EthereumBasePath, 1)
+	require.Error(t, err)
+	_, err = NewMnemonicAccounts(testMnemonic, "", "m/44'/x", 1)
+	require.Error(t, err)
+	_, err = NewMnemonicAccounts(testMnemonic, "", EthereumBasePath, 0)
+	require.Error(t, err)
+}
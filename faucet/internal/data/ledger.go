@@ -0,0 +1,51 @@
+package data
+
+import "time"
+
+// LedgerEntry is a decision of the faucet on a funding request, recorded in the append-only ledger.
+// The recipient is recorded as funded, and in its Ethereum and Filecoin forms when it has them.
+// Entries of a bundle are recorded per asset. GasPaid, in wei, and Mined are known once the transaction is mined.
+type LedgerEntry struct {
+	ID        string     `json:"id"`
+	Time      time.Time  `json:"time"`
+	Decision  string     `json:"decision"`
+	Reason    string     `json:"reason,omitempty"`
+	IP        string     `json:"ip,omitempty"`
+	Identity  string     `json:"identity,omitempty"`
+	Recipient string     `json:"recipient"`
+	Ethereum  string     `json:"ethereum,omitempty"`
+	Filecoin  string     `json:"filecoin,omitempty"`
+	Asset     string     `json:"asset,omitempty"`
+	Bundle    string     `json:"bundle,omitempty"`
+	Amount    uint64     `json:"amount,omitempty"`
+	TxHash    string     `json:"tx_hash,omitempty"`
+	Voucher   string     `json:"voucher,omitempty"`
+	GasPaid   string     `json:"gas_paid,omitempty"`
+	Mined     *time.Time `json:"mined,omitempty"`
+}
+
+// LedgerGas is the cost of the transaction of a ledger entry, recorded once the transaction is mined.
+type LedgerGas struct {
+	GasPaid string    `json:"gas_paid"`
+	Block   uint64    `json:"block"`
+	Mined   time.Time `json:"mined"`
+}
+
+// LedgerFilter selects ledger entries, newest first.
+// Address matches any form of the recipient and its identity. From is inclusive and To exclusive.
+// Cursor is the Next value of the previous page.
+type LedgerFilter struct {
+	Address  string
+	IP       string
+	Decision string
+	From     time.Time
+	To       time.Time
+	Cursor   string
+	Limit    int
+}
+
+// LedgerPage is a page of ledger entries. Next is the cursor of the next page, empty on the last page.
+type LedgerPage struct {
+	Entries []LedgerEntry `json:"entries"`
+	Next    string        `json:"next,omitempty"`
+}
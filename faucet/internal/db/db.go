@@ -4,16 +4,36 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/filecoin-project/go-address"
 	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
 	"github.com/pkg/errors"
 
 	"github.com/consensus-shipyard/calibration/faucet/internal/data"
 )
 
 var (
-	totalInfoKey = datastore.NewKey("total_info_key")
+	totalInfoKey        = datastore.NewKey("total_info_key")
+	refillTotalKey      = datastore.NewKey("refill").ChildString("total")
+	refillRecordKey     = datastore.NewKey("refill").ChildString("log")
+	rotationRecordKey   = datastore.NewKey("rotation").ChildString("log")
+	ledgerEntryPrefix   = datastore.NewKey("ledger").ChildString("entries")
+	ledgerAddressPrefix = datastore.NewKey("ledger").ChildString("address")
+	ledgerIPPrefix      = datastore.NewKey("ledger").ChildString("ip")
+	ledgerGasPrefix     = datastore.NewKey("ledger").ChildString("gas")
+	ledgerUnminedPrefix = datastore.NewKey("ledger").ChildString("unmined")
+	voucherExpiryPrefix = datastore.NewKey("voucher-expiries")
+	voucherOutstanding  = datastore.NewKey("voucher-outstanding")
+	voucherClaimsKey    = datastore.NewKey("voucher-claims")
+	crossNetPendingKey  = datastore.NewKey("crossnet-pending")
+	dripPendingKey      = datastore.NewKey("drips-pending")
 )
 
 type Database struct {
@@ -27,9 +47,27 @@ func NewDatabase(store datastore.Datastore) *Database {
 }
 
 func (db *Database) GetTotalInfo(ctx context.Context) (data.TotalInfo, error) {
+	return db.GetAssetTotalInfo(ctx, "")
+}
+
+func (db *Database) GetAddrInfo(ctx context.Context, addr common.Address) (data.AddrInfo, error) {
+	return db.GetAssetAddrInfo(ctx, "", addr)
+}
+
+func (db *Database) UpdateAddrInfo(ctx context.Context, targetAddr common.Address, info data.AddrInfo) error {
+	return db.UpdateAssetAddrInfo(ctx, "", targetAddr, info)
+}
+
+func (db *Database) UpdateTotalInfo(ctx context.Context, info data.TotalInfo) error {
+	return db.UpdateAssetTotalInfo(ctx, "", info)
+}
+
+// GetAssetTotalInfo returns the total info of the asset.
+// The empty asset is the native coin.
+func (db *Database) GetAssetTotalInfo(ctx context.Context, asset string) (data.TotalInfo, error) {
 	var info data.TotalInfo
 
-	b, err := db.store.Get(ctx, totalInfoKey)
+	b, err := db.store.Get(ctx, totalKey(asset))
 	if err != nil && !errors.Is(err, datastore.ErrNotFound) {
 		return data.TotalInfo{}, fmt.Errorf("failed to get total info: %w", err)
 	}
@@ -42,10 +80,22 @@ func (db *Database) GetTotalInfo(ctx context.Context) (data.TotalInfo, error) {
 	return info, nil
 }
 
-func (db *Database) GetAddrInfo(ctx context.Context, addr common.Address) (data.AddrInfo, error) {
+// GetAssetAddrInfo returns the address info of the asset.
+// The empty asset is the native coin.
+func (db *Database) GetAssetAddrInfo(ctx context.Context, asset string, addr common.Address) (data.AddrInfo, error) {
+	return db.GetAssetAccountInfo(ctx, asset, addr.String())
+}
+
+func (db *Database) UpdateAssetAddrInfo(ctx context.Context, asset string, targetAddr common.Address, info data.AddrInfo) error {
+	return db.UpdateAssetAccountInfo(ctx, asset, targetAddr.String(), info)
+}
+
+// GetAssetAccountInfo returns the address info of the asset for an account
+// that is identified by the string form of its address.
+func (db *Database) GetAssetAccountInfo(ctx context.Context, asset string, account string) (data.AddrInfo, error) {
 	var info data.AddrInfo
 
-	b, err := db.store.Get(ctx, addrKey(addr))
+	b, err := db.store.Get(ctx, addrKey(asset, account))
 	if err != nil && !errors.Is(err, datastore.ErrNotFound) {
 		return data.AddrInfo{}, fmt.Errorf("failed to get addr info: %w", err)
 	}
@@ -58,13 +108,13 @@ func (db *Database) GetAddrInfo(ctx context.Context, addr common.Address) (data.
 	return info, nil
 }
 
-func (db *Database) UpdateAddrInfo(ctx context.Context, targetAddr common.Address, info data.AddrInfo) error {
+func (db *Database) UpdateAssetAccountInfo(ctx context.Context, asset string, account string, info data.AddrInfo) error {
 	bytes, err := json.Marshal(info)
 	if err != nil {
 		return err
 	}
 
-	err = db.store.Put(ctx, addrKey(targetAddr), bytes)
+	err = db.store.Put(ctx, addrKey(asset, account), bytes)
 	if err != nil {
 		return fmt.Errorf("failed to put addr info into db: %w", err)
 	}
@@ -72,13 +122,13 @@ func (db *Database) UpdateAddrInfo(ctx context.Context, targetAddr common.Addres
 	return nil
 }
 
-func (db *Database) UpdateTotalInfo(ctx context.Context, info data.TotalInfo) error {
+func (db *Database) UpdateAssetTotalInfo(ctx context.Context, asset string, info data.TotalInfo) error {
 	bytes, err := json.Marshal(info)
 	if err != nil {
 		return err
 	}
 
-	err = db.store.Put(ctx, totalInfoKey, bytes)
+	err = db.store.Put(ctx, totalKey(asset), bytes)
 	if err != nil {
 		return fmt.Errorf("failed to put total info into db: %w", err)
 	}
@@ -86,6 +136,761 @@ func (db *Database) UpdateTotalInfo(ctx context.Context, info data.TotalInfo) er
 	return nil
 }
 
-func addrKey(addr common.Address) datastore.Key {
-	return datastore.NewKey(addr.String() + ":value")
+// GetCrossNetMessage returns the cross-net message sent in the transaction.
+// The zero message is returned if it is unknown.
+func (db *Database) GetCrossNetMessage(ctx context.Context, txHash string) (data.CrossNetMessage, error) {
+	var msg data.CrossNetMessage
+
+	b, err := db.store.Get(ctx, crossNetKey(txHash))
+	if err != nil && !errors.Is(err, datastore.ErrNotFound) {
+		return data.CrossNetMessage{}, fmt.Errorf("failed to get cross-net message: %w", err)
+	}
+	if errors.Is(err, datastore.ErrNotFound) {
+		return msg, nil
+	}
+	if err := json.Unmarshal(b, &msg); err != nil {
+		return data.CrossNetMessage{}, fmt.Errorf("failed to decode cross-net message: %w", err)
+	}
+	return msg, nil
+}
+
+// AddCrossNetMessage stores the message and indexes it as pending until it is resolved.
+func (db *Database) AddCrossNetMessage(ctx context.Context, msg data.CrossNetMessage) error {
+	bytes, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(ctx, func(tx *Database) error {
+		if err := tx.store.Put(ctx, crossNetKey(msg.TxHash), bytes); err != nil {
+			return fmt.Errorf("failed to put cross-net message into db: %w", err)
+		}
+		if err := tx.store.Put(ctx, crossNetPendingKey.ChildString(msg.TxHash), nil); err != nil {
+			return fmt.Errorf("failed to put pending cross-net message into db: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetPendingCrossNetMessages returns the cross-net messages that are not resolved.
+func (db *Database) GetPendingCrossNetMessages(ctx context.Context) ([]data.CrossNetMessage, error) {
+	res, err := db.store.Query(ctx, query.Query{
+		Prefix:   crossNetPendingKey.String(),
+		KeysOnly: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending cross-net messages: %w", err)
+	}
+	defer res.Close() // nolint
+
+	msgs := make([]data.CrossNetMessage, 0)
+	for entry := range res.Next() {
+		if entry.Error != nil {
+			return nil, fmt.Errorf("failed to get pending cross-net message: %w", entry.Error)
+		}
+		msg, err := db.GetCrossNetMessage(ctx, datastore.NewKey(entry.Key).Name())
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs, nil
+}
+
+// ResolveCrossNetMessage stores the final state of the message and removes it from the pending index.
+func (db *Database) ResolveCrossNetMessage(ctx context.Context, msg data.CrossNetMessage) error {
+	bytes, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(ctx, func(tx *Database) error {
+		if err := tx.store.Put(ctx, crossNetKey(msg.TxHash), bytes); err != nil {
+			return fmt.Errorf("failed to put cross-net message into db: %w", err)
+		}
+		if err := tx.store.Delete(ctx, crossNetPendingKey.ChildString(msg.TxHash)); err != nil {
+			return fmt.Errorf("failed to delete pending cross-net message from db: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateCrossNetMessage stores the state of the message.
+func (db *Database) UpdateCrossNetMessage(ctx context.Context, msg data.CrossNetMessage) error {
+	bytes, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	err = db.store.Put(ctx, crossNetKey(msg.TxHash), bytes)
+	if err != nil {
+		return fmt.Errorf("failed to put cross-net message into db: %w", err)
+	}
+
+	return nil
+}
+
+// GetDripRecord returns the drip sent in the transaction.
+// The zero record is returned if it is unknown.
+func (db *Database) GetDripRecord(ctx context.Context, txHash string) (data.DripRecord, error) {
+	var rec data.DripRecord
+
+	b, err := db.store.Get(ctx, dripKey(txHash))
+	if errors.Is(err, datastore.ErrNotFound) {
+		return rec, nil
+	}
+	if err != nil {
+		return data.DripRecord{}, fmt.Errorf("failed to get drip: %w", err)
+	}
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return data.DripRecord{}, fmt.Errorf("failed to decode drip: %w", err)
+	}
+	return rec, nil
+}
+
+// AddDripRecord stores the drip and indexes it as pending until it is resolved.
+func (db *Database) AddDripRecord(ctx context.Context, rec data.DripRecord) error {
+	bytes, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(ctx, func(tx *Database) error {
+		if err := tx.store.Put(ctx, dripKey(rec.TxHash), bytes); err != nil {
+			return fmt.Errorf("failed to put drip into db: %w", err)
+		}
+		if err := tx.store.Put(ctx, dripPendingKey.ChildString(rec.TxHash), nil); err != nil {
+			return fmt.Errorf("failed to put pending drip into db: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetPendingDripRecords returns the drips that are not resolved.
+func (db *Database) GetPendingDripRecords(ctx context.Context) ([]data.DripRecord, error) {
+	res, err := db.store.Query(ctx, query.Query{
+		Prefix:   dripPendingKey.String(),
+		KeysOnly: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query pending drips: %w", err)
+	}
+	defer res.Close() // nolint
+
+	records := make([]data.DripRecord, 0)
+	for entry := range res.Next() {
+		if entry.Error != nil {
+			return nil, fmt.Errorf("failed to get pending drip: %w", entry.Error)
+		}
+		rec, err := db.GetDripRecord(ctx, datastore.NewKey(entry.Key).Name())
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// ResolveDripRecord stores the final state of the drip and removes it from the pending index.
+func (db *Database) ResolveDripRecord(ctx context.Context, rec data.DripRecord) error {
+	bytes, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(ctx, func(tx *Database) error {
+		if err := tx.store.Put(ctx, dripKey(rec.TxHash), bytes); err != nil {
+			return fmt.Errorf("failed to put drip into db: %w", err)
+		}
+		if err := tx.store.Delete(ctx, dripPendingKey.ChildString(rec.TxHash)); err != nil {
+			return fmt.Errorf("failed to delete pending drip from db: %w", err)
+		}
+		return nil
+	})
+}
+
+// AddVoucher stores the voucher and indexes it by its expiry until it is resolved.
+// Its amount is added to the outstanding sum.
+func (db *Database) AddVoucher(ctx context.Context, rec data.VoucherRecord) error {
+	bytes, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	amount, ok := new(big.Int).SetString(rec.Amount, 10)
+	if !ok {
+		return fmt.Errorf("invalid voucher amount %q", rec.Amount)
+	}
+
+	return db.Update(ctx, func(tx *Database) error {
+		// The sum is updated first, a sum from the unresolved vouchers must not count this one.
+		if err := tx.addOutstandingVouchers(ctx, amount); err != nil {
+			return err
+		}
+		if err := tx.store.Put(ctx, voucherKey(rec.Nonce), bytes); err != nil {
+			return fmt.Errorf("failed to put voucher into db: %w", err)
+		}
+		if err := tx.store.Put(ctx, voucherExpiryKey(rec.Expiry, rec.Nonce), nil); err != nil {
+			return fmt.Errorf("failed to put voucher expiry into db: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetVoucher returns the voucher with the nonce.
+// The zero record is returned if it is unknown.
+func (db *Database) GetVoucher(ctx context.Context, nonce string) (data.VoucherRecord, error) {
+	var rec data.VoucherRecord
+
+	b, err := db.store.Get(ctx, voucherKey(nonce))
+	if errors.Is(err, datastore.ErrNotFound) {
+		return rec, nil
+	}
+	if err != nil {
+		return data.VoucherRecord{}, fmt.Errorf("failed to get voucher: %w", err)
+	}
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return data.VoucherRecord{}, fmt.Errorf("failed to decode voucher: %w", err)
+	}
+	return rec, nil
+}
+
+// GetExpiredVouchers returns the unresolved vouchers that expire before the time, in the order of expiry.
+func (db *Database) GetExpiredVouchers(ctx context.Context, before int64) ([]data.VoucherRecord, error) {
+	res, err := db.store.Query(ctx, query.Query{
+		Prefix:   voucherExpiryPrefix.String(),
+		Orders:   []query.Order{query.OrderByKey{}},
+		KeysOnly: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query voucher expiries: %w", err)
+	}
+	defer res.Close() // nolint
+
+	records := make([]data.VoucherRecord, 0)
+	for entry := range res.Next() {
+		if entry.Error != nil {
+			return nil, fmt.Errorf("failed to get voucher expiry: %w", entry.Error)
+		}
+		key := datastore.NewKey(entry.Key)
+		expiry, err := strconv.ParseInt(key.Parent().Name(), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid voucher expiry key %s: %w", key, err)
+		}
+		if expiry >= before {
+			break
+		}
+		rec, err := db.GetVoucher(ctx, key.Name())
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+// GetUnresolvedVouchers returns the vouchers that are not resolved yet, in the order of expiry.
+func (db *Database) GetUnresolvedVouchers(ctx context.Context) ([]data.VoucherRecord, error) {
+	return db.GetExpiredVouchers(ctx, math.MaxInt64)
+}
+
+// ResolveVoucher stores the final state of the voucher and removes it from the expiry index.
+// Its amount is taken off the outstanding sum, once.
+func (db *Database) ResolveVoucher(ctx context.Context, rec data.VoucherRecord) error {
+	bytes, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	amount, ok := new(big.Int).SetString(rec.Amount, 10)
+	if !ok {
+		return fmt.Errorf("invalid voucher amount %q", rec.Amount)
+	}
+
+	return db.Update(ctx, func(tx *Database) error {
+		unresolved, err := tx.store.Has(ctx, voucherExpiryKey(rec.Expiry, rec.Nonce))
+		if err != nil {
+			return fmt.Errorf("failed to get voucher expiry: %w", err)
+		}
+		if unresolved {
+			if err := tx.addOutstandingVouchers(ctx, amount.Neg(amount)); err != nil {
+				return err
+			}
+		}
+		if err := tx.store.Put(ctx, voucherKey(rec.Nonce), bytes); err != nil {
+			return fmt.Errorf("failed to put voucher into db: %w", err)
+		}
+		if err := tx.store.Delete(ctx, voucherExpiryKey(rec.Expiry, rec.Nonce)); err != nil {
+			return fmt.Errorf("failed to delete voucher expiry from db: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetOutstandingVouchers returns the sum of the amounts of the vouchers that are not resolved yet.
+// Databases written before the sum was kept get it from the unresolved vouchers.
+func (db *Database) GetOutstandingVouchers(ctx context.Context) (*big.Int, error) {
+	b, err := db.store.Get(ctx, voucherOutstanding)
+	if errors.Is(err, datastore.ErrNotFound) {
+		records, err := db.GetUnresolvedVouchers(ctx)
+		if err != nil {
+			return nil, err
+		}
+		sum := new(big.Int)
+		for _, rec := range records {
+			amount, ok := new(big.Int).SetString(rec.Amount, 10)
+			if !ok {
+				return nil, fmt.Errorf("invalid voucher amount %q", rec.Amount)
+			}
+			sum.Add(sum, amount)
+		}
+		return sum, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outstanding vouchers: %w", err)
+	}
+	sum, ok := new(big.Int).SetString(string(b), 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid outstanding vouchers %q", b)
+	}
+	return sum, nil
+}
+
+func (db *Database) addOutstandingVouchers(ctx context.Context, amount *big.Int) error {
+	sum, err := db.GetOutstandingVouchers(ctx)
+	if err != nil {
+		return err
+	}
+	sum.Add(sum, amount)
+	if err := db.store.Put(ctx, voucherOutstanding, []byte(sum.String())); err != nil {
+		return fmt.Errorf("failed to put outstanding vouchers into db: %w", err)
+	}
+	return nil
+}
+
+// GetVoucherClaimsBlock returns the first block whose claims of vouchers are not checked yet.
+// Zero is returned if no block was checked.
+func (db *Database) GetVoucherClaimsBlock(ctx context.Context) (uint64, error) {
+	b, err := db.store.Get(ctx, voucherClaimsKey)
+	if errors.Is(err, datastore.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get voucher claims block: %w", err)
+	}
+	block, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid voucher claims block %q: %w", b, err)
+	}
+	return block, nil
+}
+
+func (db *Database) UpdateVoucherClaimsBlock(ctx context.Context, block uint64) error {
+	err := db.store.Put(ctx, voucherClaimsKey, []byte(strconv.FormatUint(block, 10)))
+	if err != nil {
+		return fmt.Errorf("failed to put voucher claims block into db: %w", err)
+	}
+	return nil
+}
+
+// GetResolvedAddress returns the ID address the Filecoin address was resolved to.
+// address.Undef is returned if the address has not been resolved.
+func (db *Database) GetResolvedAddress(ctx context.Context, addr address.Address) (address.Address, error) {
+	b, err := db.store.Get(ctx, resolvedKey(addr))
+	if errors.Is(err, datastore.ErrNotFound) {
+		return address.Undef, nil
+	}
+	if err != nil {
+		return address.Undef, fmt.Errorf("failed to get resolved address: %w", err)
+	}
+	id, err := address.NewFromBytes(b)
+	if err != nil {
+		return address.Undef, fmt.Errorf("failed to decode resolved address: %w", err)
+	}
+	return id, nil
+}
+
+func (db *Database) UpdateResolvedAddress(ctx context.Context, addr, id address.Address) error {
+	err := db.store.Put(ctx, resolvedKey(addr), id.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to put resolved address into db: %w", err)
+	}
+	return nil
+}
+
+// GetRobustAddress returns the robust address the ID address was resolved to.
+// address.Undef is returned if the address has not been resolved.
+func (db *Database) GetRobustAddress(ctx context.Context, id address.Address) (address.Address, error) {
+	b, err := db.store.Get(ctx, robustKey(id))
+	if errors.Is(err, datastore.ErrNotFound) {
+		return address.Undef, nil
+	}
+	if err != nil {
+		return address.Undef, fmt.Errorf("failed to get robust address: %w", err)
+	}
+	robust, err := address.NewFromBytes(b)
+	if err != nil {
+		return address.Undef, fmt.Errorf("failed to decode robust address: %w", err)
+	}
+	return robust, nil
+}
+
+func (db *Database) UpdateRobustAddress(ctx context.Context, id, robust address.Address) error {
+	err := db.store.Put(ctx, robustKey(id), robust.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to put robust address into db: %w", err)
+	}
+	return nil
+}
+
+// GetRefillTotal returns the amount sent by the treasury in the current refill window.
+func (db *Database) GetRefillTotal(ctx context.Context) (data.TotalInfo, error) {
+	var info data.TotalInfo
+
+	b, err := db.store.Get(ctx, refillTotalKey)
+	if errors.Is(err, datastore.ErrNotFound) {
+		return info, nil
+	}
+	if err != nil {
+		return data.TotalInfo{}, fmt.Errorf("failed to get refill total: %w", err)
+	}
+	if err := json.Unmarshal(b, &info); err != nil {
+		return data.TotalInfo{}, fmt.Errorf("failed to decode refill total: %w", err)
+	}
+	return info, nil
+}
+
+func (db *Database) UpdateRefillTotal(ctx context.Context, info data.TotalInfo) error {
+	bytes, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+
+	err = db.store.Put(ctx, refillTotalKey, bytes)
+	if err != nil {
+		return fmt.Errorf("failed to put refill total into db: %w", err)
+	}
+
+	return nil
+}
+
+// AddRefillRecord appends the record to the refill audit trail.
+func (db *Database) AddRefillRecord(ctx context.Context, record data.RefillRecord) error {
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	// Keys are ordered by the zero-padded time of the record.
+	key := refillRecordKey.ChildString(fmt.Sprintf("%020d", record.Time.UnixNano()))
+	err = db.store.Put(ctx, key, bytes)
+	if err != nil {
+		return fmt.Errorf("failed to put refill record into db: %w", err)
+	}
+
+	return nil
+}
+
+// GetRefillRecords returns the refill audit trail, oldest first.
+func (db *Database) GetRefillRecords(ctx context.Context) ([]data.RefillRecord, error) {
+	res, err := db.store.Query(ctx, query.Query{
+		Prefix: refillRecordKey.String(),
+		Orders: []query.Order{query.OrderByKey{}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query refill records: %w", err)
+	}
+	defer res.Close() // nolint
+
+	records := make([]data.RefillRecord, 0)
+	for entry := range res.Next() {
+		if entry.Error != nil {
+			return nil, fmt.Errorf("failed to get refill record: %w", entry.Error)
+		}
+		var record data.RefillRecord
+		if err := json.Unmarshal(entry.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to decode refill record: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// PutRotationRecord stores the record in the rotation audit trail.
+// The record replaces the previous state of the rotation started at the same time.
+func (db *Database) PutRotationRecord(ctx context.Context, record data.RotationRecord) error {
+	bytes, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	key := rotationRecordKey.ChildString(fmt.Sprintf("%020d", record.Time.UnixNano()))
+	err = db.store.Put(ctx, key, bytes)
+	if err != nil {
+		return fmt.Errorf("failed to put rotation record into db: %w", err)
+	}
+
+	return nil
+}
+
+// GetRotationRecords returns the rotation audit trail, oldest first.
+func (db *Database) GetRotationRecords(ctx context.Context) ([]data.RotationRecord, error) {
+	res, err := db.store.Query(ctx, query.Query{
+		Prefix: rotationRecordKey.String(),
+		Orders: []query.Order{query.OrderByKey{}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rotation records: %w", err)
+	}
+	defer res.Close() // nolint
+
+	records := make([]data.RotationRecord, 0)
+	for entry := range res.Next() {
+		if entry.Error != nil {
+			return nil, fmt.Errorf("failed to get rotation record: %w", entry.Error)
+		}
+		var record data.RotationRecord
+		if err := json.Unmarshal(entry.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to decode rotation record: %w", err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// AddLedgerEntry appends the entry to the ledger, and indexes it by the forms of its recipient and by IP.
+// Entries with a transaction are also indexed as unmined until the gas of the transaction is recorded.
+// The entry and its indexes are written together. Entries are never updated.
+func (db *Database) AddLedgerEntry(ctx context.Context, entry data.LedgerEntry) error {
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(ctx, func(tx *Database) error {
+		if err := tx.store.Put(ctx, ledgerEntryPrefix.ChildString(entry.ID), bytes); err != nil {
+			return fmt.Errorf("failed to put ledger entry into db: %w", err)
+		}
+
+		for _, key := range ledgerIndexKeys(entry) {
+			if err := tx.store.Put(ctx, key, []byte{}); err != nil {
+				return fmt.Errorf("failed to put ledger index into db: %w", err)
+			}
+		}
+
+		if entry.TxHash != "" {
+			if err := tx.store.Put(ctx, ledgerUnminedPrefix.ChildString(entry.ID), []byte{}); err != nil {
+				return fmt.Errorf("failed to put unmined ledger entry into db: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetUnminedLedgerEntries returns the ledger entries with a transaction whose gas is not recorded, oldest first.
+func (db *Database) GetUnminedLedgerEntries(ctx context.Context) ([]data.LedgerEntry, error) {
+	res, err := db.store.Query(ctx, query.Query{
+		Prefix:   ledgerUnminedPrefix.String(),
+		Orders:   []query.Order{query.OrderByKey{}},
+		KeysOnly: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query unmined ledger entries: %w", err)
+	}
+	defer res.Close() // nolint
+
+	entries := make([]data.LedgerEntry, 0)
+	for result := range res.Next() {
+		if result.Error != nil {
+			return nil, fmt.Errorf("failed to get unmined ledger entry: %w", result.Error)
+		}
+		entry, err := db.getLedgerEntry(ctx, datastore.RawKey(result.Key).BaseNamespace())
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// DropUnminedLedgerEntry removes the entry from the unmined index without recording its gas,
+// for transactions whose gas can't be looked up.
+func (db *Database) DropUnminedLedgerEntry(ctx context.Context, id string) error {
+	if err := db.store.Delete(ctx, ledgerUnminedPrefix.ChildString(id)); err != nil {
+		return fmt.Errorf("failed to delete unmined ledger entry from db: %w", err)
+	}
+	return nil
+}
+
+// GetLedgerEntries returns the ledger entries selected by the filter, newest first,
+// and the cursor of the next page if there are more entries.
+func (db *Database) GetLedgerEntries(ctx context.Context, f data.LedgerFilter) ([]data.LedgerEntry, string, error) {
+	prefix := ledgerEntryPrefix
+	switch {
+	case f.Address != "":
+		prefix = ledgerAddressPrefix.ChildString(strings.ToLower(f.Address))
+	case f.IP != "":
+		prefix = ledgerIPPrefix.ChildString(f.IP)
+	}
+
+	res, err := db.store.Query(ctx, query.Query{
+		Prefix:   prefix.String(),
+		Orders:   []query.Order{query.OrderByKeyDescending{}},
+		KeysOnly: true,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to query ledger: %w", err)
+	}
+	defer res.Close() // nolint
+
+	// IDs start with the time of the entry, so the time range is a range of IDs.
+	var from, to string
+	if !f.From.IsZero() {
+		from = ledgerTime(f.From)
+	}
+	if !f.To.IsZero() {
+		to = ledgerTime(f.To)
+	}
+
+	entries := make([]data.LedgerEntry, 0)
+	for result := range res.Next() {
+		if result.Error != nil {
+			return nil, "", fmt.Errorf("failed to get ledger key: %w", result.Error)
+		}
+		id := datastore.RawKey(result.Key).BaseNamespace()
+		if (f.Cursor != "" && id >= f.Cursor) || (to != "" && id >= to) {
+			continue
+		}
+		if id < from {
+			break
+		}
+
+		entry, err := db.getLedgerEntry(ctx, id)
+		if err != nil {
+			return nil, "", err
+		}
+		if (f.IP != "" && entry.IP != f.IP) || (f.Decision != "" && entry.Decision != f.Decision) {
+			continue
+		}
+
+		if len(entries) == f.Limit {
+			return entries, entries[len(entries)-1].ID, nil
+		}
+		entries = append(entries, entry)
+	}
+	return entries, "", nil
+}
+
+func (db *Database) getLedgerEntry(ctx context.Context, id string) (data.LedgerEntry, error) {
+	b, err := db.store.Get(ctx, ledgerEntryPrefix.ChildString(id))
+	if err != nil {
+		return data.LedgerEntry{}, fmt.Errorf("failed to get ledger entry %s: %w", id, err)
+	}
+	var entry data.LedgerEntry
+	if err := json.Unmarshal(b, &entry); err != nil {
+		return data.LedgerEntry{}, fmt.Errorf("failed to decode ledger entry: %w", err)
+	}
+
+	b, err = db.store.Get(ctx, ledgerGasPrefix.ChildString(id))
+	if errors.Is(err, datastore.ErrNotFound) {
+		return entry, nil
+	}
+	if err != nil {
+		return data.LedgerEntry{}, fmt.Errorf("failed to get ledger gas: %w", err)
+	}
+	var gas data.LedgerGas
+	if err := json.Unmarshal(b, &gas); err != nil {
+		return data.LedgerEntry{}, fmt.Errorf("failed to decode ledger gas: %w", err)
+	}
+	entry.GasPaid = gas.GasPaid
+	entry.Mined = &gas.Mined
+	return entry, nil
+}
+
+// AddLedgerGas records the cost of the transaction of the ledger entry, and removes the entry from the unmined index.
+func (db *Database) AddLedgerGas(ctx context.Context, id string, gas data.LedgerGas) error {
+	bytes, err := json.Marshal(gas)
+	if err != nil {
+		return err
+	}
+
+	return db.Update(ctx, func(tx *Database) error {
+		if err := tx.store.Put(ctx, ledgerGasPrefix.ChildString(id), bytes); err != nil {
+			return fmt.Errorf("failed to put ledger gas into db: %w", err)
+		}
+		if err := tx.store.Delete(ctx, ledgerUnminedPrefix.ChildString(id)); err != nil {
+			return fmt.Errorf("failed to delete unmined ledger entry from db: %w", err)
+		}
+		return nil
+	})
+}
+
+// LedgerID returns a ledger entry ID ordered by the time, unique with the random suffix.
+func LedgerID(t time.Time, suffix uint32) string {
+	return fmt.Sprintf("%s-%08x", ledgerTime(t), suffix)
+}
+
+func ledgerTime(t time.Time) string {
+	return fmt.Sprintf("%020d", t.UnixNano())
+}
+
+// ledgerIndexKeys returns the index keys of the entry: one per distinct form of the recipient, and its IP.
+func ledgerIndexKeys(entry data.LedgerEntry) []datastore.Key {
+	var keys []datastore.Key
+	seen := make(map[string]bool)
+	for _, addr := range []string{entry.Recipient, entry.Ethereum, entry.Filecoin, entry.Identity} {
+		addr = strings.ToLower(addr)
+		if addr == "" || seen[addr] {
+			continue
+		}
+		seen[addr] = true
+		keys = append(keys, ledgerAddressPrefix.ChildString(addr).ChildString(entry.ID))
+	}
+	if entry.IP != "" {
+		keys = append(keys, ledgerIPPrefix.ChildString(entry.IP).ChildString(entry.ID))
+	}
+	return keys
+}
+
+func resolvedKey(addr address.Address) datastore.Key {
+	return datastore.NewKey("resolved").ChildString(addr.String())
+}
+
+func robustKey(id address.Address) datastore.Key {
+	return datastore.NewKey("robust").ChildString(id.String())
+}
+
+func crossNetKey(txHash string) datastore.Key {
+	return datastore.NewKey("crossnet").ChildString(txHash)
+}
+
+func voucherKey(nonce string) datastore.Key {
+	return datastore.NewKey("vouchers").ChildString(nonce)
+}
+
+// voucherExpiryKey orders the vouchers by the zero-padded expiry.
+func voucherExpiryKey(expiry int64, nonce string) datastore.Key {
+	return voucherExpiryPrefix.ChildString(fmt.Sprintf("%020d", expiry)).ChildString(nonce)
+}
+
+func dripKey(txHash string) datastore.Key {
+	return datastore.NewKey("drips").ChildString(txHash)
+}
+
+func addrKey(asset string, account string) datastore.Key {
+	k := datastore.NewKey(account + ":value")
+	if asset == "" {
+		return k
+	}
+	return assetKey(asset).Child(k)
+}
+
+func totalKey(asset string) datastore.Key {
+	if asset == "" {
+		return totalInfoKey
+	}
+	return assetKey(asset).Child(totalInfoKey)
+}
+
+func assetKey(asset string) datastore.Key {
+	return datastore.NewKey("assets").ChildString(asset)
 }
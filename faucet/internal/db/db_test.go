@@ -2,40 +2,31 @@ package db
 
 import (
 	"context"
-	"os"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	datastore "github.com/ipfs/go-ds-leveldb"
 	"github.com/stretchr/testify/require"
-	ldbopts "github.com/syndtr/goleveldb/leveldb/opt"
 
 	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/store"
 )
 
 const (
-	dbTestStorePath = "./_db_test_store"
-	dbTestAddr1     = "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d"
+	dbTestAddr1 = "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d"
 )
 
 func Test_Faucet(t *testing.T) {
-	store, err := datastore.NewDatastore(dbTestStorePath, &datastore.Options{
-		Compression: ldbopts.NoCompression,
-		NoSync:      false,
-		Strict:      ldbopts.StrictAll,
-		ReadOnly:    false,
-	})
+	ds, err := store.Open(store.Memory, store.Options{})
 	require.NoError(t, err)
 
 	defer func() {
-		err = store.Close()
-		require.NoError(t, err)
-		err = os.RemoveAll(dbTestStorePath)
+		err = ds.Close()
 		require.NoError(t, err)
 	}()
 
-	db := NewDatabase(store)
+	db := NewDatabase(ds)
 
 	ctx := context.Background()
 
@@ -73,3 +64,40 @@ func Test_Faucet(t *testing.T) {
 	require.Equal(t, newTotalInfo.Amount, totalInfo.Amount)
 	require.Equal(t, true, newTotalInfo.LatestTransfer.Equal(totalInfo.LatestTransfer))
 }
+
+func Test_OutstandingVouchers(t *testing.T) {
+	ds, err := store.Open(store.Memory, store.Options{})
+	require.NoError(t, err)
+	defer ds.Close() // nolint
+
+	db := NewDatabase(ds)
+	ctx := context.Background()
+
+	voucher := func(nonce, amount string) data.VoucherRecord {
+		return data.VoucherRecord{Voucher: data.Voucher{Nonce: nonce, Amount: amount, Expiry: 100}}
+	}
+
+	// A database without the sum gets it from the unresolved vouchers.
+	v1 := voucher("1", "10")
+	b, err := json.Marshal(v1)
+	require.NoError(t, err)
+	require.NoError(t, ds.Put(ctx, voucherKey(v1.Nonce), b))
+	require.NoError(t, ds.Put(ctx, voucherExpiryKey(v1.Expiry, v1.Nonce), nil))
+
+	sum, err := db.GetOutstandingVouchers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "10", sum.String())
+
+	v2 := voucher("2", "25")
+	require.NoError(t, db.AddVoucher(ctx, v2))
+	sum, err = db.GetOutstandingVouchers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "35", sum.String())
+
+	// A voucher is taken off the sum once.
+	require.NoError(t, db.ResolveVoucher(ctx, v1))
+	require.NoError(t, db.ResolveVoucher(ctx, v1))
+	sum, err = db.GetOutstandingVouchers(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "25", sum.String())
+}
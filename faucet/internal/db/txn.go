@@ -0,0 +1,156 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+)
+
+// Update runs fn with a database whose writes are committed together when fn returns,
+// so that related records, like the quotas of a grant and its ledger entry, are never stored partially.
+// The writes are discarded if fn returns an error.
+//
+// The writes are made in a transaction if the store supports them, and in a batch otherwise.
+// Reads of the database passed to fn see its writes, except for queries of batched stores.
+// Writes must not be made through the outer database while fn runs, a transaction may block them.
+func (db *Database) Update(ctx context.Context, fn func(tx *Database) error) error {
+	if ts, ok := db.store.(datastore.TxnDatastore); ok {
+		txn, err := ts.NewTransaction(ctx, false)
+		if err != nil {
+			return fmt.Errorf("failed to open transaction: %w", err)
+		}
+		defer txn.Discard(ctx)
+
+		if err := fn(&Database{store: txnStore{txn}}); err != nil {
+			return err
+		}
+		if err := txn.Commit(ctx); err != nil {
+			return fmt.Errorf("failed to commit transaction: %w", err)
+		}
+		return nil
+	}
+
+	var batch datastore.Batch
+	if bs, ok := db.store.(datastore.Batching); ok {
+		var err error
+		if batch, err = bs.Batch(ctx); err != nil {
+			return fmt.Errorf("failed to open batch: %w", err)
+		}
+	} else {
+		// Stores without batches get the writes one by one, they are not atomic.
+		batch = datastore.NewBasicBatch(db.store)
+	}
+
+	if err := fn(&Database{store: newBatchStore(db.store, batch)}); err != nil {
+		return err
+	}
+	if err := batch.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit batch: %w", err)
+	}
+	return nil
+}
+
+// txnStore is a datastore writing in a transaction.
+type txnStore struct {
+	datastore.Txn
+}
+
+func (s txnStore) Sync(_ context.Context, _ datastore.Key) error {
+	return nil
+}
+
+func (s txnStore) Close() error {
+	return nil
+}
+
+// batchStore is a datastore writing in a batch. It reads the store, overlaid with the writes of the batch.
+type batchStore struct {
+	store datastore.Datastore
+	batch datastore.Batch
+
+	mu     sync.Mutex
+	writes map[datastore.Key][]byte
+}
+
+func newBatchStore(store datastore.Datastore, batch datastore.Batch) *batchStore {
+	return &batchStore{
+		store:  store,
+		batch:  batch,
+		writes: make(map[datastore.Key][]byte),
+	}
+}
+
+// written returns the value written to the key in the batch, nil if it was deleted.
+// It reports whether the key was written.
+func (s *batchStore) written(key datastore.Key) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.writes[key]
+	return value, ok
+}
+
+func (s *batchStore) Get(ctx context.Context, key datastore.Key) ([]byte, error) {
+	if value, ok := s.written(key); ok {
+		if value == nil {
+			return nil, datastore.ErrNotFound
+		}
+		return value, nil
+	}
+	return s.store.Get(ctx, key)
+}
+
+func (s *batchStore) Has(ctx context.Context, key datastore.Key) (bool, error) {
+	if value, ok := s.written(key); ok {
+		return value != nil, nil
+	}
+	return s.store.Has(ctx, key)
+}
+
+func (s *batchStore) GetSize(ctx context.Context, key datastore.Key) (int, error) {
+	if value, ok := s.written(key); ok {
+		if value == nil {
+			return -1, datastore.ErrNotFound
+		}
+		return len(value), nil
+	}
+	return s.store.GetSize(ctx, key)
+}
+
+// Query queries the store. It doesn't see the writes of the batch.
+func (s *batchStore) Query(ctx context.Context, q query.Query) (query.Results, error) {
+	return s.store.Query(ctx, q)
+}
+
+func (s *batchStore) Put(ctx context.Context, key datastore.Key, value []byte) error {
+	if err := s.batch.Put(ctx, key, value); err != nil {
+		return err
+	}
+	if value == nil {
+		value = []byte{}
+	}
+	s.mu.Lock()
+	s.writes[key] = value
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *batchStore) Delete(ctx context.Context, key datastore.Key) error {
+	if err := s.batch.Delete(ctx, key); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.writes[key] = nil
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *batchStore) Sync(_ context.Context, _ datastore.Key) error {
+	return nil
+}
+
+func (s *batchStore) Close() error {
+	return nil
+}
@@ -0,0 +1,58 @@
+package faucet
+
+import (
+	"strings"
+)
+
+// NativeAsset is the name of the native coin of the chain.
+const NativeAsset = "native"
+
+type asset struct {
+	name         string
+	amount       uint64
+	addressLimit uint64
+	totalLimit   uint64
+	token        *TokenConfig
+}
+
+// key returns the datastore namespace of the asset.
+// The native coin uses the root namespace to keep compatibility with existing databases.
+func (a *asset) key() string {
+	if a.token == nil {
+		return ""
+	}
+	return strings.ToLower(a.token.Symbol)
+}
+
+func newAssets(cfg *Config) map[string]*asset {
+	assets := map[string]*asset{
+		NativeAsset: {
+			name:         NativeAsset,
+			amount:       cfg.TransferAmount,
+			addressLimit: cfg.AddressTransferLimit,
+			totalLimit:   cfg.TotalTransferLimit,
+		},
+	}
+	for i := range cfg.Tokens {
+		t := &cfg.Tokens[i]
+		assets[strings.ToLower(t.Symbol)] = &asset{
+			name:         t.Symbol,
+			amount:       t.TransferAmount,
+			addressLimit: t.AddressTransferLimit,
+			totalLimit:   t.TotalTransferLimit,
+			token:        t,
+		}
+	}
+	return assets
+}
+
+func (s *Service) asset(name string) (*asset, error) {
+	if name == "" {
+		name = NativeAsset
+	}
+	a, ok := s.assets[strings.ToLower(name)]
+	if !ok {
+		return nil, ErrUnknownAsset
+	}
+	return a, nil
+}
@@ -0,0 +1,36 @@
+package faucet
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-cid"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/lotus"
+)
+
+// Backend is the part of the Ethereum API the faucet uses.
+// It is implemented by ethclient.Client and by the simulated backend used in tests.
+type Backend interface {
+	bind.ContractBackend
+	bind.DeployBackend
+
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+}
+
+// FilecoinBackend is the part of the Lotus API the faucet uses to send native Filecoin messages.
+// It is implemented by lotus.Client.
+type FilecoinBackend interface {
+	MpoolGetNonce(ctx context.Context, addr address.Address) (uint64, error)
+	GasEstimateMessageGas(ctx context.Context, msg *lotus.Message) (*lotus.Message, error)
+	MpoolPush(ctx context.Context, msg *lotus.SignedMessage) (cid.Cid, error)
+	StateLookupID(ctx context.Context, addr address.Address) (address.Address, error)
+	StateLookupRobustAddress(ctx context.Context, addr address.Address) (address.Address, error)
+	StateGetActor(ctx context.Context, addr address.Address) (*lotus.Actor, error)
+}
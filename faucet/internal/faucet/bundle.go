@@ -0,0 +1,204 @@
+package faucet
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/db"
+)
+
+const (
+	LegSent   = "sent"
+	LegFailed = "failed"
+)
+
+// BundleConfig is a named set of assets dispensed by a single request.
+type BundleConfig struct {
+	Name string
+	Legs []BundleLeg
+}
+
+// BundleLeg is an asset of a bundle.
+// The amount is in quota units of the asset, zero means the transfer amount of the asset.
+type BundleLeg struct {
+	Asset  string
+	Amount uint64
+}
+
+type bundleLeg struct {
+	asset  *asset
+	amount uint64
+}
+
+// FundBundle transfers all assets of the bundle to the target address.
+// The native coin is dispensed like by FundAddress, through the faucet contract in the contract mode.
+// The voucher mode grants it with a voucher instead, so bundles with the native coin are rejected then.
+// The quotas of all legs are checked and reserved together before any transfer is sent,
+// and the reservation of every failed leg is credited back.
+// The decision on every leg is recorded in the ledger with its quota, or the rejection of the bundle if no leg was sent.
+func (s *Service) FundBundle(ctx context.Context, name string, targetAddr common.Address) (data.BundleResponse, error) {
+	entry := s.ethLedgerEntry(ctx, targetAddr.Hex(), targetAddr)
+	entry.Bundle = name
+
+	resp, err := s.fundBundle(ctx, entry, name, targetAddr)
+	if len(resp.Legs) == 0 {
+		s.record(entry, "", err)
+	}
+	return resp, err
+}
+
+func (s *Service) fundBundle(ctx context.Context, entry *data.LedgerEntry, name string, targetAddr common.Address) (data.BundleResponse, error) {
+	if targetAddr == (common.Address{}) {
+		return data.BundleResponse{}, ErrInvalidAddress
+	}
+
+	identity, err := s.ethIdentity(ctx, targetAddr)
+	if err != nil {
+		return data.BundleResponse{}, err
+	}
+	entry.Identity = identity
+
+	if err := s.checkDenied(ctx, identity); err != nil {
+		return data.BundleResponse{}, err
+	}
+
+	legs, err := s.bundle(name)
+	if err != nil {
+		return data.BundleResponse{}, err
+	}
+	if s.cfg.Vouchers != nil {
+		for _, leg := range legs {
+			if leg.asset.token == nil {
+				return data.BundleResponse{}, fmt.Errorf("%w: %s", ErrVoucherBundle, name)
+			}
+		}
+	}
+
+	now := time.Now()
+
+	// The legs of an asset are checked together, so that they can't exceed its quotas between them.
+	var assets []*asset
+	amounts := make(map[*asset]uint64)
+	for _, leg := range legs {
+		if _, ok := amounts[leg.asset]; !ok {
+			assets = append(assets, leg.asset)
+		}
+		amounts[leg.asset] += leg.amount
+	}
+
+	err = s.updateQuotas(ctx, func(tx *db.Database) error {
+		for _, a := range assets {
+			if err := s.adjustQuota(ctx, tx, a, identity, now, func(q *quota) error {
+				if err := s.checkQuota(a, *q, amounts[a]); err != nil {
+					return err
+				}
+				q.addr.Amount += amounts[a]
+				q.total.Amount += amounts[a]
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return data.BundleResponse{}, err
+	}
+
+	s.log.Infof("funding %v with bundle %s is allowed", targetAddr, name)
+
+	resp := data.BundleResponse{
+		Bundle: name,
+		Legs:   make([]data.LegStatus, 0, len(legs)),
+	}
+
+	var firstErr error
+	for _, leg := range legs {
+		status := data.LegStatus{
+			Asset:  leg.asset.name,
+			Amount: leg.amount,
+		}
+
+		legEntry := *entry
+		legEntry.ID = db.LedgerID(entry.Time, rand.Uint32()) // nolint
+		legEntry.Asset = leg.asset.name
+		legEntry.Amount = leg.amount
+
+		txHash, err := s.transfer(ctx, leg.asset, identity, targetAddr, leg.amount)
+		if err != nil {
+			s.log.Errorw("failed to send bundle leg", "addr", targetAddr, "asset", leg.asset.name, "err", err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			decide(&legEntry, "", err)
+			if err := s.updateQuotas(ctx, func(tx *db.Database) error {
+				if err := s.adjustQuota(ctx, tx, leg.asset, identity, now, func(q *quota) error {
+					q.addr.Amount = remaining(q.addr.Amount, leg.amount)
+					q.total.Amount = remaining(q.total.Amount, leg.amount)
+					return nil
+				}); err != nil {
+					return err
+				}
+				return tx.AddLedgerEntry(ctx, legEntry)
+			}); err != nil {
+				s.log.Errorw("failed to credit back quota", "account", identity, "asset", leg.asset.name, "err", err)
+			}
+			status.Status = LegFailed
+			status.Error = err.Error()
+			resp.Legs = append(resp.Legs, status)
+			continue
+		}
+
+		decide(&legEntry, txHash.Hex(), nil)
+		if err := s.updateQuotas(ctx, func(tx *db.Database) error {
+			if err := s.adjustQuota(ctx, tx, leg.asset, identity, now, func(q *quota) error {
+				q.addr.LastGrant = time.Now()
+				q.addr.LastTxHash = txHash.Hex()
+				return nil
+			}); err != nil {
+				return err
+			}
+			return tx.AddLedgerEntry(ctx, legEntry)
+		}); err != nil {
+			s.log.Errorw("failed to record bundle leg", "addr", targetAddr, "asset", leg.asset.name, "err", err)
+		}
+
+		status.Status = LegSent
+		status.TxHash = txHash.Hex()
+		resp.Legs = append(resp.Legs, status)
+	}
+
+	if resp.Failed() == len(resp.Legs) {
+		return resp, firstErr
+	}
+
+	return resp, nil
+}
+
+func (s *Service) bundle(name string) ([]bundleLeg, error) {
+	for _, b := range s.cfg.Bundles {
+		if !strings.EqualFold(b.Name, name) {
+			continue
+		}
+		legs := make([]bundleLeg, 0, len(b.Legs))
+		for _, l := range b.Legs {
+			a, err := s.asset(l.Asset)
+			if err != nil {
+				return nil, err
+			}
+			amount := l.Amount
+			if amount == 0 {
+				amount = a.amount
+			}
+			legs = append(legs, bundleLeg{asset: a, amount: amount})
+		}
+		return legs, nil
+	}
+	return nil, ErrUnknownBundle
+}
@@ -0,0 +1,326 @@
+package faucet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	ftypes "github.com/consensus-shipyard/calibration/faucet/internal/types"
+)
+
+const (
+	CrossNetPending   = "pending"
+	CrossNetDelivered = "delivered"
+	CrossNetFailed    = "failed"
+	CrossNetTimeout   = "timeout"
+)
+
+const (
+	defaultCrossNetPollInterval = 5 * time.Second
+	defaultCrossNetTimeout      = 30 * time.Minute
+)
+
+// CrossNetConfig enables funding of recipients on a child subnet through the IPC gateway of the parent.
+// The faucet account holds funds on the parent, and the child API is used to track delivery
+// by the top-down nonce the child gateway applied.
+type CrossNetConfig struct {
+	Gateway common.Address
+	// ChildGateway is the gateway of the child subnet. The zero address means the address of Gateway.
+	ChildGateway common.Address
+	Subnet       ftypes.SubnetID
+	Child        Backend
+	PollInterval time.Duration
+	Timeout      time.Duration
+}
+
+// childGateway returns the address of the gateway of the child subnet.
+func (cn *CrossNetConfig) childGateway() common.Address {
+	if cn.ChildGateway == (common.Address{}) {
+		return cn.Gateway
+	}
+	return cn.ChildGateway
+}
+
+const gatewayABIJSON = `[
+	{"type":"function","name":"fund","stateMutability":"payable",
+	 "inputs":[
+		{"name":"subnetId","type":"tuple","components":[
+			{"name":"root","type":"uint64"},
+			{"name":"route","type":"address[]"}]},
+		{"name":"to","type":"tuple","components":[
+			{"name":"addrType","type":"uint8"},
+			{"name":"payload","type":"bytes"}]}],
+	 "outputs":[]},
+	{"type":"function","name":"appliedTopDownNonce","stateMutability":"view","inputs":[],
+	 "outputs":[{"name":"","type":"uint64"}]},
+	{"type":"event","name":"NewTopDownMessage","anonymous":false,"inputs":[
+		{"name":"subnet","type":"address","indexed":true},
+		{"name":"message","type":"tuple","indexed":false,"components":[
+			{"name":"kind","type":"uint8"},
+			{"name":"to","type":"tuple","components":[
+				{"name":"subnetId","type":"tuple","components":[
+					{"name":"root","type":"uint64"},
+					{"name":"route","type":"address[]"}]},
+				{"name":"rawAddress","type":"tuple","components":[
+					{"name":"addrType","type":"uint8"},
+					{"name":"payload","type":"bytes"}]}]},
+			{"name":"from","type":"tuple","components":[
+				{"name":"subnetId","type":"tuple","components":[
+					{"name":"root","type":"uint64"},
+					{"name":"route","type":"address[]"}]},
+				{"name":"rawAddress","type":"tuple","components":[
+					{"name":"addrType","type":"uint8"},
+					{"name":"payload","type":"bytes"}]}]},
+			{"name":"nonce","type":"uint64"},
+			{"name":"value","type":"uint256"},
+			{"name":"message","type":"bytes"}]}]}
+]`
+
+var gatewayABI = mustParseABI(gatewayABIJSON)
+
+// The IPC FvmAddress of an Ethereum address is a delegated address in the EAM namespace.
+const (
+	fvmAddrTypeDelegated = 4
+	eamActorID           = 10
+)
+
+var delegatedAddressArgs = abi.Arguments{{Type: mustNewTupleType([]abi.ArgumentMarshaling{
+	{Name: "namespace", Type: "uint64"},
+	{Name: "length", Type: "uint128"},
+	{Name: "buffer", Type: "bytes"},
+})}}
+
+type gatewaySubnetID struct {
+	Root  uint64
+	Route []common.Address
+}
+
+type gatewayFvmAddress struct {
+	AddrType uint8
+	Payload  []byte
+}
+
+// FvmAddressPayload returns the payload of the IPC FvmAddress of the Ethereum address.
+func FvmAddressPayload(addr common.Address) ([]byte, error) {
+	return delegatedAddressArgs.Pack(struct {
+		Namespace uint64
+		Length    *big.Int
+		Buffer    []byte
+	}{
+		Namespace: eamActorID,
+		Length:    big.NewInt(common.AddressLength),
+		Buffer:    addr.Bytes(),
+	})
+}
+
+func (s *Service) fundCrossNet(ctx context.Context, to common.Address, amount uint64) (common.Hash, error) {
+	cn := s.cfg.CrossNet
+
+	payload, err := FvmAddressPayload(to)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to encode recipient: %w", err)
+	}
+
+	input, err := gatewayABI.Pack("fund",
+		gatewaySubnetID{Root: cn.Subnet.Root, Route: cn.Subnet.Route},
+		gatewayFvmAddress{AddrType: fvmAddrTypeDelegated, Payload: payload},
+	)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to pack gateway fund: %w", err)
+	}
+
+	value := TransferAmount(amount)
+
+	txHash, err := s.sendTx(ctx, cn.Gateway, value, input, nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	msg := data.CrossNetMessage{
+		TxHash:    txHash.Hex(),
+		Subnet:    cn.Subnet.String(),
+		Recipient: to.Hex(),
+		Amount:    amount,
+		Status:    CrossNetPending,
+		Sent:      time.Now(),
+	}
+	if err := s.db.AddCrossNetMessage(ctx, msg); err != nil {
+		return common.Hash{}, err
+	}
+
+	go s.trackCrossNet(msg)
+
+	return txHash, nil
+}
+
+// resumeCrossNet tracks the messages left pending by a previous run.
+func (s *Service) resumeCrossNet() {
+	msgs, err := s.db.GetPendingCrossNetMessages(s.ctx)
+	if err != nil {
+		s.log.Errorw("failed to get pending cross-net messages", "err", err)
+		return
+	}
+	for _, msg := range msgs {
+		s.log.Infow("resuming cross-net message tracking", "tx", msg.TxHash, "subnet", msg.Subnet, "to", msg.Recipient)
+		go s.trackCrossNet(msg)
+	}
+}
+
+// trackCrossNet follows the message until the child gateway applies it.
+// The top-down nonce of the message is read from the NewTopDownMessage event of its transaction on the parent,
+// and the message is delivered once the nonce applied by the child gateway passes it.
+// The timeout runs from the moment the message was sent, also for messages resumed after a restart.
+// The message stays pending if the service stops first.
+func (s *Service) trackCrossNet(msg data.CrossNetMessage) {
+	cn := s.cfg.CrossNet
+
+	interval := cn.PollInterval
+	if interval == 0 {
+		interval = defaultCrossNetPollInterval
+	}
+	timeout := cn.Timeout
+	if timeout == 0 {
+		timeout = defaultCrossNetTimeout
+	}
+
+	ctx, cancel := context.WithDeadline(s.ctx, msg.Sent.Add(timeout))
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if s.ctx.Err() != nil {
+				return
+			}
+			s.log.Errorw("cross-net message not delivered", "tx", msg.TxHash, "subnet", msg.Subnet, "to", msg.Recipient)
+			msg.Status = CrossNetTimeout
+			s.resolveCrossNet(msg)
+			return
+		case <-ticker.C:
+			if msg.Nonce == nil {
+				nonce, err := s.topDownNonce(ctx, common.HexToHash(msg.TxHash))
+				if errors.Is(err, ethereum.NotFound) {
+					continue
+				}
+				if errors.Is(err, errCrossNetFailed) {
+					s.log.Errorw("cross-net message failed", "tx", msg.TxHash, "subnet", msg.Subnet, "to", msg.Recipient, "err", err)
+					msg.Status = CrossNetFailed
+					msg.Error = err.Error()
+					s.resolveCrossNet(msg)
+					return
+				}
+				if err != nil {
+					s.log.Errorw("failed to get cross-net message nonce", "tx", msg.TxHash, "err", err)
+					continue
+				}
+				msg.Nonce = &nonce
+				if err := s.db.UpdateCrossNetMessage(ctx, msg); err != nil {
+					s.log.Errorw("failed to store cross-net message", "tx", msg.TxHash, "err", err)
+				}
+			}
+
+			applied, err := s.appliedTopDownNonce(ctx)
+			if err != nil {
+				s.log.Errorw("failed to get applied top-down nonce", "subnet", msg.Subnet, "err", err)
+				continue
+			}
+			if applied <= *msg.Nonce {
+				continue
+			}
+			s.log.Infow("cross-net message delivered", "tx", msg.TxHash, "subnet", msg.Subnet, "to", msg.Recipient, "nonce", *msg.Nonce)
+			now := time.Now()
+			msg.Status = CrossNetDelivered
+			msg.Delivered = &now
+			s.resolveCrossNet(msg)
+			return
+		}
+	}
+}
+
+var errCrossNetFailed = errors.New("cross-net message not sent")
+
+// topDownNonce returns the nonce of the top-down message the gateway emitted in the transaction.
+// ethereum.NotFound is returned if the transaction isn't mined yet, and errCrossNetFailed if it sent no message.
+func (s *Service) topDownNonce(ctx context.Context, txHash common.Hash) (uint64, error) {
+	receipt, err := s.client.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return 0, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return 0, fmt.Errorf("%w: transaction reverted", errCrossNetFailed)
+	}
+
+	event := gatewayABI.Events["NewTopDownMessage"]
+	for _, l := range receipt.Logs {
+		if l.Address != s.cfg.CrossNet.Gateway || len(l.Topics) == 0 || l.Topics[0] != event.ID {
+			continue
+		}
+		values, err := event.Inputs.NonIndexed().Unpack(l.Data)
+		if err != nil {
+			return 0, fmt.Errorf("%w: invalid NewTopDownMessage event: %v", errCrossNetFailed, err)
+		}
+		// The envelope is unpacked into a struct type built from the ABI.
+		return reflect.ValueOf(values[0]).FieldByName("Nonce").Uint(), nil
+	}
+	return 0, fmt.Errorf("%w: no NewTopDownMessage event", errCrossNetFailed)
+}
+
+// appliedTopDownNonce returns the nonce of the next top-down message the child gateway applies.
+func (s *Service) appliedTopDownNonce(ctx context.Context) (uint64, error) {
+	cn := s.cfg.CrossNet
+
+	input, err := gatewayABI.Pack("appliedTopDownNonce")
+	if err != nil {
+		return 0, fmt.Errorf("failed to pack appliedTopDownNonce: %w", err)
+	}
+
+	gateway := cn.childGateway()
+	out, err := cn.Child.CallContract(ctx, ethereum.CallMsg{To: &gateway, Data: input}, nil)
+	if err != nil {
+		return 0, unavailable("failed to get applied top-down nonce", err)
+	}
+
+	res, err := gatewayABI.Unpack("appliedTopDownNonce", out)
+	if err != nil {
+		return 0, fmt.Errorf("failed to unpack applied top-down nonce: %w", err)
+	}
+	return res[0].(uint64), nil
+}
+
+func (s *Service) resolveCrossNet(msg data.CrossNetMessage) {
+	if err := s.db.ResolveCrossNetMessage(context.Background(), msg); err != nil {
+		s.log.Errorw("failed to store cross-net message", "tx", msg.TxHash, "err", err)
+	}
+}
+
+// CrossNetMessage returns the delivery state of the cross-net message sent in the transaction.
+func (s *Service) CrossNetMessage(ctx context.Context, txHash common.Hash) (data.CrossNetMessage, error) {
+	msg, err := s.db.GetCrossNetMessage(ctx, txHash.Hex())
+	if err != nil {
+		return data.CrossNetMessage{}, err
+	}
+	if msg.TxHash == "" {
+		return data.CrossNetMessage{}, ErrMessageNotFound
+	}
+	return msg, nil
+}
+
+func mustNewTupleType(components []abi.ArgumentMarshaling) abi.Type {
+	t, err := abi.NewType("tuple", "", components)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
@@ -0,0 +1,234 @@
+package faucet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/contract"
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/db"
+)
+
+const (
+	DripPending   = "pending"
+	DripConfirmed = "confirmed"
+	DripFailed    = "failed"
+)
+
+const (
+	defaultDripPollInterval = 5 * time.Second
+	defaultDripTimeout      = 30 * time.Minute
+)
+
+// ContractConfig enables dispensing of the native coin through a faucet contract.
+// The contract holds the funds and enforces its own limits, and the funding accounts
+// must be its operators. They only pay for gas.
+type ContractConfig struct {
+	Address      common.Address
+	PollInterval time.Duration
+	Timeout      time.Duration
+}
+
+var faucetABI = mustParseABI(contract.FaucetMetaData.ABI)
+
+func newFaucetContract(cfg *ContractConfig, client Backend) *contract.Faucet {
+	if cfg == nil {
+		return nil
+	}
+	// The binding only fails on an invalid ABI, which is checked by faucetABI.
+	c, _ := contract.NewFaucet(cfg.Address, client)
+	return c
+}
+
+// drip calls drip on the faucet contract to send the amount to the recipient.
+// The drip is checked against the contract limits first, so rejected drips don't cost gas.
+// The account is the identity whose quota is credited back if the drip fails.
+func (s *Service) drip(ctx context.Context, account string, to common.Address, amount uint64) (common.Hash, error) {
+	cc := s.cfg.Contract
+	value := TransferAmount(amount)
+
+	ok, err := s.faucetContract.CanDrip(&bind.CallOpts{Context: ctx}, to, value)
+	if err != nil {
+		return common.Hash{}, unavailable("failed to check faucet contract limits", err)
+	}
+	if !ok {
+		balance, err := s.client.BalanceAt(ctx, cc.Address, nil)
+		if err != nil {
+			return common.Hash{}, unavailable("failed to get faucet contract balance", err)
+		}
+		if balance.Cmp(value) < 0 {
+			return common.Hash{}, fmt.Errorf("%w: faucet contract balance is %s", ErrInsufficientFunds, balance)
+		}
+		return common.Hash{}, ErrDripRejected
+	}
+
+	input, err := faucetABI.Pack("drip", to, value)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to pack drip: %w", err)
+	}
+
+	txHash, err := s.sendTx(ctx, cc.Address, new(big.Int), input, nil)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	rec := data.DripRecord{
+		TxHash:    txHash.Hex(),
+		Contract:  cc.Address.Hex(),
+		Recipient: to.Hex(),
+		Amount:    amount,
+		Status:    DripPending,
+		Sent:      time.Now(),
+		Identity:  account,
+	}
+	// The drip is sent, so it is granted whether or not it is recorded: failing here would credit
+	// the quota back for funds already dispensed. The drip is still tracked until the service stops.
+	if err := s.db.AddDripRecord(ctx, rec); err != nil {
+		s.log.Errorw("failed to record drip", "tx", rec.TxHash, "to", rec.Recipient, "err", err)
+	}
+
+	go s.trackDrip(rec)
+
+	return txHash, nil
+}
+
+// resumeDrips tracks the drips left pending by a previous run.
+func (s *Service) resumeDrips() {
+	records, err := s.db.GetPendingDripRecords(s.ctx)
+	if err != nil {
+		s.log.Errorw("failed to get pending drips", "err", err)
+		return
+	}
+	for _, rec := range records {
+		s.log.Infow("resuming drip tracking", "tx", rec.TxHash, "to", rec.Recipient)
+		go s.trackDrip(rec)
+	}
+}
+
+// trackDrip polls the receipt of the drip transaction until it is mined,
+// and confirms the drip by the Drip event the contract emitted.
+// The timeout runs from the moment the drip was sent, also for drips resumed after a restart.
+// The drip stays pending if the service stops first.
+func (s *Service) trackDrip(rec data.DripRecord) {
+	cc := s.cfg.Contract
+
+	interval := cc.PollInterval
+	if interval == 0 {
+		interval = defaultDripPollInterval
+	}
+	timeout := cc.Timeout
+	if timeout == 0 {
+		timeout = defaultDripTimeout
+	}
+
+	ctx, cancel := context.WithDeadline(s.ctx, rec.Sent.Add(timeout))
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	txHash := common.HexToHash(rec.TxHash)
+	to := common.HexToAddress(rec.Recipient)
+	value := TransferAmount(rec.Amount)
+
+	for {
+		select {
+		case <-ctx.Done():
+			if s.ctx.Err() != nil {
+				return
+			}
+			s.log.Errorw("drip not mined", "tx", rec.TxHash, "to", rec.Recipient)
+			s.failDrip(rec, errors.New("transaction not mined"))
+			return
+		case <-ticker.C:
+			receipt, err := s.client.TransactionReceipt(ctx, txHash)
+			if errors.Is(err, ethereum.NotFound) {
+				continue
+			}
+			if err != nil {
+				s.log.Errorw("failed to get drip receipt", "tx", rec.TxHash, "err", err)
+				continue
+			}
+
+			rec.Block = receipt.BlockNumber.Uint64()
+			if err := s.dripEvent(receipt, to, value); err != nil {
+				s.log.Errorw("drip failed", "tx", rec.TxHash, "to", rec.Recipient, "err", err)
+				s.failDrip(rec, err)
+				return
+			}
+
+			s.log.Infow("drip confirmed", "tx", rec.TxHash, "to", rec.Recipient, "block", rec.Block)
+			now := time.Now()
+			rec.Status = DripConfirmed
+			rec.Confirmed = &now
+			if err := s.db.ResolveDripRecord(context.Background(), rec); err != nil {
+				s.log.Errorw("failed to store drip", "tx", rec.TxHash, "err", err)
+			}
+			return
+		}
+	}
+}
+
+// failDrip stores the failure of the drip, and credits its amount back to the quota it was granted from,
+// like an expired voucher. The credit is stored with the resolution, so that a drip is never credited twice.
+func (s *Service) failDrip(rec data.DripRecord, cause error) {
+	ctx := context.Background()
+	rec.Status = DripFailed
+	rec.Error = cause.Error()
+
+	err := s.updateQuotas(ctx, func(tx *db.Database) error {
+		if rec.Identity != "" {
+			a, err := s.asset(NativeAsset)
+			if err != nil {
+				return err
+			}
+			if rec.Credited, err = s.creditQuota(ctx, tx, a, rec.Identity, rec.Amount, rec.Sent); err != nil {
+				return err
+			}
+		}
+		return tx.ResolveDripRecord(ctx, rec)
+	})
+	if err != nil {
+		s.log.Errorw("failed to store drip", "tx", rec.TxHash, "err", err)
+	}
+}
+
+// dripEvent returns an error if the receipt holds no Drip event of the contract for the recipient and value.
+func (s *Service) dripEvent(receipt *types.Receipt, to common.Address, value *big.Int) error {
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return errors.New("transaction reverted")
+	}
+	for _, l := range receipt.Logs {
+		if l.Address != s.cfg.Contract.Address {
+			continue
+		}
+		event, err := s.faucetContract.ParseDrip(*l)
+		if err != nil {
+			continue
+		}
+		if event.Recipient == to && event.Amount.Cmp(value) == 0 {
+			return nil
+		}
+	}
+	return errors.New("no Drip event for the recipient")
+}
+
+// DripRecord returns the confirmation state of the drip sent in the transaction.
+func (s *Service) DripRecord(ctx context.Context, txHash common.Hash) (data.DripRecord, error) {
+	rec, err := s.db.GetDripRecord(ctx, txHash.Hex())
+	if err != nil {
+		return data.DripRecord{}, err
+	}
+	if rec.TxHash == "" {
+		return data.DripRecord{}, ErrDripNotFound
+	}
+	return rec, nil
+}
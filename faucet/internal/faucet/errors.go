@@ -0,0 +1,101 @@
+package faucet
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrExceedTotalAllowedFunds = fmt.Errorf("transaction exceeds total allowed funds per day")
+	ErrExceedAddrAllowedFunds  = fmt.Errorf("transaction to exceeds daily allowed funds per address")
+	ErrInvalidAddress          = fmt.Errorf("invalid address")
+	ErrChainUnavailable        = fmt.Errorf("chain is unavailable")
+	ErrDenied                  = fmt.Errorf("request is denied")
+	ErrUnknownAsset            = fmt.Errorf("unknown asset")
+	ErrUnknownBundle           = fmt.Errorf("unknown bundle")
+	ErrVoucherBundle           = fmt.Errorf("bundles can't grant the native coin in the voucher mode")
+	ErrMessageNotFound         = fmt.Errorf("cross-net message not found")
+	ErrSubnetNotServed         = fmt.Errorf("subnet is not served")
+	ErrUnsupportedAddress      = fmt.Errorf("address type is not supported")
+	ErrActorNotFound           = fmt.Errorf("actor not found")
+	ErrInsufficientFunds       = fmt.Errorf("no funding account holds enough funds")
+	ErrAccountExists           = fmt.Errorf("account is already in the pool")
+	ErrAccountNotFound         = fmt.Errorf("account is not in the pool")
+	ErrLastAccount             = fmt.Errorf("the last account of the pool can't be removed")
+	ErrDripRejected            = fmt.Errorf("drip exceeds the faucet contract limits")
+	ErrDripNotFound            = fmt.Errorf("drip not found")
+	ErrVoucherNotFound         = fmt.Errorf("voucher not found")
+	ErrSignerUnavailable       = fmt.Errorf("signer is unavailable")
+	ErrNoLocalKey              = fmt.Errorf("the account has no local key")
+	ErrNonceRejected           = fmt.Errorf("transaction nonce is rejected")
+	ErrInvalidAccountKey       = fmt.Errorf("invalid account key")
+)
+
+// LimitError is returned when a request exceeds a funding quota.
+// It wraps ErrExceedAddrAllowedFunds or ErrExceedTotalAllowedFunds.
+type LimitError struct {
+	Err       error
+	Limit     uint64
+	Remaining uint64
+	Reset     time.Time
+}
+
+func (e *LimitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LimitError) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter returns the time left until the quota is reset.
+func (e *LimitError) RetryAfter(now time.Time) time.Duration {
+	if d := e.Reset.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+func newLimitError(err error, limit, used uint64, reset time.Time) *LimitError {
+	return &LimitError{
+		Err:       err,
+		Limit:     limit,
+		Remaining: remaining(limit, used),
+		Reset:     reset,
+	}
+}
+
+func unavailable(msg string, err error) error {
+	return fmt.Errorf("%w: %s: %w", ErrChainUnavailable, msg, err)
+}
+
+// Messages of the nodes rejecting a transaction for good. Geth, the simulated backend and the Lotus Eth API
+// report them as plain JSON-RPC errors.
+var (
+	insufficientFundsMessages = []string{"insufficient funds", "not enough funds"}
+	nonceMessages             = []string{"nonce too low", "nonce too high", "invalid transaction nonce", "replacement transaction underpriced"}
+)
+
+// sendError classifies an error of SendTransaction. A transaction rejected for its nonce or the funds of its
+// sender fails the same way when it is sent again, so it is not reported as the chain being unavailable.
+func sendError(err error) error {
+	msg := strings.ToLower(err.Error())
+	contains := func(messages []string) bool {
+		for _, m := range messages {
+			if strings.Contains(msg, m) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case contains(insufficientFundsMessages):
+		return fmt.Errorf("%w: %w", ErrInsufficientFunds, err)
+	case contains(nonceMessages):
+		return fmt.Errorf("%w: %w", ErrNonceRejected, err)
+	default:
+		return unavailable("failed to send tx", err)
+	}
+}
@@ -0,0 +1,25 @@
+package faucet
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendError(t *testing.T) {
+	for msg, expected := range map[string]error{
+		"insufficient funds for gas * price + value: balance 0, tx cost 1": ErrInsufficientFunds,
+		"not enough funds including pending messages":                      ErrInsufficientFunds,
+		"nonce too low: next nonce 5, tx nonce 4":                          ErrNonceRejected,
+		"nonce too high": ErrNonceRejected,
+		"invalid transaction nonce: got 1, want 2": ErrNonceRejected,
+		"replacement transaction underpriced":      ErrNonceRejected,
+		"connection refused":                       ErrChainUnavailable,
+	} {
+		err := errors.New(msg)
+		classified := sendError(err)
+		require.ErrorIs(t, classified, expected, msg)
+		require.ErrorIs(t, classified, err, msg)
+	}
+}
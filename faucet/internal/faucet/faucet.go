@@ -0,0 +1,639 @@
+package faucet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-datastore"
+	logging "github.com/ipfs/go-log/v2"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/contract"
+	"github.com/consensus-shipyard/calibration/faucet/internal/db"
+	ftypes "github.com/consensus-shipyard/calibration/faucet/internal/types"
+)
+
+type Config struct {
+	AllowedOrigins       []string
+	TotalTransferLimit   uint64
+	AddressTransferLimit uint64
+	TransferAmount       uint64
+	BackendAddress       string
+	// TrustedProxies are the networks of the reverse proxies in front of the faucet. The IP address of a requester
+	// is read from the ForwardedHeader of the requests they forward, X-Forwarded-For by default.
+	TrustedProxies  []*net.IPNet
+	ForwardedHeader string
+	// AdminToken is the bearer token of the admin API. The admin API is disabled without a token.
+	AdminToken string
+	// AdminPrivateKeys lets the admin API take private keys in request bodies. Otherwise the accounts it adds
+	// are referenced by a key file on the faucet host or an account of an external signer.
+	AdminPrivateKeys bool
+	// Accounts is the pool of accounts funds are sent from. Accounts and Signers must not be both empty.
+	Accounts []*data.EthereumAccount
+	// Signers are the accounts of the pool whose keys are held by external signers. They follow Accounts.
+	Signers []Signer
+	ChainID *big.Int
+	// Subnet is the IPC subnet of the chain. The zero value is the root network of ChainID.
+	Subnet   ftypes.SubnetID
+	Window   Window
+	Denylist []address.Address
+	Tokens   []TokenConfig
+	Bundles  []BundleConfig
+	CrossNet *CrossNetConfig
+	// Filecoin is used to fund f1 and f3 addresses with native messages.
+	Filecoin FilecoinBackend
+	// RequireChecksum rejects 0x addresses without an EIP-55 checksum.
+	RequireChecksum bool
+	// PoolRefreshInterval is the period of the refreshes of the nonces and balances of the pool accounts.
+	// It defaults to 5 seconds.
+	PoolRefreshInterval time.Duration
+	// Refill tops up the funding accounts from a treasury account.
+	Refill *RefillConfig
+	// Contract dispenses the native coin through a faucet contract instead of transfers from the funding accounts.
+	Contract *ContractConfig
+	// Vouchers grants the native coin with signed vouchers redeemed by the recipients.
+	Vouchers *VoucherConfig
+}
+
+type Service struct {
+	// ctx is the lifetime of the service: its background work stops when it is done.
+	ctx    context.Context
+	log    *logging.ZapEventLogger
+	client Backend
+	db     *db.Database
+	cfg    *Config
+	assets map[string]*asset
+	pool   *accountPool
+	refill *refiller
+	fil    *filSender
+
+	// quotaMu serializes the updates of the quotas.
+	quotaMu sync.Mutex
+	// voucherMu serializes the issuance of vouchers.
+	voucherMu sync.Mutex
+
+	faucetContract *contract.Faucet
+	claimContract  *contract.Claim
+}
+
+// NewService returns the faucet service of the chain. Its background work runs until the context is done,
+// and work interrupted then, like the tracking of pending drips and rotations, resumes with the next service.
+func NewService(ctx context.Context, log *logging.ZapEventLogger, client Backend, store datastore.Datastore, cfg *Config) *Service {
+	s := &Service{
+		ctx:    ctx,
+		cfg:    cfg,
+		log:    log,
+		client: client,
+		db:     db.NewDatabase(store),
+		assets: newAssets(cfg),
+		pool:   newAccountPool(client, cfg.Accounts, cfg.Signers),
+		refill: newRefiller(cfg.Refill, cfg.Window.Location),
+		fil:    newFilSender(cfg),
+
+		faucetContract: newFaucetContract(cfg.Contract, client),
+		claimContract:  newClaimContract(cfg.Vouchers, client),
+	}
+	go s.runPool()
+	if s.refill != nil {
+		go s.runRefill()
+	}
+	if s.claimContract != nil {
+		go s.runVouchers()
+	}
+	// The work left by a previous run is picked up before any request, which would start work of its own.
+	if cfg.CrossNet != nil {
+		s.resumeCrossNet()
+	}
+	if cfg.Contract != nil {
+		s.resumeDrips()
+	}
+	s.resumeRotations()
+	return s
+}
+
+// FundAddress transfers the configured amount of the asset to the target address.
+// An empty asset name means the native coin. In the voucher mode the native coin is
+// granted with a voucher the recipient redeems instead. The decision is recorded in the ledger.
+func (s *Service) FundAddress(ctx context.Context, assetName string, targetAddr common.Address) (data.FundResponse, error) {
+	entry := s.ethLedgerEntry(ctx, targetAddr.Hex(), targetAddr)
+	resp, err := s.fundAddress(ctx, entry, assetName, targetAddr)
+	s.recordFund(entry, err)
+	return resp, err
+}
+
+// fundAddress funds the target address, and fills the entry with the identity, the asset and the amount.
+func (s *Service) fundAddress(ctx context.Context, entry *data.LedgerEntry, assetName string, targetAddr common.Address) (data.FundResponse, error) {
+	if targetAddr == (common.Address{}) {
+		return data.FundResponse{}, ErrInvalidAddress
+	}
+
+	identity, err := s.ethIdentity(ctx, targetAddr)
+	if err != nil {
+		return data.FundResponse{}, err
+	}
+	entry.Identity = identity
+
+	if err := s.checkDenied(ctx, identity); err != nil {
+		return data.FundResponse{}, err
+	}
+
+	a, err := s.asset(assetName)
+	if err != nil {
+		return data.FundResponse{}, err
+	}
+	entry.Asset = a.name
+	entry.Amount = a.amount
+
+	if a.token == nil && s.cfg.Vouchers != nil {
+		return s.fundVoucher(ctx, entry, a, identity, targetAddr)
+	}
+
+	return s.fund(ctx, entry, a, identity, func() (string, error) {
+		txHash, err := s.transfer(ctx, a, identity, targetAddr, a.amount)
+		return txHash.Hex(), err
+	})
+}
+
+// fund sends the asset with the send function if the quota of the account allows it.
+// The account is the identity of the recipient and it keys the quota.
+// The amount is reserved in the quotas before it is sent, and credited back if the send fails.
+// The grant is then stored in the quota together with its ledger entry.
+func (s *Service) fund(ctx context.Context, entry *data.LedgerEntry, a *asset, account string, send func() (string, error)) (data.FundResponse, error) {
+	now := time.Now()
+
+	err := s.updateQuotas(ctx, func(tx *db.Database) error {
+		return s.adjustQuota(ctx, tx, a, account, now, func(q *quota) error {
+			if err := s.checkQuota(a, *q, a.amount); err != nil {
+				return err
+			}
+			q.addr.Amount += a.amount
+			q.total.Amount += a.amount
+			return nil
+		})
+	})
+	if err != nil {
+		return data.FundResponse{}, err
+	}
+
+	s.log.Infof("funding %v with %s is allowed", account, a.name)
+
+	txHash, err := send()
+	if err != nil {
+		s.releaseQuota(ctx, a, account, a.amount, now)
+		return data.FundResponse{}, fmt.Errorf("fail to send tx: %w", err)
+	}
+	s.log.Infof("address %v funded successfully", account)
+
+	var q quota
+	decide(entry, txHash, nil)
+	err = s.updateQuotas(ctx, func(tx *db.Database) error {
+		err := s.adjustQuota(ctx, tx, a, account, now, func(stored *quota) error {
+			stored.addr.LastGrant = time.Now()
+			stored.addr.LastTxHash = txHash
+			q = *stored
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return tx.AddLedgerEntry(ctx, *entry)
+	})
+	if err != nil {
+		return data.FundResponse{}, err
+	}
+
+	return data.FundResponse{
+		Asset:     a.name,
+		TxHash:    q.addr.LastTxHash,
+		NextReset: s.cfg.Window.NextReset(q.addr.LatestTransfer),
+	}, nil
+}
+
+// AccountInfo returns the balance and the quota state of the address for the asset.
+func (s *Service) AccountInfo(ctx context.Context, assetName string, addr common.Address) (data.AccountResponse, error) {
+	a, err := s.asset(assetName)
+	if err != nil {
+		return data.AccountResponse{}, err
+	}
+
+	identity, err := s.ethIdentity(ctx, addr)
+	if err != nil {
+		return data.AccountResponse{}, err
+	}
+
+	addrInfo, err := s.db.GetAssetAccountInfo(ctx, a.key(), identity)
+	if err != nil {
+		return data.AccountResponse{}, err
+	}
+
+	totalInfo, err := s.db.GetAssetTotalInfo(ctx, a.key())
+	if err != nil {
+		return data.AccountResponse{}, err
+	}
+
+	balance, err := s.balance(ctx, a, addr)
+	if err != nil {
+		return data.AccountResponse{}, err
+	}
+
+	filecoinAddr, err := ftypes.EthAddress(addr).ToFilecoinAddress()
+	if err != nil {
+		return data.AccountResponse{}, err
+	}
+
+	now := time.Now()
+
+	if s.cfg.Window.Expired(addrInfo.LatestTransfer, now) {
+		addrInfo.Amount = 0
+		addrInfo.LatestTransfer = time.Time{}
+	}
+
+	if s.cfg.Window.Expired(totalInfo.LatestTransfer, now) {
+		totalInfo.Amount = 0
+	}
+
+	resp := data.AccountResponse{
+		Address:         addr.Hex(),
+		FilecoinAddress: filecoinAddr.String(),
+		Identity:        identity,
+		Asset:           a.name,
+		Balance:         balance.String(),
+		Received:        addrInfo.Amount,
+		Remaining:       remaining(a.addressLimit, addrInfo.Amount),
+		LastTxHash:      addrInfo.LastTxHash,
+		TotalRemaining:  remaining(a.totalLimit, totalInfo.Amount),
+	}
+
+	// A rolling window is only opened by a transfer, calendar windows are always open.
+	switch {
+	case !addrInfo.LatestTransfer.IsZero():
+		reset := s.cfg.Window.NextReset(addrInfo.LatestTransfer)
+		resp.NextReset = &reset
+	case s.cfg.Window.Mode != WindowRolling && s.cfg.Window.Mode != "":
+		reset := s.cfg.Window.NextReset(s.cfg.Window.Begin(now))
+		resp.NextReset = &reset
+	}
+
+	if !addrInfo.LastGrant.IsZero() {
+		resp.LastGrant = &addrInfo.LastGrant
+	}
+
+	return resp, nil
+}
+
+// quota is the accounting state of an address for an asset.
+type quota struct {
+	addr  data.AddrInfo
+	total data.TotalInfo
+}
+
+// loadQuota returns the quota state of the address in the window open at the given moment.
+func (s *Service) loadQuota(ctx context.Context, d *db.Database, a *asset, account string, now time.Time) (quota, error) {
+	addrInfo, err := d.GetAssetAccountInfo(ctx, a.key(), account)
+	if err != nil {
+		return quota{}, err
+	}
+	s.log.Infof("funding address info: %v", addrInfo)
+
+	totalInfo, err := d.GetAssetTotalInfo(ctx, a.key())
+	if err != nil {
+		return quota{}, err
+	}
+	s.log.Infof("total info: %v", totalInfo)
+
+	if s.cfg.Window.Expired(addrInfo.LatestTransfer, now) {
+		addrInfo.Amount = 0
+		addrInfo.LatestTransfer = s.cfg.Window.Begin(now)
+	}
+
+	if s.cfg.Window.Expired(totalInfo.LatestTransfer, now) {
+		totalInfo.Amount = 0
+		totalInfo.LatestTransfer = s.cfg.Window.Begin(now)
+	}
+
+	return quota{addr: addrInfo, total: totalInfo}, nil
+}
+
+// checkQuota returns a LimitError if granting the amount would exceed the quotas.
+func (s *Service) checkQuota(a *asset, q quota, amount uint64) error {
+	if q.total.Amount+amount > a.totalLimit {
+		return newLimitError(ErrExceedTotalAllowedFunds,
+			a.totalLimit, q.total.Amount, s.cfg.Window.NextReset(q.total.LatestTransfer))
+	}
+
+	if q.addr.Amount+amount > a.addressLimit {
+		return newLimitError(ErrExceedAddrAllowedFunds,
+			a.addressLimit, q.addr.Amount, s.cfg.Window.NextReset(q.addr.LatestTransfer))
+	}
+
+	return nil
+}
+
+// storeQuota stores the quota state of the address with the database, which is the faucet database or an update of it.
+func (s *Service) storeQuota(ctx context.Context, d *db.Database, a *asset, account string, q quota) error {
+	if err := d.UpdateAssetAccountInfo(ctx, a.key(), account, q.addr); err != nil {
+		return err
+	}
+	return d.UpdateAssetTotalInfo(ctx, a.key(), q.total)
+}
+
+// updateQuotas runs fn in a database update under the quota lock, so that the quotas it reads
+// are not changed by a concurrent request before it writes them back.
+// The lock is not held while funds are sent.
+func (s *Service) updateQuotas(ctx context.Context, fn func(tx *db.Database) error) error {
+	s.quotaMu.Lock()
+	defer s.quotaMu.Unlock()
+	return s.db.Update(ctx, fn)
+}
+
+// adjustQuota applies the update to the quota of the account stored with the database.
+// Nothing is stored if the update returns an error.
+func (s *Service) adjustQuota(ctx context.Context, d *db.Database, a *asset, account string, now time.Time, update func(q *quota) error) error {
+	q, err := s.loadQuota(ctx, d, a, account, now)
+	if err != nil {
+		return err
+	}
+	if err := update(&q); err != nil {
+		return err
+	}
+	return s.storeQuota(ctx, d, a, account, q)
+}
+
+// releaseQuota credits back the amount reserved in the quotas of the account at the given moment.
+func (s *Service) releaseQuota(ctx context.Context, a *asset, account string, amount uint64, now time.Time) {
+	err := s.updateQuotas(ctx, func(tx *db.Database) error {
+		return s.adjustQuota(ctx, tx, a, account, now, func(q *quota) error {
+			q.addr.Amount = remaining(q.addr.Amount, amount)
+			q.total.Amount = remaining(q.total.Amount, amount)
+			return nil
+		})
+	})
+	if err != nil {
+		s.log.Errorw("failed to credit back quota", "account", account, "asset", a.name, "err", err)
+	}
+}
+
+// creditQuota returns the amount granted at the given moment to the quotas of the account,
+// if their window is the one it was granted in. It reports whether a quota was credited.
+func (s *Service) creditQuota(ctx context.Context, d *db.Database, a *asset, account string, amount uint64, granted time.Time) (bool, error) {
+	q, err := s.loadQuota(ctx, d, a, account, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	var credited bool
+	if !q.addr.LatestTransfer.After(granted) {
+		q.addr.Amount = remaining(q.addr.Amount, amount)
+		credited = true
+	}
+	if !q.total.LatestTransfer.After(granted) {
+		q.total.Amount = remaining(q.total.Amount, amount)
+		credited = true
+	}
+	if !credited {
+		return false, nil
+	}
+	return true, s.storeQuota(ctx, d, a, account, q)
+}
+
+// transfer sends the amount of the asset to the address. The account is the identity of the recipient,
+// it is recorded with the transfers whose outcome is only known later.
+func (s *Service) transfer(ctx context.Context, a *asset, account string, to common.Address, amount uint64) (common.Hash, error) {
+	if a.token != nil {
+		return s.transferToken(ctx, a.token, to, amount)
+	}
+	if s.cfg.CrossNet != nil {
+		return s.fundCrossNet(ctx, to, amount)
+	}
+	return s.transferETH(ctx, account, to, amount)
+}
+
+func (s *Service) balance(ctx context.Context, a *asset, addr common.Address) (*big.Int, error) {
+	if a.token != nil {
+		return s.tokenBalance(ctx, a.token, addr)
+	}
+	balance, err := s.client.BalanceAt(ctx, addr, nil)
+	if err != nil {
+		return nil, unavailable("failed to get balance", err)
+	}
+	return balance, nil
+}
+
+func (s *Service) transferETH(ctx context.Context, account string, to common.Address, amount uint64) (common.Hash, error) {
+	if s.cfg.Contract != nil {
+		return s.drip(ctx, account, to, amount)
+	}
+	value := TransferAmount(amount)
+	return s.sendTx(ctx, to, value, nil, nil)
+}
+
+// sendTx sends the transaction from the least busy account of the pool that can pay for it.
+// need is what the transaction spends besides its value and its fees, like the tokens it transfers.
+func (s *Service) sendTx(ctx context.Context, to common.Address, value *big.Int, input []byte, need spend) (common.Hash, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond*5000*4)
+	defer cancel()
+
+	if need == nil {
+		need = make(spend)
+	}
+	need.add(native, value)
+
+	excluded := make(map[*poolAccount]bool)
+	resynced := make(map[*poolAccount]bool)
+	for {
+		acc, nonce, err := s.pool.acquire(ctx, need, excluded, s.refreshAccount)
+		if err != nil {
+			return common.Hash{}, err
+		}
+
+		rawTx, err := s.newTx(ctx, acc.Address, to, value, input)
+		if err != nil {
+			acc.release(nonce)
+			return common.Hash{}, err
+		}
+		rawTx.Nonce = nonce
+
+		// The fees are only known once the gas is estimated, the account must cover them too.
+		fees := new(big.Int).Mul(rawTx.GasFeeCap, new(big.Int).SetUint64(rawTx.Gas))
+		cost := make(spend)
+		for asset, amount := range need {
+			cost.add(asset, amount)
+		}
+		if !acc.cover(nonce, cost.add(native, fees)) {
+			acc.release(nonce)
+			excluded[acc] = true
+			continue
+		}
+
+		txHash, err := s.sendSigned(ctx, acc, rawTx)
+		if err != nil {
+			acc.abort(nonce, err)
+		}
+		switch {
+		case errors.Is(err, ErrNonceRejected) && !resynced[acc]:
+			// The transaction is sent again once with the nonce read from the chain.
+			resynced[acc] = true
+			if err := s.refreshAccount(ctx, acc); err != nil {
+				return common.Hash{}, err
+			}
+			continue
+		case errors.Is(err, ErrInsufficientFunds):
+			// The cached balance of the account was out of date, the other accounts may pay.
+			if err := s.refreshAccount(ctx, acc); err != nil {
+				return common.Hash{}, err
+			}
+			excluded[acc] = true
+			continue
+		case err != nil:
+			return common.Hash{}, err
+		}
+		s.requestRefill()
+		return txHash, nil
+	}
+}
+
+// gasFees returns the tip and the fee cap of the next transactions, and the base fee they are computed from.
+func (s *Service) gasFees(ctx context.Context) (gasTipCap, gasFeeCap, baseFee *big.Int, err error) {
+	gasTipCap, err = s.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, nil, unavailable("failed to suggest gas tip", err)
+	}
+
+	// https://github.com/ethereum/go-ethereum/issues/23125
+	block, err := s.client.BlockByNumber(ctx, nil)
+	if err != nil {
+		return nil, nil, nil, unavailable("failed to get block", err)
+	}
+	baseFee = block.BaseFee()
+	gasFeeCap = new(big.Int).SetUint64(1500000000)
+	gasFeeCap.Add(gasFeeCap, new(big.Int).Mul(baseFee, big.NewInt(2)))
+	return gasTipCap, gasFeeCap, baseFee, nil
+}
+
+// signAndSend sends the transaction from the account with the nonce.
+func (s *Service) signAndSend(ctx context.Context, acc *poolAccount, nonce uint64, to common.Address, value *big.Int, input []byte) (common.Hash, error) {
+	rawTx, err := s.newTx(ctx, acc.Address, to, value, input)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	rawTx.Nonce = nonce
+	return s.sendSigned(ctx, acc, rawTx)
+}
+
+// newTx returns the transaction from the address with its fees and gas limit. The nonce is left to the caller.
+func (s *Service) newTx(ctx context.Context, from common.Address, to common.Address, value *big.Int, input []byte) (*types.DynamicFeeTx, error) {
+	gasTipCap, gasFeeCap, baseFee, err := s.gasFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The fees are left out of the estimate, the pool checks that the account pays for them.
+	gasLimit, err := s.client.EstimateGas(ctx, ethereum.CallMsg{
+		From:  from,
+		To:    &to,
+		Value: value,
+		Data:  input,
+	})
+	if err != nil {
+		s.log.Errorw(
+			"failed to estimate gas price",
+			"to", to.String(),
+			"from", from,
+			"GasFeeCap", gasFeeCap,
+			"gasTipCap", gasTipCap,
+			"baseFee", baseFee,
+		)
+		return nil, unavailable("failed to estimate gas price", err)
+	}
+
+	gasLimit += gasLimit / 5
+
+	return &types.DynamicFeeTx{
+		ChainID:   s.cfg.ChainID,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     value,
+		Data:      input,
+	}, nil
+}
+
+// sendSigned signs the transaction with the account and sends it.
+func (s *Service) sendSigned(ctx context.Context, acc *poolAccount, rawTx *types.DynamicFeeTx) (common.Hash, error) {
+	signedTx, err := acc.SignTx(ctx, types.NewTx(rawTx), s.cfg.ChainID)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to sign tx: %w", err)
+	}
+
+	err = s.client.SendTransaction(ctx, signedTx)
+	if err != nil {
+		s.log.Errorw(
+			"failed to send tx", "hash", signedTx.Hash(),
+			"gasFeeCap", rawTx.GasFeeCap,
+			"gasLimit", rawTx.Gas,
+			"gasTipCap", rawTx.GasTipCap,
+		)
+		return common.Hash{}, sendError(err)
+	}
+
+	s.log.Infof("tx sent from %s: %s", acc.Address, signedTx.Hash().Hex())
+
+	return signedTx.Hash(), nil
+}
+
+// Subnet returns the IPC subnet where the faucet funds recipients.
+func (s *Service) Subnet() ftypes.SubnetID {
+	switch {
+	case s.cfg.CrossNet != nil:
+		return s.cfg.CrossNet.Subnet
+	case s.cfg.Subnet.Root != 0:
+		return s.cfg.Subnet
+	default:
+		return ftypes.SubnetID{Root: s.cfg.ChainID.Uint64()}
+	}
+}
+
+// NetworkPrefix returns the prefix of Filecoin addresses on the chain.
+func (s *Service) NetworkPrefix() string {
+	return ftypes.NetworkPrefix(s.cfg.ChainID.Uint64())
+}
+
+// AddressOptions returns the options recipient addresses are checked with.
+func (s *Service) AddressOptions() ftypes.AddressOptions {
+	return ftypes.AddressOptions{
+		NetworkPrefix:   s.NetworkPrefix(),
+		RequireChecksum: s.cfg.RequireChecksum,
+	}
+}
+
+// CheckSubnet returns an error if recipients on the subnet are not funded by the faucet.
+func (s *Service) CheckSubnet(subnet ftypes.SubnetID) error {
+	if served := s.Subnet(); !served.Equal(subnet) {
+		return fmt.Errorf("%w: %s, the faucet funds %s", ErrSubnetNotServed, subnet, served)
+	}
+	return nil
+}
+
+func remaining(limit, used uint64) uint64 {
+	if used >= limit {
+		return 0
+	}
+	return limit - used
+}
+
+func TransferAmount(amount uint64) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(amount), big.NewInt(params.Ether))
+}
@@ -0,0 +1,281 @@
+package faucet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/filecoin-project/go-address"
+	fbig "github.com/filecoin-project/go-state-types/big"
+	fcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/ipfs/go-cid"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/lotus"
+	ftypes "github.com/consensus-shipyard/calibration/faucet/internal/types"
+)
+
+// FundFilecoinAddress funds an f1 or f3 address.
+// If the address has an actor, its masked ID address is funded through the Ethereum API.
+// Otherwise the native coin is sent with a Filecoin message, which creates the actor.
+// The decision is recorded in the ledger.
+func (s *Service) FundFilecoinAddress(ctx context.Context, assetName string, targetAddr address.Address) (data.FundResponse, error) {
+	recipient := ftypes.FilecoinAddressString(targetAddr, s.NetworkPrefix())
+
+	ethAddr, err := s.ResolveFilecoinAddress(ctx, targetAddr)
+	switch {
+	case err == nil:
+		entry := s.ethLedgerEntry(ctx, recipient, ethAddr)
+		entry.Filecoin = recipient
+		resp, err := s.fundAddress(ctx, entry, assetName, ethAddr)
+		s.recordFund(entry, err)
+		return resp, err
+	case !errors.Is(err, ErrActorNotFound):
+		entry := ledgerEntry(ctx, recipient)
+		entry.Filecoin = recipient
+		s.record(entry, "", err)
+		return data.FundResponse{}, err
+	}
+
+	entry := ledgerEntry(ctx, recipient)
+	entry.Filecoin = recipient
+	resp, err := s.fundFilecoinAddress(ctx, entry, assetName, targetAddr)
+	s.recordFund(entry, err)
+	return resp, err
+}
+
+// fundFilecoinAddress sends the native coin to the f1 or f3 address with a Filecoin message,
+// and fills the entry with the identity, the asset and the amount.
+func (s *Service) fundFilecoinAddress(ctx context.Context, entry *data.LedgerEntry, assetName string, targetAddr address.Address) (data.FundResponse, error) {
+	identity, err := s.identity(ctx, targetAddr)
+	if err != nil {
+		return data.FundResponse{}, err
+	}
+	entry.Identity = identity
+
+	if err := s.checkDenied(ctx, identity); err != nil {
+		return data.FundResponse{}, err
+	}
+
+	a, err := s.asset(assetName)
+	if err != nil {
+		return data.FundResponse{}, err
+	}
+	entry.Asset = a.name
+	entry.Amount = a.amount
+	if a.token != nil {
+		return data.FundResponse{}, fmt.Errorf("%w: %s can't be sent to %s", ErrUnsupportedAddress, a.name, targetAddr)
+	}
+
+	return s.fund(ctx, entry, a, identity, func() (string, error) {
+		msgCid, err := s.transferFIL(ctx, targetAddr, a.amount)
+		return msgCid.String(), err
+	})
+}
+
+// ResolveFilecoinAddress returns the masked ID address of the actor at an f1 or f3 address.
+// ErrActorNotFound is returned if the address has no actor yet.
+func (s *Service) ResolveFilecoinAddress(ctx context.Context, addr address.Address) (common.Address, error) {
+	switch addr.Protocol() {
+	case address.SECP256K1, address.BLS:
+	default:
+		return common.Address{}, ErrInvalidAddress
+	}
+
+	if !s.canResolve() {
+		return common.Address{}, fmt.Errorf("%w: %s", ErrUnsupportedAddress, addr)
+	}
+
+	id, err := s.lookupID(ctx, addr)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	ethAddr, err := ftypes.EthAddressFromFilecoinAddress(id)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return common.Address(ethAddr), nil
+}
+
+// lookupID resolves the address to the ID address of its actor.
+// Resolutions are cached in the datastore since IDs never change once assigned.
+func (s *Service) lookupID(ctx context.Context, addr address.Address) (address.Address, error) {
+	id, err := s.db.GetResolvedAddress(ctx, addr)
+	if err != nil {
+		return address.Undef, err
+	}
+	if id != address.Undef {
+		return id, nil
+	}
+
+	id, err = s.cfg.Filecoin.StateLookupID(ctx, addr)
+	switch {
+	case errors.Is(err, lotus.ErrActorNotFound):
+		return address.Undef, fmt.Errorf("%w: %s", ErrActorNotFound, addr)
+	case err != nil:
+		return address.Undef, unavailable("failed to look up actor ID", err)
+	}
+
+	if err := s.db.UpdateResolvedAddress(ctx, addr, id); err != nil {
+		return address.Undef, err
+	}
+	// The reverse resolution identifies the recipient when its masked ID address is funded.
+	if err := s.db.UpdateRobustAddress(ctx, id, addr); err != nil {
+		return address.Undef, err
+	}
+	s.log.Infof("address %s resolved to %s", addr, id)
+
+	return id, nil
+}
+
+// filSender sends the Filecoin messages of the faucet from the f1 address of a local key.
+// The sends are serialized and the nonces assigned locally, so concurrent requests never reuse a nonce.
+type filSender struct {
+	account *data.EthereumAccount
+	from    address.Address
+
+	mu sync.Mutex
+	// synced is false until the next nonce is read from the message pool, and after a failed push.
+	synced bool
+	// next is the nonce of the next message.
+	next uint64
+	// reserved is the maximum cost of the pushed messages by nonce, until the actor nonce passes them.
+	reserved map[uint64]*big.Int
+}
+
+// newFilSender returns the sender of the first account with a local key,
+// or nil if Filecoin funding is disabled or there is no such account.
+func newFilSender(cfg *Config) *filSender {
+	if cfg.Filecoin == nil || len(cfg.Accounts) == 0 {
+		return nil
+	}
+	acc := cfg.Accounts[0]
+	from, err := acc.FilecoinAddress()
+	if err != nil {
+		return nil
+	}
+	return &filSender{account: acc, from: from, reserved: make(map[uint64]*big.Int)}
+}
+
+// confirm drops the reservations of the messages below the actor nonce,
+// and the local sequence if messages were sent with the key by someone else.
+// The caller holds mu.
+func (f *filSender) confirm(nonce uint64) {
+	if f.next < nonce {
+		f.synced = false
+	}
+	for n := range f.reserved {
+		if n < nonce {
+			delete(f.reserved, n)
+		}
+	}
+}
+
+// available returns the part of the balance the pushed messages don't spend.
+// The caller holds mu.
+func (f *filSender) available(balance *big.Int) *big.Int {
+	available := new(big.Int).Set(balance)
+	for _, cost := range f.reserved {
+		available.Sub(available, cost)
+	}
+	return available
+}
+
+// transferFIL sends the amount from the f1 address of the first account with a local key.
+// The message is only pushed if the balance of the address covers it and its maximum fee,
+// on top of what the pushed messages may spend.
+func (s *Service) transferFIL(ctx context.Context, to address.Address, amount uint64) (cid.Cid, error) {
+	f := s.fil
+	if f == nil {
+		return cid.Undef, fmt.Errorf("%w: Filecoin messages are signed by the first account with a private key", ErrNoLocalKey)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	balance := new(big.Int)
+	actor, err := s.cfg.Filecoin.StateGetActor(ctx, f.from)
+	switch {
+	case err == nil:
+		if actor.Balance.Int != nil {
+			balance = actor.Balance.Int
+		}
+		f.confirm(actor.Nonce)
+	case !errors.Is(err, lotus.ErrActorNotFound):
+		return cid.Undef, unavailable("failed to get the balance of "+f.from.String(), err)
+	}
+
+	if !f.synced {
+		nonce, err := s.cfg.Filecoin.MpoolGetNonce(ctx, f.from)
+		if err != nil {
+			return cid.Undef, unavailable("failed to retrieve nonce", err)
+		}
+		f.next, f.synced = nonce, true
+	}
+
+	value := TransferAmount(amount)
+	msg, err := s.cfg.Filecoin.GasEstimateMessageGas(ctx, &lotus.Message{
+		To:         to,
+		From:       f.from,
+		Nonce:      f.next,
+		Value:      fbig.NewFromGo(value),
+		GasFeeCap:  fbig.Zero(),
+		GasPremium: fbig.Zero(),
+		Method:     lotus.MethodSend,
+	})
+	if err != nil {
+		return cid.Undef, unavailable("failed to estimate gas", err)
+	}
+
+	cost := new(big.Int).Mul(msg.GasFeeCap.Int, big.NewInt(msg.GasLimit))
+	cost.Add(cost, value)
+	if available := f.available(balance); available.Cmp(cost) < 0 {
+		s.log.Errorw("Filecoin address can't cover the message", "from", f.from, "balance", balance, "available", available, "cost", cost)
+		return cid.Undef, fmt.Errorf("%w: %s holds %s, %s is needed", ErrInsufficientFunds, f.from, available, cost)
+	}
+
+	signed, err := signMessage(f.account, msg)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	msgCid, err := s.cfg.Filecoin.MpoolPush(ctx, signed)
+	if err != nil {
+		// The message pool may hold messages of the key the local sequence doesn't know of.
+		f.synced = false
+		s.log.Errorw("failed to push message", "to", to, "nonce", msg.Nonce, "gasLimit", msg.GasLimit)
+		return cid.Undef, unavailable("failed to push message", err)
+	}
+
+	f.reserved[msg.Nonce] = cost
+	f.next++
+
+	s.log.Infof("message pushed: %s", msgCid)
+
+	return msgCid, nil
+}
+
+// signMessage signs the BLAKE2b-256 digest of the message CID with the secp256k1 key of the account.
+func signMessage(acc *data.EthereumAccount, msg *lotus.Message) (*lotus.SignedMessage, error) {
+	msgCid, err := msg.Cid()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	digest := blake2b.Sum256(msgCid.Bytes())
+	sig, err := crypto.Sign(digest[:], acc.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign message: %w", err)
+	}
+
+	return &lotus.SignedMessage{
+		Message:   *msg,
+		Signature: fcrypto.Signature{Type: fcrypto.SigTypeSecp256k1, Data: sig},
+	}, nil
+}
@@ -0,0 +1,102 @@
+package faucet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/filecoin-project/go-address"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/lotus"
+	ftypes "github.com/consensus-shipyard/calibration/faucet/internal/types"
+)
+
+// identity returns the canonical identity of the recipient. It keys the quotas and is matched against the denylist,
+// so an actor reachable through several addresses gets a single allowance.
+//
+// ID addresses, including masked ID addresses, are resolved to the robust address of the actor.
+// Addresses with an Ethereum form are identified by their 0x form, f1 and f3 addresses by their string form.
+// Without a Lotus API ID addresses can't be resolved and are identified by their masked ID address.
+func (s *Service) identity(ctx context.Context, addr address.Address) (string, error) {
+	if addr.Protocol() == address.ID && s.canResolve() {
+		robust, err := s.lookupRobust(ctx, addr)
+		if err != nil {
+			return "", err
+		}
+		addr = robust
+	}
+
+	switch addr.Protocol() {
+	case address.SECP256K1, address.BLS:
+		return addr.String(), nil
+	default:
+		ethAddr, err := ftypes.EthAddressFromFilecoinAddress(addr)
+		if err != nil {
+			return "", fmt.Errorf("%w: %s", ErrInvalidAddress, addr)
+		}
+		return common.Address(ethAddr).String(), nil
+	}
+}
+
+// ethIdentity returns the canonical identity of the recipient at the Ethereum address.
+func (s *Service) ethIdentity(ctx context.Context, addr common.Address) (string, error) {
+	filecoinAddr, err := ftypes.EthAddress(addr).ToFilecoinAddress()
+	if err != nil {
+		return "", err
+	}
+	return s.identity(ctx, filecoinAddr)
+}
+
+// canResolve reports whether addresses are resolved through the Lotus API of the chain.
+func (s *Service) canResolve() bool {
+	return s.cfg.Filecoin != nil && s.cfg.CrossNet == nil
+}
+
+// lookupRobust resolves the ID address to the robust address of its actor.
+// Actors without a robust address, like singletons, keep their ID address.
+// Only definitive resolutions are cached in the datastore: a failed lookup would otherwise split the identity
+// of the actor in two for good.
+func (s *Service) lookupRobust(ctx context.Context, id address.Address) (address.Address, error) {
+	robust, err := s.db.GetRobustAddress(ctx, id)
+	if err != nil {
+		return address.Undef, err
+	}
+	if robust != address.Undef {
+		return robust, nil
+	}
+
+	robust, err = s.cfg.Filecoin.StateLookupRobustAddress(ctx, id)
+	switch {
+	case errors.Is(err, lotus.ErrActorNotFound):
+		return address.Undef, fmt.Errorf("%w: %s", ErrActorNotFound, id)
+	case errors.Is(err, lotus.ErrNoRobustAddress):
+		s.log.Infof("address %s has no robust address", id)
+		robust = id
+	case err != nil:
+		return address.Undef, unavailable("failed to look up robust address", err)
+	}
+
+	if err := s.db.UpdateRobustAddress(ctx, id, robust); err != nil {
+		return address.Undef, err
+	}
+
+	return robust, nil
+}
+
+// checkDenied returns ErrDenied if the identity is the identity of a denylisted address.
+func (s *Service) checkDenied(ctx context.Context, identity string) error {
+	for _, a := range s.cfg.Denylist {
+		denied, err := s.identity(ctx, a)
+		if errors.Is(err, ErrActorNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if denied == identity {
+			return ErrDenied
+		}
+	}
+	return nil
+}
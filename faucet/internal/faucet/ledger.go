@@ -0,0 +1,167 @@
+package faucet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"math/rand"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/db"
+	ftypes "github.com/consensus-shipyard/calibration/faucet/internal/types"
+)
+
+// Decisions recorded in the ledger.
+const (
+	LedgerGranted  = "granted"
+	LedgerRejected = "rejected"
+)
+
+const (
+	defaultLedgerLimit = 100
+	maxLedgerLimit     = 1000
+	// ledgerGasTimeout bounds the lookups of the gas paid for the transaction of a ledger entry.
+	ledgerGasTimeout = time.Hour
+)
+
+type requesterKey struct{}
+
+// WithRequester returns a context carrying the IP address of the requester, which is recorded in the ledger.
+func WithRequester(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, requesterKey{}, ip)
+}
+
+func requester(ctx context.Context) string {
+	ip, _ := ctx.Value(requesterKey{}).(string)
+	return ip
+}
+
+// ledgerEntry returns a new entry for a request by the requester of the context.
+func ledgerEntry(ctx context.Context, recipient string) *data.LedgerEntry {
+	now := time.Now()
+	return &data.LedgerEntry{
+		ID:        db.LedgerID(now, rand.Uint32()), // nolint
+		Time:      now,
+		IP:        requester(ctx),
+		Recipient: recipient,
+	}
+}
+
+// ethLedgerEntry returns a new entry for a request funding the Ethereum address.
+func (s *Service) ethLedgerEntry(ctx context.Context, recipient string, addr common.Address) *data.LedgerEntry {
+	entry := ledgerEntry(ctx, recipient)
+	entry.Ethereum = addr.Hex()
+	if filecoinAddr, err := ftypes.EthAddress(addr).ToFilecoinAddress(); err == nil {
+		entry.Filecoin = ftypes.FilecoinAddressString(filecoinAddr, s.NetworkPrefix())
+	}
+	return entry
+}
+
+// decide sets the decision on the request in the entry: granted with the transaction hash, or rejected for the error.
+func decide(entry *data.LedgerEntry, txHash string, err error) {
+	entry.Decision = LedgerGranted
+	entry.TxHash = txHash
+	if err != nil {
+		entry.Decision = LedgerRejected
+		entry.Reason = err.Error()
+	}
+}
+
+// record appends the decision on the request to the ledger.
+// The decision is already made, so a failure to record it is logged rather than returned.
+func (s *Service) record(entry *data.LedgerEntry, txHash string, err error) {
+	decide(entry, txHash, err)
+	if err := s.db.AddLedgerEntry(context.Background(), *entry); err != nil {
+		s.log.Errorw("failed to record ledger entry", "recipient", entry.Recipient, "decision", entry.Decision, "err", err)
+	}
+}
+
+// recordFund appends the rejection of a funding request to the ledger.
+// Grants are decided and recorded by fund together with their quota, even if storing them fails.
+func (s *Service) recordFund(entry *data.LedgerEntry, err error) {
+	if entry.Decision != "" {
+		return
+	}
+	s.record(entry, "", err)
+}
+
+// Ledger returns a page of the ledger entries selected by the filter, newest first.
+// The gas paid for the transactions of the entries is recorded by recordLedgerGas once they are mined,
+// so the ledger is served without reading the chain.
+func (s *Service) Ledger(ctx context.Context, filter data.LedgerFilter) (data.LedgerPage, error) {
+	switch {
+	case filter.Limit <= 0:
+		filter.Limit = defaultLedgerLimit
+	case filter.Limit > maxLedgerLimit:
+		filter.Limit = maxLedgerLimit
+	}
+
+	entries, next, err := s.db.GetLedgerEntries(ctx, filter)
+	if err != nil {
+		return data.LedgerPage{}, err
+	}
+	return data.LedgerPage{Entries: entries, Next: next}, nil
+}
+
+// recordLedgerGas records the gas paid for the transactions of the ledger entries mined since it last ran.
+// It runs with the refreshes of the pool. The lookups of a transaction stop once its gas is recorded,
+// or once it is older than ledgerGasTimeout, since it was dropped then.
+func (s *Service) recordLedgerGas(ctx context.Context) {
+	entries, err := s.db.GetUnminedLedgerEntries(ctx)
+	if err != nil {
+		s.log.Errorw("failed to get unmined ledger entries", "err", err)
+		return
+	}
+	for _, entry := range entries {
+		if err := s.ledgerGas(ctx, entry); err != nil {
+			s.log.Errorw("failed to record ledger gas", "tx", entry.TxHash, "err", err)
+		}
+	}
+}
+
+// ledgerGas looks up the cost of the transaction of the entry, and records it once the transaction is mined.
+func (s *Service) ledgerGas(ctx context.Context, entry data.LedgerEntry) error {
+	// Native Filecoin messages are identified by CIDs, and vouchers have no transaction.
+	b, err := hexutil.Decode(entry.TxHash)
+	if err != nil || len(b) != common.HashLength {
+		return s.db.DropUnminedLedgerEntry(ctx, entry.ID)
+	}
+
+	receipt, err := s.client.TransactionReceipt(ctx, common.BytesToHash(b))
+	if errors.Is(err, ethereum.NotFound) {
+		if time.Since(entry.Time) > ledgerGasTimeout {
+			s.log.Warnw("ledger transaction not mined, its gas is not recorded", "tx", entry.TxHash)
+			return s.db.DropUnminedLedgerEntry(ctx, entry.ID)
+		}
+		return nil
+	}
+	if err != nil {
+		return unavailable("failed to get receipt", err)
+	}
+
+	header, err := s.client.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return unavailable("failed to get block", err)
+	}
+
+	gasPaid := new(big.Int).SetUint64(receipt.GasUsed)
+	if receipt.EffectiveGasPrice != nil {
+		gasPaid.Mul(gasPaid, receipt.EffectiveGasPrice)
+	}
+	gas := data.LedgerGas{
+		GasPaid: gasPaid.String(),
+		Block:   receipt.BlockNumber.Uint64(),
+		Mined:   time.Unix(int64(header.Time), 0).UTC(),
+	}
+
+	if err := s.db.AddLedgerGas(ctx, entry.ID, gas); err != nil {
+		return fmt.Errorf("failed to record ledger gas: %w", err)
+	}
+	return nil
+}
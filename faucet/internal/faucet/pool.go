@@ -0,0 +1,535 @@
+package faucet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+)
+
+// defaultPoolRefreshInterval is the default period of the refreshes of the nonces and balances of the pool accounts.
+const defaultPoolRefreshInterval = 5 * time.Second
+
+// spend is an amount of funds per asset: the native coin under the zero address, and tokens under their contract address.
+type spend map[common.Address]*big.Int
+
+// native is the key of the native coin in a spend.
+var native = common.Address{}
+
+// add adds the amount of the asset to the spend.
+func (s spend) add(asset common.Address, amount *big.Int) spend {
+	if s[asset] == nil {
+		s[asset] = new(big.Int)
+	}
+	s[asset] = new(big.Int).Add(s[asset], amount)
+	return s
+}
+
+// poolAccount is a funding account of the pool with its own nonce sequence.
+// Nonces are assigned locally so that several transactions of the account can be pending at once.
+type poolAccount struct {
+	Signer
+	Address common.Address
+
+	mu sync.Mutex
+	// synced is false until the next nonce is read from the chain, and after a failed send.
+	synced bool
+	// next is the nonce of the next transaction sent by the account.
+	next uint64
+	// confirmed is the nonce of the account in the latest block.
+	confirmed uint64
+	// balances are the balances of the account in the latest block, nil until they are first read.
+	balances spend
+	// reserved is what the pending transactions of the account may spend, by nonce.
+	reserved map[uint64]spend
+}
+
+func newPoolAccount(signer Signer) *poolAccount {
+	return &poolAccount{Signer: signer, Address: signer.Address(), reserved: make(map[uint64]spend)}
+}
+
+// newDrainingAccount returns an account whose key is unknown. Its transactions can be waited for, but it can't send.
+func newDrainingAccount(addr common.Address) *poolAccount {
+	return &poolAccount{Address: addr, reserved: make(map[uint64]spend)}
+}
+
+// pending returns the number of transactions of the account that are not included in a block yet.
+func (a *poolAccount) pending() uint64 {
+	if !a.synced || a.next < a.confirmed {
+		return 0
+	}
+	return a.next - a.confirmed
+}
+
+// refresh updates the confirmed nonce of the account.
+func (a *poolAccount) refresh(ctx context.Context, client Backend) error {
+	confirmed, err := client.NonceAt(ctx, a.Address, nil)
+	if err != nil {
+		return unavailable("failed to retrieve nonce", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.confirm(confirmed)
+	return nil
+}
+
+// confirm sets the confirmed nonce of the account, and drops the reservations of the confirmed transactions.
+// The caller holds mu.
+func (a *poolAccount) confirm(confirmed uint64) {
+	a.confirmed = confirmed
+	// Transactions sent with the key by someone else invalidate the local sequence.
+	if a.next < confirmed {
+		a.synced = false
+	}
+	for nonce := range a.reserved {
+		if nonce < confirmed {
+			delete(a.reserved, nonce)
+		}
+	}
+}
+
+// available returns the balance of the asset the pending transactions of the account don't spend.
+// The caller holds mu.
+func (a *poolAccount) available(asset common.Address) *big.Int {
+	available := new(big.Int)
+	if balance := a.balances[asset]; balance != nil {
+		available.Set(balance)
+	}
+	for _, r := range a.reserved {
+		if amount := r[asset]; amount != nil {
+			available.Sub(available, amount)
+		}
+	}
+	return available
+}
+
+// covers reports whether the account can spend the amounts on top of its pending transactions.
+// The caller holds mu.
+func (a *poolAccount) covers(need spend) bool {
+	for asset, amount := range need {
+		if a.available(asset).Cmp(amount) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// cover replaces the reservation of the transaction with the nonce by what it spends, if the account can cover it.
+func (a *poolAccount) cover(nonce uint64, need spend) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	prev := a.reserved[nonce]
+	delete(a.reserved, nonce)
+	if !a.covers(need) {
+		a.reserved[nonce] = prev
+		return false
+	}
+	a.reserved[nonce] = need
+	return true
+}
+
+// reserve returns the nonce of the next transaction of the account.
+func (a *poolAccount) reserve(ctx context.Context, client Backend) (uint64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.synced {
+		nonce, err := client.PendingNonceAt(ctx, a.Address)
+		if err != nil {
+			return 0, unavailable("failed to retrieve nonce", err)
+		}
+		a.next, a.synced = nonce, true
+	}
+
+	nonce := a.next
+	a.next++
+	return nonce, nil
+}
+
+// sync reads the next nonce of the account from the chain if its local sequence is not synced.
+// The node is called without holding mu, so that a slow call doesn't block the selection of the other accounts.
+func (a *poolAccount) sync(ctx context.Context, client Backend) error {
+	a.mu.Lock()
+	synced := a.synced
+	a.mu.Unlock()
+	if synced {
+		return nil
+	}
+
+	nonce, err := client.PendingNonceAt(ctx, a.Address)
+	if err != nil {
+		return unavailable("failed to retrieve nonce", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.synced {
+		a.next, a.synced = nonce, true
+	}
+	return nil
+}
+
+// take reserves the next nonce of the account for a transaction that spends the amounts.
+// It returns false if the sequence of the account is not synced.
+func (a *poolAccount) take(need spend) (uint64, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.synced {
+		return 0, false
+	}
+	nonce := a.next
+	a.next++
+	a.reserved[nonce] = need
+	return nonce, true
+}
+
+// release returns the nonce of a transaction that was not sent.
+// The sequence is read from the chain again since later nonces may have been reserved meanwhile.
+func (a *poolAccount) release(nonce uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	delete(a.reserved, nonce)
+
+	if a.synced && a.next == nonce+1 {
+		a.next = nonce
+		return
+	}
+	a.synced = false
+}
+
+// resync drops the local nonce sequence of the account, so that the next nonce is read from the chain again.
+func (a *poolAccount) resync() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.synced = false
+}
+
+// abort returns the nonce of a transaction that failed to be sent. The sequence is read from the chain again
+// if the node rejected the nonce, since another transaction of the account took it.
+func (a *poolAccount) abort(nonce uint64, err error) {
+	a.release(nonce)
+	if errors.Is(err, ErrNonceRejected) {
+		a.resync()
+	}
+}
+
+// accountPool is the set of accounts the faucet sends transactions from.
+// Accounts can be added and removed while the faucet is running.
+type accountPool struct {
+	client Backend
+
+	mu       sync.RWMutex
+	accounts []*poolAccount
+
+	// selectMu makes the selection of an account and the reservation of its nonce atomic,
+	// so that concurrent requests are spread over the pool.
+	selectMu sync.Mutex
+}
+
+// newAccountPool returns a pool of the accounts with local keys followed by the accounts of the signers.
+// Duplicate accounts are added once.
+func newAccountPool(client Backend, accounts []*data.EthereumAccount, signers []Signer) *accountPool {
+	p := &accountPool{client: client}
+	for _, acc := range accounts {
+		_ = p.add(NewLocalSigner(acc))
+	}
+	for _, signer := range signers {
+		_ = p.add(signer)
+	}
+	return p
+}
+
+// refreshFunc reads the nonce and the balances of the account from the chain.
+type refreshFunc func(ctx context.Context, acc *poolAccount) error
+
+// acquire returns the account with the fewest pending transactions among the accounts that can spend the amounts,
+// and the nonce reserved for the transaction. The nonce must be released if the transaction is not sent.
+//
+// Accounts are selected from their cached nonces and balances, less what their pending transactions may spend.
+// They are only read from the chain when the account is first used, or when no account holds enough funds.
+// The chain is read outside of selectMu, so that a slow node doesn't hold up the sends of the other requests.
+// Excluded accounts are not selected.
+func (p *accountPool) acquire(ctx context.Context, need spend, excluded map[*poolAccount]bool, refresh refreshFunc) (*poolAccount, uint64, error) {
+	var accounts []*poolAccount
+	for _, acc := range p.list() {
+		if excluded[acc] {
+			continue
+		}
+		acc.mu.Lock()
+		known := acc.balances != nil
+		acc.mu.Unlock()
+		if !known {
+			if err := refresh(ctx, acc); err != nil {
+				return nil, 0, err
+			}
+		}
+		accounts = append(accounts, acc)
+	}
+
+	refreshed := false
+	for {
+		acc, nonce, ok := p.selectAccount(accounts, need)
+		switch {
+		case ok:
+			return acc, nonce, nil
+		case acc != nil:
+			// The nonce sequence of the selected account is read again, then the selection starts over.
+			if err := acc.sync(ctx, p.client); err != nil {
+				return nil, 0, err
+			}
+		case !refreshed:
+			// The cached balances may predate a top-up.
+			for _, a := range accounts {
+				if err := refresh(ctx, a); err != nil {
+					return nil, 0, err
+				}
+			}
+			refreshed = true
+		default:
+			return nil, 0, ErrInsufficientFunds
+		}
+	}
+}
+
+// selectAccount picks the account among the accounts still in the pool, and reserves its next nonce.
+// It returns the account and false if its nonce sequence must be synced first, and nil if no account covers the amounts.
+func (p *accountPool) selectAccount(accounts []*poolAccount, need spend) (*poolAccount, uint64, bool) {
+	p.selectMu.Lock()
+	defer p.selectMu.Unlock()
+
+	// Accounts taken out of the pool since the list was read must not be selected.
+	inPool := make(map[*poolAccount]bool)
+	for _, acc := range p.list() {
+		inPool[acc] = true
+	}
+	candidates := make([]*poolAccount, 0, len(accounts))
+	for _, acc := range accounts {
+		if inPool[acc] {
+			candidates = append(candidates, acc)
+		}
+	}
+
+	acc := p.pick(candidates, need)
+	if acc == nil {
+		return nil, 0, false
+	}
+	nonce, ok := acc.take(need)
+	return acc, nonce, ok
+}
+
+// pick returns the account with the fewest pending transactions that covers the amounts, nil if there is none.
+func (p *accountPool) pick(accounts []*poolAccount, need spend) *poolAccount {
+	pending := make(map[*poolAccount]uint64, len(accounts))
+	covers := make(map[*poolAccount]bool, len(accounts))
+	for _, acc := range accounts {
+		acc.mu.Lock()
+		pending[acc] = acc.pending()
+		covers[acc] = acc.covers(need)
+		acc.mu.Unlock()
+	}
+
+	var picked *poolAccount
+	for _, acc := range accounts {
+		if covers[acc] && (picked == nil || pending[acc] < pending[picked]) {
+			picked = acc
+		}
+	}
+	return picked
+}
+
+func (p *accountPool) list() []*poolAccount {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return append([]*poolAccount(nil), p.accounts...)
+}
+
+func (p *accountPool) add(signer Signer) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	acc := newPoolAccount(signer)
+	for _, a := range p.accounts {
+		if a.Address == acc.Address {
+			return fmt.Errorf("%w: %s", ErrAccountExists, acc.Address)
+		}
+	}
+	p.accounts = append(p.accounts, acc)
+	return nil
+}
+
+// remove removes the account from the pool. Transactions already sent by the account are not affected.
+func (p *accountPool) remove(addr common.Address) error {
+	_, err := p.take(addr)
+	return err
+}
+
+// take removes the account from the pool and returns it. No new nonce of the account is reserved once it returns.
+// The nonces reserved before are counted as pending by the account until they are released or confirmed.
+func (p *accountPool) take(addr common.Address) (*poolAccount, error) {
+	p.selectMu.Lock()
+	defer p.selectMu.Unlock()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, a := range p.accounts {
+		if a.Address != addr {
+			continue
+		}
+		if len(p.accounts) == 1 {
+			return nil, ErrLastAccount
+		}
+		p.accounts = append(p.accounts[:i:i], p.accounts[i+1:]...)
+		return a, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, addr)
+}
+
+// replace puts the account of the signer in the place of the account of the pool, and returns the replaced account.
+// No new nonce of the replaced account is reserved once it returns. The nonces reserved before are counted as pending
+// by the account until they are released or confirmed.
+func (p *accountPool) replace(old common.Address, signer Signer) (*poolAccount, error) {
+	p.selectMu.Lock()
+	defer p.selectMu.Unlock()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	acc := newPoolAccount(signer)
+	idx := -1
+	for i, a := range p.accounts {
+		if a.Address == acc.Address {
+			return nil, fmt.Errorf("%w: %s", ErrAccountExists, acc.Address)
+		}
+		if a.Address == old {
+			idx = i
+		}
+	}
+	if idx < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrAccountNotFound, old)
+	}
+
+	prev := p.accounts[idx]
+	p.accounts[idx] = acc
+	return prev, nil
+}
+
+// refreshAccount reads the nonce of the account, and its balances of the native coin and of the tokens.
+// The nonce is read first, so that the balances never miss the spending of a transaction whose reservation is dropped.
+func (s *Service) refreshAccount(ctx context.Context, acc *poolAccount) error {
+	confirmed, err := s.client.NonceAt(ctx, acc.Address, nil)
+	if err != nil {
+		return unavailable("failed to retrieve nonce", err)
+	}
+
+	balances := make(spend)
+	if balances[native], err = s.client.BalanceAt(ctx, acc.Address, nil); err != nil {
+		return unavailable("failed to get balance", err)
+	}
+	for i := range s.cfg.Tokens {
+		token := &s.cfg.Tokens[i]
+		balance, err := s.tokenBalance(ctx, token, acc.Address)
+		if err != nil {
+			// A broken token must not keep the account from sending the other assets.
+			s.log.Errorw("failed to refresh pool account token balance", "account", acc.Address, "token", token.Symbol, "err", err)
+			continue
+		}
+		balances[token.Address] = balance
+	}
+
+	acc.mu.Lock()
+	defer acc.mu.Unlock()
+	acc.confirm(confirmed)
+	// Token balances that can't be read keep their last value.
+	for asset, balance := range acc.balances {
+		if _, ok := balances[asset]; !ok {
+			balances[asset] = balance
+		}
+	}
+	acc.balances = balances
+	return nil
+}
+
+// runPool refreshes the nonces and balances of the pool accounts periodically,
+// so that requests select their account without reading the chain.
+// The gas paid for the transactions of the ledger is recorded with every refresh.
+func (s *Service) runPool() {
+	interval := s.cfg.PoolRefreshInterval
+	if interval == 0 {
+		interval = defaultPoolRefreshInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, acc := range s.pool.list() {
+			if err := s.refreshAccount(s.ctx, acc); err != nil {
+				s.log.Errorw("failed to refresh pool account", "account", acc.Address, "err", err)
+			}
+		}
+		s.recordLedgerGas(s.ctx)
+	}
+}
+
+// PoolAccounts returns the balance and the number of pending transactions of every account of the pool.
+// They are served from the state the pool refreshes periodically. Accounts are only read from the chain
+// if they were never read before.
+func (s *Service) PoolAccounts(ctx context.Context) ([]data.PoolAccount, error) {
+	accounts := s.pool.list()
+	resp := make([]data.PoolAccount, 0, len(accounts))
+
+	for _, acc := range accounts {
+		acc.mu.Lock()
+		known := acc.balances != nil
+		acc.mu.Unlock()
+		if !known {
+			if err := s.refreshAccount(ctx, acc); err != nil {
+				return nil, err
+			}
+		}
+
+		acc.mu.Lock()
+		resp = append(resp, data.PoolAccount{
+			Address: acc.Address.Hex(),
+			Balance: acc.balances[native].String(),
+			Nonce:   acc.confirmed,
+			Pending: acc.pending(),
+		})
+		acc.mu.Unlock()
+	}
+
+	return resp, nil
+}
+
+// AddAccount adds the account of the signer to the pool.
+// Pool changes made while the faucet runs are not persisted: the pool is built from the configuration at startup.
+func (s *Service) AddAccount(signer Signer) error {
+	if err := s.pool.add(signer); err != nil {
+		return err
+	}
+	s.log.Infow("account added to the pool", "account", signer.Address())
+	return nil
+}
+
+// RemoveAccount removes the account from the pool. The last account can't be removed.
+// The account is back in the pool after a restart if it is configured.
+func (s *Service) RemoveAccount(addr common.Address) error {
+	if err := s.pool.remove(addr); err != nil {
+		return err
+	}
+	s.log.Infow("account removed from the pool", "account", addr)
+	return nil
+}
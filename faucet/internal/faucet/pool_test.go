@@ -0,0 +1,90 @@
+package faucet
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+)
+
+func TestAcquireRefreshOutsideLock(t *testing.T) {
+	acc0, err := data.NewAccount("6cbed15c793ce57650b9877cf6fa156fbef513c4e6134f022a85b1ffdd59b2a1")
+	require.NoError(t, err)
+	acc1, err := data.NewAccount("395df67f0c2d2d9fe1ad08d1bc8b6627011959b79c53d7dd6a3536a33ab8a4fd")
+	require.NoError(t, err)
+
+	p := newAccountPool(nil, []*data.EthereumAccount{acc0, acc1}, nil)
+	need := spend{native: big.NewInt(1)}
+
+	// The first account is read from the chain by a slow node call.
+	blocked := make(chan struct{})
+	unblock := make(chan struct{})
+	refresh := func(ctx context.Context, acc *poolAccount) error {
+		if acc.Address == acc0.Address {
+			close(blocked)
+			<-unblock
+		}
+		acc.mu.Lock()
+		defer acc.mu.Unlock()
+		acc.balances = spend{native: big.NewInt(10)}
+		acc.synced = true
+		return nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _, err := p.acquire(context.Background(), need, map[*poolAccount]bool{p.list()[1]: true}, refresh)
+		require.NoError(t, err)
+	}()
+	<-blocked
+
+	// The pool keeps serving the other accounts meanwhile.
+	acquired := make(chan *poolAccount)
+	go func() {
+		acc, _, err := p.acquire(context.Background(), need, map[*poolAccount]bool{p.list()[0]: true}, refresh)
+		require.NoError(t, err)
+		acquired <- acc
+	}()
+	select {
+	case acc := <-acquired:
+		require.Equal(t, acc1.Address, acc.Address)
+	case <-time.After(5 * time.Second):
+		t.Fatal("acquire blocked by the refresh of another account")
+	}
+
+	close(unblock)
+	<-done
+}
+
+func TestAcquireSkipsTakenAccount(t *testing.T) {
+	acc0, err := data.NewAccount("6cbed15c793ce57650b9877cf6fa156fbef513c4e6134f022a85b1ffdd59b2a1")
+	require.NoError(t, err)
+	acc1, err := data.NewAccount("395df67f0c2d2d9fe1ad08d1bc8b6627011959b79c53d7dd6a3536a33ab8a4fd")
+	require.NoError(t, err)
+
+	p := newAccountPool(nil, []*data.EthereumAccount{acc0, acc1}, nil)
+	need := spend{native: big.NewInt(1)}
+
+	// The first account is taken out of the pool while it is read from the chain.
+	refresh := func(ctx context.Context, acc *poolAccount) error {
+		if acc.Address == acc0.Address {
+			_, err := p.take(acc0.Address)
+			require.NoError(t, err)
+		}
+		acc.mu.Lock()
+		defer acc.mu.Unlock()
+		acc.balances = spend{native: big.NewInt(10)}
+		acc.synced = true
+		return nil
+	}
+
+	acc, nonce, err := p.acquire(context.Background(), need, nil, refresh)
+	require.NoError(t, err)
+	require.Equal(t, acc1.Address, acc.Address)
+	require.Equal(t, uint64(0), nonce)
+}
@@ -0,0 +1,289 @@
+package faucet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/db"
+)
+
+const (
+	RefillSent   = "sent"
+	RefillCapped = "capped"
+	RefillFailed = "failed"
+)
+
+const (
+	defaultRefillInterval = time.Minute
+	defaultRefillTimeout  = 10 * time.Minute
+)
+
+// RefillConfig enables automatic refills of the funding accounts from a treasury account.
+// Amounts are in whole coins.
+type RefillConfig struct {
+	Treasury *data.EthereumAccount
+	// LowWater is the balance below which an account is refilled.
+	LowWater uint64
+	// Amount is sent to an account on every refill.
+	Amount uint64
+	// DailyCap is the maximum amount sent by the treasury in a day.
+	DailyCap uint64
+	// Interval is the period of balance checks. Balances are also checked after every transfer.
+	Interval time.Duration
+	// Timeout is the time after which a refill that is not mined is considered dropped, and sent again.
+	Timeout time.Duration
+}
+
+// refiller tops up the funding accounts from the treasury.
+// Its state is only accessed by the refill loop.
+type refiller struct {
+	cfg      *RefillConfig
+	treasury *poolAccount
+	window   Window
+	trigger  chan struct{}
+	// pending holds the refill transactions that are not mined yet.
+	pending map[common.Address]pendingRefill
+	// capped holds the window in which a refill of the account was last rejected by the cap.
+	capped map[common.Address]time.Time
+}
+
+func newRefiller(cfg *RefillConfig, loc *time.Location) *refiller {
+	if cfg == nil {
+		return nil
+	}
+	return &refiller{
+		cfg:      cfg,
+		treasury: newPoolAccount(NewLocalSigner(cfg.Treasury)),
+		window:   Window{Mode: WindowDaily, Location: loc},
+		trigger:  make(chan struct{}, 1),
+		pending:  make(map[common.Address]pendingRefill),
+		capped:   make(map[common.Address]time.Time),
+	}
+}
+
+// pendingRefill is a refill transaction that is not mined yet.
+type pendingRefill struct {
+	txHash common.Hash
+	nonce  uint64
+	amount uint64
+	sent   time.Time
+}
+
+// requestRefill schedules a balance check of the funding accounts.
+func (s *Service) requestRefill() {
+	if s.refill == nil {
+		return
+	}
+	select {
+	case s.refill.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// runRefill checks the balances of the funding accounts periodically and when requested.
+func (s *Service) runRefill() {
+	interval := s.refill.cfg.Interval
+	if interval == 0 {
+		interval = defaultRefillInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.refill.trigger:
+		}
+		s.refillAccounts(s.ctx)
+	}
+}
+
+// refillAccounts sends a refill to every funding account whose balance is below the low-water mark.
+// A failure to refill an account is logged, and doesn't hold up the refills of the other accounts.
+func (s *Service) refillAccounts(ctx context.Context) {
+	r := s.refill
+	for _, acc := range s.pool.list() {
+		if acc.Address == r.treasury.Address {
+			continue
+		}
+		if err := s.checkRefill(ctx, acc.Address); err != nil {
+			s.log.Errorw("failed to refill account", "account", acc.Address, "err", err)
+		}
+	}
+}
+
+// checkRefill refills the account if its balance is below the low-water mark and no refill of it is pending.
+func (s *Service) checkRefill(ctx context.Context, addr common.Address) error {
+	r := s.refill
+
+	if p, ok := r.pending[addr]; ok {
+		done, err := s.refillDone(ctx, addr, p)
+		if err != nil || !done {
+			return err
+		}
+		delete(r.pending, addr)
+	}
+
+	balance, err := s.client.BalanceAt(ctx, addr, nil)
+	if err != nil {
+		return unavailable("failed to get balance", err)
+	}
+	if balance.Cmp(TransferAmount(r.cfg.LowWater)) >= 0 {
+		return nil
+	}
+
+	return s.refillAccount(ctx, addr, balance.String())
+}
+
+// refillDone reports whether the pending refill of the account is mined or dropped.
+// A refill is dropped if another transaction of the treasury took its nonce, or if it is not mined
+// within the timeout. A dropped refill is recorded as failed, and its amount is returned to the daily cap.
+func (s *Service) refillDone(ctx context.Context, addr common.Address, p pendingRefill) (bool, error) {
+	r := s.refill
+
+	// The nonce is read before the receipt, so that a refill mined in between isn't taken for a replaced one.
+	confirmed, err := s.client.NonceAt(ctx, r.treasury.Address, nil)
+	if err != nil {
+		return false, unavailable("failed to retrieve nonce", err)
+	}
+	_, err = s.client.TransactionReceipt(ctx, p.txHash)
+	switch {
+	case err == nil:
+		return true, nil
+	case !errors.Is(err, ethereum.NotFound):
+		return false, unavailable("failed to get refill receipt", err)
+	}
+
+	timeout := r.cfg.Timeout
+	if timeout == 0 {
+		timeout = defaultRefillTimeout
+	}
+
+	var cause string
+	switch {
+	case confirmed > p.nonce:
+		cause = "refill transaction replaced by another transaction of the treasury"
+	case time.Since(p.sent) > timeout:
+		cause = "refill transaction not mined"
+		// The nonce of the dropped transaction is free again.
+		r.treasury.resync()
+	default:
+		return false, nil
+	}
+	s.log.Warnw("refill dropped", "account", addr, "tx", p.txHash, "nonce", p.nonce, "cause", cause)
+
+	record := data.RefillRecord{
+		Time:     time.Now(),
+		Treasury: r.treasury.Address.Hex(),
+		Account:  addr.Hex(),
+		Amount:   p.amount,
+		Status:   RefillFailed,
+		TxHash:   p.txHash.Hex(),
+		Error:    cause,
+	}
+	err = s.db.Update(ctx, func(tx *db.Database) error {
+		total, err := tx.GetRefillTotal(ctx)
+		if err != nil {
+			return err
+		}
+		if total.LatestTransfer.Equal(r.window.Begin(p.sent)) {
+			total.Amount = remaining(total.Amount, p.amount)
+			if err := tx.UpdateRefillTotal(ctx, total); err != nil {
+				return err
+			}
+		}
+		return tx.AddRefillRecord(ctx, record)
+	})
+	return err == nil, err
+}
+
+// refillAccount sends the refill amount to the account, within the daily cap, and records the refill.
+func (s *Service) refillAccount(ctx context.Context, addr common.Address, balance string) error {
+	r := s.refill
+	now := time.Now()
+
+	total, err := s.db.GetRefillTotal(ctx)
+	if err != nil {
+		return err
+	}
+	if r.window.Expired(total.LatestTransfer, now) {
+		total = data.TotalInfo{LatestTransfer: r.window.Begin(now)}
+	}
+
+	record := data.RefillRecord{
+		Time:     now,
+		Treasury: r.treasury.Address.Hex(),
+		Account:  addr.Hex(),
+		Balance:  balance,
+		Amount:   r.cfg.Amount,
+	}
+	if left := remaining(r.cfg.DailyCap, total.Amount); left < record.Amount {
+		record.Amount = left
+	}
+
+	if record.Amount == 0 {
+		// The cap is recorded once per account and window.
+		if r.capped[addr].Equal(total.LatestTransfer) {
+			return nil
+		}
+		r.capped[addr] = total.LatestTransfer
+		s.log.Warnw("refill cap reached", "account", addr, "cap", r.cfg.DailyCap)
+		record.Status = RefillCapped
+		record.Error = fmt.Sprintf("daily refill cap of %d reached", r.cfg.DailyCap)
+		return s.db.AddRefillRecord(ctx, record)
+	}
+
+	txHash, nonce, err := s.sendRefill(ctx, addr, record.Amount)
+	if err != nil {
+		s.log.Errorw("failed to send refill", "account", addr, "amount", record.Amount, "err", err)
+		record.Status = RefillFailed
+		record.Error = err.Error()
+		return s.db.AddRefillRecord(ctx, record)
+	}
+	s.log.Infow("refill sent", "account", addr, "amount", record.Amount, "tx", txHash)
+
+	r.pending[addr] = pendingRefill{txHash: txHash, nonce: nonce, amount: record.Amount, sent: now}
+	record.Status = RefillSent
+	record.TxHash = txHash.Hex()
+
+	total.Amount += record.Amount
+	return s.db.Update(ctx, func(tx *db.Database) error {
+		if err := tx.UpdateRefillTotal(ctx, total); err != nil {
+			return err
+		}
+		return tx.AddRefillRecord(ctx, record)
+	})
+}
+
+func (s *Service) sendRefill(ctx context.Context, to common.Address, amount uint64) (common.Hash, uint64, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond*5000*4)
+	defer cancel()
+
+	treasury := s.refill.treasury
+
+	nonce, err := treasury.reserve(ctx, s.client)
+	if err != nil {
+		return common.Hash{}, 0, err
+	}
+
+	txHash, err := s.signAndSend(ctx, treasury, nonce, to, TransferAmount(amount), nil)
+	if err != nil {
+		treasury.abort(nonce, err)
+		return common.Hash{}, 0, err
+	}
+	return txHash, nonce, nil
+}
+
+// RefillRecords returns the audit trail of the refills, oldest first.
+func (s *Service) RefillRecords(ctx context.Context) ([]data.RefillRecord, error) {
+	return s.db.GetRefillRecords(ctx)
+}
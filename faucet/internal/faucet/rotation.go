@@ -0,0 +1,235 @@
+package faucet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+)
+
+const (
+	RotationDraining  = "draining"
+	RotationCompleted = "completed"
+	RotationFailed    = "failed"
+)
+
+const (
+	// rotationInterval is the period of the checks of the pending transactions of a rotated account.
+	rotationInterval = time.Second
+	// rotationTimeout bounds the wait for the pending transactions of a rotated account.
+	rotationTimeout = time.Hour
+)
+
+// RotateAccount replaces the account of the pool with the account of the signer while the faucet is running.
+// The new account takes the place of the old one at once, so no new transaction is sent from the old account.
+// The rotation then waits for the pending transactions of the old account to be confirmed and,
+// with sweep, sends the balance left on the old account to the new one.
+// Every state of the rotation is recorded in the rotation audit trail. The returned record is the first one.
+func (s *Service) RotateAccount(ctx context.Context, old common.Address, signer Signer, sweep bool) (data.RotationRecord, error) {
+	prev, err := s.pool.replace(old, signer)
+	if err != nil {
+		return data.RotationRecord{}, err
+	}
+
+	record := data.RotationRecord{
+		Time:   time.Now(),
+		Old:    old.Hex(),
+		New:    signer.Address().Hex(),
+		Sweep:  sweep,
+		Status: RotationDraining,
+	}
+	s.log.Infow("account rotation started", "old", old, "new", signer.Address(), "sweep", sweep)
+
+	// The old account is out of the pool already, so the rotation goes on even if it can't be recorded.
+	if err := s.db.PutRotationRecord(ctx, record); err != nil {
+		s.log.Errorw("failed to record account rotation", "old", old, "err", err)
+	}
+	go s.finishRotation(prev, signer.Address(), record)
+
+	return record, nil
+}
+
+// resumeRotations finishes the rotations left draining by a previous run.
+// The old account is taken out of the pool again if it is configured, since its key must not send anymore.
+// Without its key a draining account can still be waited for, but not swept.
+func (s *Service) resumeRotations() {
+	records, err := s.db.GetRotationRecords(s.ctx)
+	if err != nil {
+		s.log.Errorw("failed to get rotation records", "err", err)
+		return
+	}
+
+	for _, record := range records {
+		if record.Status != RotationDraining {
+			continue
+		}
+		s.log.Infow("resuming account rotation", "old", record.Old, "new", record.New, "sweep", record.Sweep)
+
+		old := common.HexToAddress(record.Old)
+		acc, err := s.pool.take(old)
+		switch {
+		case errors.Is(err, ErrAccountNotFound) && !record.Sweep:
+			acc = newDrainingAccount(old)
+		case errors.Is(err, ErrAccountNotFound):
+			s.failRotation(record, fmt.Errorf("the key of %s is not configured, it can't be swept", old))
+			continue
+		case err != nil:
+			s.failRotation(record, err)
+			continue
+		}
+		go s.finishRotation(acc, common.HexToAddress(record.New), record)
+	}
+}
+
+// finishRotation waits for the pending transactions of the rotated account, sweeps it if requested,
+// and records the outcome of the rotation. The wait is bounded from the start of the rotation.
+// The rotation stays draining if the service stops first, and is resumed by the next one.
+func (s *Service) finishRotation(prev *poolAccount, to common.Address, record data.RotationRecord) {
+	ctx, cancel := context.WithDeadline(s.ctx, record.Time.Add(rotationTimeout))
+	defer cancel()
+
+	err := s.drain(ctx, prev)
+	if err == nil && record.Sweep {
+		var swept *big.Int
+		var txHash common.Hash
+		swept, txHash, err = s.sweep(ctx, prev, to)
+		if err == nil {
+			record.Swept = swept.String()
+			if txHash != (common.Hash{}) {
+				record.TxHash = txHash.Hex()
+			}
+		}
+	}
+	if s.ctx.Err() != nil {
+		return
+	}
+
+	if err != nil {
+		s.failRotation(record, err)
+		return
+	}
+
+	now := time.Now()
+	record.Completed = &now
+	s.log.Infow("account rotation completed", "old", record.Old, "new", record.New, "swept", record.Swept, "tx", record.TxHash)
+	record.Status = RotationCompleted
+	if err := s.db.PutRotationRecord(s.ctx, record); err != nil {
+		s.log.Errorw("failed to record account rotation", "old", record.Old, "err", err)
+	}
+}
+
+// failRotation records the failure of the rotation.
+func (s *Service) failRotation(record data.RotationRecord, err error) {
+	s.log.Errorw("account rotation failed", "old", record.Old, "new", record.New, "err", err)
+
+	now := time.Now()
+	record.Completed = &now
+	record.Status = RotationFailed
+	record.Error = err.Error()
+	if err := s.db.PutRotationRecord(s.ctx, record); err != nil {
+		s.log.Errorw("failed to record account rotation", "old", record.Old, "err", err)
+	}
+}
+
+// drain returns once every transaction of the account is confirmed: the nonces reserved locally,
+// and the transactions in the mempool of the node.
+func (s *Service) drain(ctx context.Context, acc *poolAccount) error {
+	ticker := time.NewTicker(rotationInterval)
+	defer ticker.Stop()
+
+	for {
+		done, err := s.drained(ctx, acc)
+		if err != nil {
+			s.log.Warnw("failed to check pending transactions", "account", acc.Address, "err", err)
+		}
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("pending transactions of %s are not confirmed: %w", acc.Address, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Service) drained(ctx context.Context, acc *poolAccount) (bool, error) {
+	if err := acc.refresh(ctx, s.client); err != nil {
+		return false, err
+	}
+	acc.mu.Lock()
+	pending, confirmed := acc.pending(), acc.confirmed
+	acc.mu.Unlock()
+	if pending > 0 {
+		return false, nil
+	}
+
+	next, err := s.client.PendingNonceAt(ctx, acc.Address)
+	if err != nil {
+		return false, unavailable("failed to retrieve nonce", err)
+	}
+	return next <= confirmed, nil
+}
+
+// sweep sends the balance of the account, less the maximum fee of the transaction, to the address.
+// Nothing is sent if the balance doesn't cover the fee.
+func (s *Service) sweep(ctx context.Context, acc *poolAccount, to common.Address) (*big.Int, common.Hash, error) {
+	balance, err := s.client.BalanceAt(ctx, acc.Address, nil)
+	if err != nil {
+		return nil, common.Hash{}, unavailable("failed to get balance", err)
+	}
+
+	gasTipCap, gasFeeCap, _, err := s.gasFees(ctx)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	gasLimit, err := s.client.EstimateGas(ctx, ethereum.CallMsg{
+		From:      acc.Address,
+		To:        &to,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		Value:     big.NewInt(1),
+	})
+	if err != nil {
+		return nil, common.Hash{}, unavailable("failed to estimate gas price", err)
+	}
+	gasLimit += gasLimit / 5
+
+	value := new(big.Int).Sub(balance, new(big.Int).Mul(gasFeeCap, new(big.Int).SetUint64(gasLimit)))
+	if value.Sign() <= 0 {
+		return new(big.Int), common.Hash{}, nil
+	}
+
+	nonce, err := acc.reserve(ctx, s.client)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	txHash, err := s.sendSigned(ctx, acc, &types.DynamicFeeTx{
+		ChainID:   s.cfg.ChainID,
+		Nonce:     nonce,
+		GasFeeCap: gasFeeCap,
+		GasTipCap: gasTipCap,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     value,
+	})
+	if err != nil {
+		acc.abort(nonce, err)
+		return nil, common.Hash{}, err
+	}
+
+	return value, txHash, nil
+}
+
+// RotationRecords returns the audit trail of the account rotations, oldest first.
+func (s *Service) RotationRecords(ctx context.Context) ([]data.RotationRecord, error) {
+	return s.db.GetRotationRecords(ctx)
+}
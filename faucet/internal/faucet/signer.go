@@ -0,0 +1,282 @@
+package faucet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	ftypes "github.com/consensus-shipyard/calibration/faucet/internal/types"
+)
+
+// Signer signs the transactions of a funding account, so that its key doesn't have to live in the faucet.
+type Signer interface {
+	// Address returns the address of the account.
+	Address() common.Address
+	// SignTx returns the transaction signed for the chain.
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// TypedDataSigner is a Signer that also signs EIP-712 typed data, as the vouchers are.
+type TypedDataSigner interface {
+	Signer
+	// SignTypedData returns the signature of the typed data, with a recovery id of 27 or 28.
+	SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error)
+}
+
+// LocalSigner signs with a key held in memory.
+type LocalSigner struct {
+	account *data.EthereumAccount
+}
+
+func NewLocalSigner(account *data.EthereumAccount) *LocalSigner {
+	return &LocalSigner{account: account}
+}
+
+func (s *LocalSigner) Address() common.Address {
+	return s.account.Address
+}
+
+func (s *LocalSigner) SignTx(_ context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.account.PrivateKey)
+}
+
+func (s *LocalSigner) SignTypedData(_ context.Context, typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+	sig, err := crypto.Sign(hash, s.account.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// Account returns the account of the key.
+func (s *LocalSigner) Account() *data.EthereumAccount {
+	return s.account
+}
+
+// JSON-RPC methods of the external signers.
+const (
+	// ClefSignMethod is the method of Clef.
+	ClefSignMethod = "account_signTransaction"
+	// EthSignMethod is the method of Web3Signer and of the nodes managing keys.
+	EthSignMethod = "eth_signTransaction"
+)
+
+// typedDataMethods are the typed data signing methods of the external signers, by transaction signing method.
+var typedDataMethods = map[string]string{
+	ClefSignMethod: "account_signTypedData",
+	EthSignMethod:  "eth_signTypedData",
+}
+
+// SignMethod returns the signing method of an external signer, ClefSignMethod if none is set.
+func SignMethod(method string) (string, error) {
+	switch method {
+	case "":
+		return ClefSignMethod, nil
+	case ClefSignMethod, EthSignMethod:
+		return method, nil
+	default:
+		return "", fmt.Errorf("unsupported signer method %q", method)
+	}
+}
+
+// RemoteSigner signs with a key held by an external signer, called over JSON-RPC.
+type RemoteSigner struct {
+	client  *rpc.Client
+	method  string
+	address common.Address
+}
+
+// NewRemoteSigner returns a signer of the account calling the method of the signer client.
+func NewRemoteSigner(client *rpc.Client, method string, address common.Address) *RemoteSigner {
+	return &RemoteSigner{client: client, method: method, address: address}
+}
+
+func (s *RemoteSigner) Address() common.Address {
+	return s.address
+}
+
+// signTxArgs are the transaction arguments understood by Clef and eth_signTransaction.
+type signTxArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *hexutil.Big    `json:"chainId"`
+}
+
+// signTxResult is the result of Clef and of the nodes. Web3Signer returns the raw transaction only.
+type signTxResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+func (s *RemoteSigner) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := signTxArgs{
+		From:                 s.address,
+		To:                   tx.To(),
+		Gas:                  hexutil.Uint64(tx.Gas()),
+		MaxFeePerGas:         (*hexutil.Big)(tx.GasFeeCap()),
+		MaxPriorityFeePerGas: (*hexutil.Big)(tx.GasTipCap()),
+		Value:                (*hexutil.Big)(tx.Value()),
+		Nonce:                hexutil.Uint64(tx.Nonce()),
+		Data:                 tx.Data(),
+		ChainID:              (*hexutil.Big)(chainID),
+	}
+
+	var res json.RawMessage
+	if err := s.client.CallContext(ctx, &res, s.method, args); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSignerUnavailable, err)
+	}
+
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(res, &raw); err != nil {
+		var result signTxResult
+		if err := json.Unmarshal(res, &result); err != nil {
+			return nil, fmt.Errorf("invalid signer result: %w", err)
+		}
+		raw = result.Raw
+	}
+
+	signed := new(types.Transaction)
+	if err := signed.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("invalid signed transaction: %w", err)
+	}
+	if err := checkSigned(tx, signed, s.address, chainID); err != nil {
+		return nil, err
+	}
+	return signed, nil
+}
+
+func (s *RemoteSigner) SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+
+	var sig hexutil.Bytes
+	if err := s.client.CallContext(ctx, &sig, typedDataMethods[s.method], s.address, typedData); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSignerUnavailable, err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+
+	// Signers return a recovery id of 0 or 1, or of 27 or 28.
+	if sig[crypto.RecoveryIDOffset] < 27 {
+		sig[crypto.RecoveryIDOffset] += 27
+	}
+	recovery := append([]byte(nil), sig...)
+	recovery[crypto.RecoveryIDOffset] -= 27
+	pub, err := crypto.SigToPub(hash, recovery)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature: %w", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pub); signer != s.address {
+		return nil, fmt.Errorf("typed data signed by %s instead of %s", signer, s.address)
+	}
+	return sig, nil
+}
+
+// checkSigned returns an error if the signed transaction is not the transaction signed by the account,
+// so that a misbehaving signer can't send anything else.
+func checkSigned(tx, signed *types.Transaction, from common.Address, chainID *big.Int) error {
+	signer := types.LatestSignerForChainID(chainID)
+	if signer.Hash(signed) != signer.Hash(tx) {
+		return errors.New("signer altered the transaction")
+	}
+	sender, err := types.Sender(signer, signed)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+	if sender != from {
+		return fmt.Errorf("transaction signed by %s instead of %s", sender, from)
+	}
+	return nil
+}
+
+// AccountSigner returns the signer of the account the key references.
+// Private keys are only taken if the configuration allows it, since the admin API guards them with its token only.
+func (s *Service) AccountSigner(ctx context.Context, key data.AccountKey) (Signer, error) {
+	set := 0
+	for _, ref := range []string{key.KeyFile, key.SignerAccount, key.PrivateKey} {
+		if ref != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, fmt.Errorf("%w: expected one of key_file, signer_account and private_key", ErrInvalidAccountKey)
+	}
+
+	switch {
+	case key.SignerAccount != "":
+		if key.SignerURL == "" {
+			return nil, fmt.Errorf("%w: no signer URL", ErrInvalidAccountKey)
+		}
+		if !common.IsHexAddress(key.SignerAccount) {
+			return nil, fmt.Errorf("%w: invalid signer account %q", ErrInvalidAccountKey, key.SignerAccount)
+		}
+		method, err := SignMethod(key.SignerMethod)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidAccountKey, err)
+		}
+		client, err := rpc.DialContext(ctx, key.SignerURL)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSignerUnavailable, err)
+		}
+		return NewRemoteSigner(client, method, common.HexToAddress(key.SignerAccount)), nil
+	case key.KeyFile != "":
+		k, err := os.ReadFile(key.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read key file: %w", ErrInvalidAccountKey, err)
+		}
+		account, err := data.NewAccount(string(k))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid private key in %s: %w", ErrInvalidAccountKey, key.KeyFile, err)
+		}
+		s.warnLotusKey(account)
+		return NewLocalSigner(account), nil
+	default:
+		if !s.cfg.AdminPrivateKeys {
+			return nil, fmt.Errorf("%w: private keys are not accepted, reference a key_file or a signer_account instead", ErrDenied)
+		}
+		account, err := data.NewAccount(key.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid private key: %w", ErrInvalidAccountKey, err)
+		}
+		s.warnLotusKey(account)
+		return NewLocalSigner(account), nil
+	}
+}
+
+// warnLotusKey logs the 0x address of the account next to the f1 address of its wallet if the key is
+// the secp256k1 key of a Lotus wallet, since the funds of the wallet are not on the address the pool sends from.
+func (s *Service) warnLotusKey(account *data.EthereumAccount) {
+	if !account.LotusSecp256k1() {
+		return
+	}
+	f1, err := account.FilecoinAddress()
+	if err != nil {
+		return
+	}
+	s.log.Warnw("the account is a Lotus secp256k1 wallet: EVM transfers are sent from its 0x address, not from the f1 address of the wallet",
+		"account", account.Address.Hex(), "f1", ftypes.FilecoinAddressString(f1, s.NetworkPrefix()))
+}
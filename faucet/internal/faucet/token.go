@@ -0,0 +1,75 @@
+package faucet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// TokenConfig describes an ERC-20 token dispensed by the faucet.
+// Amounts and limits are in whole tokens.
+type TokenConfig struct {
+	Symbol               string
+	Address              common.Address
+	Decimals             uint8
+	TransferAmount       uint64
+	AddressTransferLimit uint64
+	TotalTransferLimit   uint64
+}
+
+const erc20ABIJSON = `[
+	{"type":"function","name":"transfer","stateMutability":"nonpayable",
+	 "inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],
+	 "outputs":[{"name":"","type":"bool"}]},
+	{"type":"function","name":"balanceOf","stateMutability":"view",
+	 "inputs":[{"name":"account","type":"address"}],
+	 "outputs":[{"name":"","type":"uint256"}]}
+]`
+
+var erc20ABI = mustParseABI(erc20ABIJSON)
+
+func (s *Service) transferToken(ctx context.Context, token *TokenConfig, to common.Address, amount uint64) (common.Hash, error) {
+	value := TokenAmount(amount, token.Decimals)
+	input, err := erc20ABI.Pack("transfer", to, value)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to pack %s transfer: %w", token.Symbol, err)
+	}
+	return s.sendTx(ctx, token.Address, new(big.Int), input, spend{token.Address: value})
+}
+
+func (s *Service) tokenBalance(ctx context.Context, token *TokenConfig, addr common.Address) (*big.Int, error) {
+	input, err := erc20ABI.Pack("balanceOf", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %s balanceOf: %w", token.Symbol, err)
+	}
+
+	out, err := s.client.CallContract(ctx, ethereum.CallMsg{To: &token.Address, Data: input}, nil)
+	if err != nil {
+		return nil, unavailable("failed to get token balance", err)
+	}
+
+	res, err := erc20ABI.Unpack("balanceOf", out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s balance: %w", token.Symbol, err)
+	}
+	return abi.ConvertType(res[0], new(big.Int)).(*big.Int), nil
+}
+
+// TokenAmount converts whole tokens into base units.
+func TokenAmount(amount uint64, decimals uint8) *big.Int {
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	return new(big.Int).Mul(new(big.Int).SetUint64(amount), unit)
+}
+
+func mustParseABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
@@ -0,0 +1,377 @@
+package faucet
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/contract"
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/db"
+)
+
+const (
+	VoucherIssued  = "issued"
+	VoucherClaimed = "claimed"
+	// VoucherCredited is an expired voucher whose amount was returned to the quotas.
+	VoucherCredited = "credited"
+	// VoucherExpired is an expired voucher issued in a window that was reset before it expired.
+	VoucherExpired = "expired"
+)
+
+const (
+	defaultVoucherTTL      = time.Hour
+	defaultVoucherInterval = time.Minute
+)
+
+// The EIP-712 domain of the claim contract.
+const (
+	voucherDomainName    = "FaucetClaim"
+	voucherDomainVersion = "1"
+)
+
+// VoucherConfig enables the voucher mode: native coin requests are granted with EIP-712 vouchers
+// that recipients redeem at the claim contract, instead of transfers paid by the faucet.
+type VoucherConfig struct {
+	Contract common.Address
+	// Signer signs the vouchers. It must be the signer of the claim contract, and a TypedDataSigner.
+	Signer Signer
+	// TTL is the validity of a voucher.
+	TTL time.Duration
+	// Interval is the period of the checks of expired vouchers.
+	Interval time.Duration
+}
+
+var claimABI = mustParseABI(contract.ClaimMetaData.ABI)
+
+func newClaimContract(cfg *VoucherConfig, client Backend) *contract.Claim {
+	if cfg == nil {
+		return nil
+	}
+	// The binding only fails on an invalid ABI, which is checked by claimABI.
+	c, _ := contract.NewClaim(cfg.Contract, client)
+	return c
+}
+
+// VoucherTypedData returns the EIP-712 typed data of a voucher of the claim contract.
+func VoucherTypedData(chainID *big.Int, claim, recipient common.Address, amount, nonce *big.Int, expiry int64) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Voucher": {
+				{Name: "recipient", Type: "address"},
+				{Name: "amount", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "expiry", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Voucher",
+		Domain: apitypes.TypedDataDomain{
+			Name:              voucherDomainName,
+			Version:           voucherDomainVersion,
+			ChainId:           (*math.HexOrDecimal256)(chainID),
+			VerifyingContract: claim.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"recipient": recipient.Hex(),
+			"amount":    amount.String(),
+			"nonce":     nonce.String(),
+			"expiry":    strconv.FormatInt(expiry, 10),
+		},
+	}
+}
+
+// fundVoucher grants the native coin with a voucher, within the quota of the recipient.
+func (s *Service) fundVoucher(ctx context.Context, entry *data.LedgerEntry, a *asset, identity string, to common.Address) (data.FundResponse, error) {
+	var v data.Voucher
+	resp, err := s.fund(ctx, entry, a, identity, func() (string, error) {
+		var err error
+		v, err = s.issueVoucher(ctx, identity, to, a.amount)
+		entry.Voucher = v.Nonce
+		return "", err
+	})
+	if err != nil {
+		return data.FundResponse{}, err
+	}
+	resp.Voucher = &v
+	return resp, nil
+}
+
+// issueVoucher signs a voucher for the amount and records it until it is claimed or expires.
+func (s *Service) issueVoucher(ctx context.Context, identity string, to common.Address, amount uint64) (data.Voucher, error) {
+	vc := s.cfg.Vouchers
+
+	ttl := vc.TTL
+	if ttl == 0 {
+		ttl = defaultVoucherTTL
+	}
+
+	value := TransferAmount(amount)
+
+	// The check of the balance and the record of the voucher are atomic, so concurrent vouchers are all covered.
+	s.voucherMu.Lock()
+	defer s.voucherMu.Unlock()
+
+	// The contract checks the expiry against the block time.
+	head, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return data.Voucher{}, unavailable("failed to get block", err)
+	}
+	expiry := int64(head.Time) + int64(ttl/time.Second)
+
+	// A voucher the contract can't pay is useless to the recipient,
+	// and the vouchers that can still be claimed are paid first.
+	// Expired vouchers can't be claimed, even before the periodic resolution takes them off the outstanding sum.
+	balance, err := s.client.BalanceAt(ctx, vc.Contract, nil)
+	if err != nil {
+		return data.Voucher{}, unavailable("failed to get claim contract balance", err)
+	}
+	outstanding, err := s.db.GetOutstandingVouchers(ctx)
+	if err != nil {
+		return data.Voucher{}, err
+	}
+	expired, err := s.db.GetExpiredVouchers(ctx, int64(head.Time))
+	if err != nil {
+		return data.Voucher{}, err
+	}
+	for _, rec := range expired {
+		amount, ok := new(big.Int).SetString(rec.Amount, 10)
+		if !ok {
+			return data.Voucher{}, fmt.Errorf("invalid voucher amount %q", rec.Amount)
+		}
+		outstanding.Sub(outstanding, amount)
+	}
+	if available := new(big.Int).Sub(balance, outstanding); available.Cmp(value) < 0 {
+		return data.Voucher{}, fmt.Errorf("%w: claim contract balance is %s, of which %s is owed to issued vouchers",
+			ErrInsufficientFunds, balance, outstanding)
+	}
+
+	// Random nonces don't need coordination between the faucet instances.
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return data.Voucher{}, fmt.Errorf("failed to generate voucher nonce: %w", err)
+	}
+	nonce := new(big.Int).SetBytes(b)
+
+	signer, ok := vc.Signer.(TypedDataSigner)
+	if !ok {
+		return data.Voucher{}, fmt.Errorf("voucher signer %s can't sign typed data", vc.Signer.Address())
+	}
+	sig, err := signer.SignTypedData(ctx, VoucherTypedData(s.cfg.ChainID, vc.Contract, to, value, nonce, expiry))
+	if err != nil {
+		return data.Voucher{}, fmt.Errorf("failed to sign voucher: %w", err)
+	}
+
+	input, err := claimABI.Pack("claim", to, value, nonce, big.NewInt(expiry),
+		sig[crypto.RecoveryIDOffset], [32]byte(sig[:32]), [32]byte(sig[32:64]))
+	if err != nil {
+		return data.Voucher{}, fmt.Errorf("failed to pack claim: %w", err)
+	}
+
+	v := data.Voucher{
+		Contract:  vc.Contract.Hex(),
+		ChainID:   s.cfg.ChainID.Uint64(),
+		Recipient: to.Hex(),
+		Amount:    value.String(),
+		Nonce:     nonce.String(),
+		Expiry:    expiry,
+		Signature: hexutil.Encode(sig),
+		Calldata:  hexutil.Encode(input),
+	}
+
+	err = s.db.AddVoucher(ctx, data.VoucherRecord{
+		Voucher:  v,
+		Identity: identity,
+		Granted:  amount,
+		Issued:   time.Now(),
+		Status:   VoucherIssued,
+	})
+	if err != nil {
+		return data.Voucher{}, err
+	}
+	s.log.Infow("voucher issued", "to", v.Recipient, "nonce", v.Nonce, "expiry", expiry)
+
+	return v, nil
+}
+
+// runVouchers resolves the claimed and the expired vouchers periodically.
+func (s *Service) runVouchers() {
+	interval := s.cfg.Vouchers.Interval
+	if interval == 0 {
+		interval = defaultVoucherInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := s.resolveClaims(s.ctx); err != nil {
+			s.log.Errorw("failed to resolve claimed vouchers", "err", err)
+		}
+		if err := s.resolveVouchers(s.ctx); err != nil {
+			s.log.Errorw("failed to resolve expired vouchers", "err", err)
+		}
+	}
+}
+
+// resolveVouchers credits the expired vouchers that were not claimed back to the quotas.
+// A voucher is expired once the chain is past its expiry, so it can't be claimed anymore.
+func (s *Service) resolveVouchers(ctx context.Context) error {
+	head, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return unavailable("failed to get block", err)
+	}
+
+	records, err := s.db.GetExpiredVouchers(ctx, int64(head.Time))
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range records {
+		nonce, ok := new(big.Int).SetString(rec.Nonce, 10)
+		if !ok {
+			return fmt.Errorf("invalid voucher nonce %q", rec.Nonce)
+		}
+		claimed, err := s.claimContract.Claimed(&bind.CallOpts{Context: ctx}, nonce)
+		if err != nil {
+			return unavailable("failed to check voucher", err)
+		}
+
+		// The credit is stored with the resolution, so that a voucher is never credited twice.
+		s.voucherMu.Lock()
+		err = s.updateQuotas(ctx, func(tx *db.Database) error {
+			switch {
+			case claimed:
+				rec.Status = VoucherClaimed
+			default:
+				credited, err := s.creditVoucher(ctx, tx, rec)
+				if err != nil {
+					return err
+				}
+				rec.Status = VoucherExpired
+				if credited {
+					rec.Status = VoucherCredited
+				}
+			}
+
+			now := time.Now()
+			rec.Resolved = &now
+			return tx.ResolveVoucher(ctx, rec)
+		})
+		s.voucherMu.Unlock()
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			s.log.Infow("voucher expired", "to", rec.Recipient, "nonce", rec.Nonce, "status", rec.Status)
+		}
+	}
+	return nil
+}
+
+// resolveClaims resolves the vouchers claimed since the last check, from the Claimed events of the contract,
+// so that what they owed stops counting against the balance before they expire.
+// Vouchers claimed before the first check are resolved once they expire.
+func (s *Service) resolveClaims(ctx context.Context) error {
+	head, err := s.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return unavailable("failed to get block", err)
+	}
+	end := head.Number.Uint64()
+
+	start, err := s.db.GetVoucherClaimsBlock(ctx)
+	if err != nil {
+		return err
+	}
+	if start == 0 {
+		start = end
+	}
+	if start > end {
+		return nil
+	}
+
+	it, err := s.claimContract.FilterClaimed(&bind.FilterOpts{Start: start, End: &end, Context: ctx}, nil, nil)
+	if err != nil {
+		return unavailable("failed to get voucher claims", err)
+	}
+	defer it.Close() // nolint
+
+	for it.Next() {
+		rec, err := s.db.GetVoucher(ctx, it.Event.Nonce.String())
+		if err != nil {
+			return err
+		}
+		// Vouchers of other faucets are unknown.
+		if rec.Status != VoucherIssued {
+			continue
+		}
+
+		now := time.Now()
+		rec.Status = VoucherClaimed
+		rec.Resolved = &now
+		s.voucherMu.Lock()
+		err = s.db.ResolveVoucher(ctx, rec)
+		s.voucherMu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	if err := it.Error(); err != nil {
+		return unavailable("failed to get voucher claims", err)
+	}
+
+	return s.db.UpdateVoucherClaimsBlock(ctx, end+1)
+}
+
+// creditVoucher returns the amount of the voucher to the quotas if their window is the one it was issued in.
+// It reports whether a quota was credited.
+func (s *Service) creditVoucher(ctx context.Context, d *db.Database, rec data.VoucherRecord) (bool, error) {
+	a, err := s.asset(NativeAsset)
+	if err != nil {
+		return false, err
+	}
+	return s.creditQuota(ctx, d, a, rec.Identity, rec.Granted, rec.Issued)
+}
+
+// VoucherRecord returns the state of the voucher with the nonce.
+// Claims of vouchers that have not expired yet are checked on the chain.
+func (s *Service) VoucherRecord(ctx context.Context, nonce *big.Int) (data.VoucherRecord, error) {
+	rec, err := s.db.GetVoucher(ctx, nonce.String())
+	if err != nil {
+		return data.VoucherRecord{}, err
+	}
+	if rec.Nonce == "" {
+		return data.VoucherRecord{}, ErrVoucherNotFound
+	}
+
+	if rec.Status == VoucherIssued && s.claimContract != nil {
+		claimed, err := s.claimContract.Claimed(&bind.CallOpts{Context: ctx}, nonce)
+		if err != nil {
+			return data.VoucherRecord{}, unavailable("failed to check voucher", err)
+		}
+		if claimed {
+			rec.Status = VoucherClaimed
+		}
+	}
+	return rec, nil
+}
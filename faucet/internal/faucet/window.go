@@ -0,0 +1,90 @@
+package faucet
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WindowMode defines how quota windows are aligned in time.
+type WindowMode string
+
+const (
+	// WindowRolling starts a 24-hour window at the first transfer after the previous window expired.
+	WindowRolling WindowMode = "rolling"
+	// WindowDaily aligns windows to midnight in the configured location.
+	WindowDaily WindowMode = "daily"
+	// WindowWeekly aligns windows to Monday midnight in the configured location.
+	WindowWeekly WindowMode = "weekly"
+)
+
+const rollingWindowDuration = 24 * time.Hour
+
+// Window computes quota window boundaries.
+// The zero value is a rolling window.
+type Window struct {
+	Mode     WindowMode
+	Location *time.Location
+}
+
+// NewWindow returns a window for the given mode and IANA time zone name.
+func NewWindow(mode, timeZone string) (Window, error) {
+	m := WindowMode(strings.ToLower(mode))
+	switch m {
+	case "", WindowRolling:
+		m = WindowRolling
+	case WindowDaily, WindowWeekly:
+	default:
+		return Window{}, fmt.Errorf("unknown reset mode %q", mode)
+	}
+
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return Window{}, fmt.Errorf("failed to load time zone %q: %w", timeZone, err)
+	}
+
+	return Window{Mode: m, Location: loc}, nil
+}
+
+// Begin returns the start of the window that is opened at the given moment.
+func (w Window) Begin(now time.Time) time.Time {
+	switch w.Mode {
+	case WindowDaily:
+		return w.midnight(now)
+	case WindowWeekly:
+		day := w.midnight(now)
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	default:
+		return now
+	}
+}
+
+// NextReset returns the moment the window started at start expires.
+func (w Window) NextReset(start time.Time) time.Time {
+	switch w.Mode {
+	case WindowDaily:
+		return w.Begin(start).AddDate(0, 0, 1)
+	case WindowWeekly:
+		return w.Begin(start).AddDate(0, 0, 7)
+	default:
+		return start.Add(rollingWindowDuration)
+	}
+}
+
+// Expired reports whether the window started at start is over at the given moment.
+func (w Window) Expired(start, now time.Time) bool {
+	return start.IsZero() || !now.Before(w.NextReset(start))
+}
+
+func (w Window) midnight(t time.Time) time.Time {
+	t = t.In(w.location())
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func (w Window) location() *time.Location {
+	if w.Location == nil {
+		return time.UTC
+	}
+	return w.Location
+}
@@ -0,0 +1,71 @@
+package faucet
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRollingWindow(t *testing.T) {
+	w, err := NewWindow("rolling", "UTC")
+	require.NoError(t, err)
+
+	now := time.Date(2023, 11, 15, 13, 30, 0, 0, time.UTC)
+	start := w.Begin(now)
+	require.Equal(t, now, start)
+	require.Equal(t, now.Add(24*time.Hour), w.NextReset(start))
+
+	require.True(t, w.Expired(time.Time{}, now))
+	require.False(t, w.Expired(start, now.Add(23*time.Hour)))
+	require.True(t, w.Expired(start, now.Add(24*time.Hour)))
+}
+
+func TestDailyWindow(t *testing.T) {
+	w, err := NewWindow("daily", "UTC")
+	require.NoError(t, err)
+
+	now := time.Date(2023, 11, 15, 13, 30, 0, 0, time.UTC)
+	start := w.Begin(now)
+	require.Equal(t, time.Date(2023, 11, 15, 0, 0, 0, 0, time.UTC), start)
+	require.Equal(t, time.Date(2023, 11, 16, 0, 0, 0, 0, time.UTC), w.NextReset(start))
+
+	require.False(t, w.Expired(start, time.Date(2023, 11, 15, 23, 59, 59, 0, time.UTC)))
+	require.True(t, w.Expired(start, time.Date(2023, 11, 16, 0, 0, 0, 0, time.UTC)))
+}
+
+func TestWeeklyWindow(t *testing.T) {
+	w, err := NewWindow("weekly", "UTC")
+	require.NoError(t, err)
+
+	// Wednesday.
+	now := time.Date(2023, 11, 15, 13, 30, 0, 0, time.UTC)
+	start := w.Begin(now)
+	require.Equal(t, time.Date(2023, 11, 13, 0, 0, 0, 0, time.UTC), start)
+	require.Equal(t, time.Monday, start.Weekday())
+	require.Equal(t, time.Date(2023, 11, 20, 0, 0, 0, 0, time.UTC), w.NextReset(start))
+
+	// Sunday belongs to the week started on the previous Monday.
+	sunday := time.Date(2023, 11, 19, 22, 0, 0, 0, time.UTC)
+	require.Equal(t, start, w.Begin(sunday))
+	require.False(t, w.Expired(start, sunday))
+}
+
+func TestWindowTimeZone(t *testing.T) {
+	w, err := NewWindow("daily", "Asia/Tokyo")
+	require.NoError(t, err)
+
+	// 20:00 UTC is already the next day in Tokyo.
+	now := time.Date(2023, 11, 15, 20, 0, 0, 0, time.UTC)
+	start := w.Begin(now)
+	require.True(t, start.Equal(time.Date(2023, 11, 15, 15, 0, 0, 0, time.UTC)))
+	require.True(t, w.NextReset(start).Equal(time.Date(2023, 11, 16, 15, 0, 0, 0, time.UTC)))
+}
+
+func TestWindowInvalidConfig(t *testing.T) {
+	_, err := NewWindow("monthly", "UTC")
+	require.Error(t, err)
+
+	_, err = NewWindow("daily", "Nowhere/Unknown")
+	require.Error(t, err)
+}
@@ -0,0 +1,251 @@
+package http
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gorilla/mux"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	"github.com/consensus-shipyard/calibration/faucet/internal/platform/web"
+)
+
+var errUnauthorized = errors.New("invalid admin token")
+
+// adminRoutes registers the admin API of the faucet under the prefix.
+// The API is not served if no admin token is configured.
+func (h *FaucetWebService) adminRoutes(r *mux.Router, prefix string, svc *faucet.Service, token string) {
+	if token == "" {
+		return
+	}
+
+	r.HandleFunc(prefix+"/admin/accounts", adminAuth(token, h.handlePoolAccounts(svc))).Methods("GET")
+	r.HandleFunc(prefix+"/admin/accounts", adminAuth(token, h.handleAddAccount(svc))).Methods("POST")
+	r.HandleFunc(prefix+"/admin/accounts/{address}", adminAuth(token, h.handleRemoveAccount(svc))).Methods("DELETE")
+	r.HandleFunc(prefix+"/admin/accounts/{address}/rotate", adminAuth(token, h.handleRotateAccount(svc))).Methods("POST")
+	r.HandleFunc(prefix+"/admin/refills", adminAuth(token, h.handleRefills(svc))).Methods("GET")
+	r.HandleFunc(prefix+"/admin/rotations", adminAuth(token, h.handleRotations(svc))).Methods("GET")
+	r.HandleFunc(prefix+"/admin/ledger", adminAuth(token, h.handleLedger(svc))).Methods("GET")
+}
+
+// adminAuth rejects requests without the admin token in the Authorization header.
+func adminAuth(token string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			web.RespondError(w, http.StatusUnauthorized, errUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (h *FaucetWebService) handlePoolAccounts(svc *faucet.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := svc.PoolAccounts(r.Context())
+		if err != nil {
+			h.log.Errorw("failed to get pool accounts", "remote", r.RemoteAddr, "err", err)
+			respondAdminError(w, err)
+			return
+		}
+
+		if err := web.Respond(r.Context(), w, resp, http.StatusOK); err != nil {
+			web.RespondError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *FaucetWebService) handleAddAccount(svc *faucet.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req data.AddAccountRequest
+		if err := web.Decode(r, &req); err != nil {
+			web.RespondError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		signer, err := svc.AccountSigner(r.Context(), req.AccountKey)
+		if err != nil {
+			respondAdminError(w, err)
+			return
+		}
+
+		if err := svc.AddAccount(signer); err != nil {
+			respondAdminError(w, err)
+			return
+		}
+		h.log.Infow("pool account added", "remote", r.RemoteAddr, "account", signer.Address())
+
+		resp := data.PoolAccount{Address: signer.Address().Hex()}
+		if err := web.Respond(r.Context(), w, resp, http.StatusCreated); err != nil {
+			web.RespondError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *FaucetWebService) handleRemoveAccount(svc *faucet.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		addr := mux.Vars(r)["address"]
+		if !common.IsHexAddress(addr) {
+			web.RespondError(w, http.StatusBadRequest, fmt.Errorf("invalid account address %q", addr))
+			return
+		}
+
+		if err := svc.RemoveAccount(common.HexToAddress(addr)); err != nil {
+			respondAdminError(w, err)
+			return
+		}
+		h.log.Infow("pool account removed", "remote", r.RemoteAddr, "account", addr)
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (h *FaucetWebService) handleRotateAccount(svc *faucet.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		addr := mux.Vars(r)["address"]
+		if !common.IsHexAddress(addr) {
+			web.RespondError(w, http.StatusBadRequest, fmt.Errorf("invalid account address %q", addr))
+			return
+		}
+
+		var req data.RotateAccountRequest
+		if err := web.Decode(r, &req); err != nil {
+			web.RespondError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		signer, err := svc.AccountSigner(r.Context(), req.AccountKey)
+		if err != nil {
+			respondAdminError(w, err)
+			return
+		}
+
+		resp, err := svc.RotateAccount(r.Context(), common.HexToAddress(addr), signer, req.Sweep)
+		if err != nil {
+			respondAdminError(w, err)
+			return
+		}
+		h.log.Infow("pool account rotated", "remote", r.RemoteAddr, "old", addr, "new", signer.Address(), "sweep", req.Sweep)
+
+		if err := web.Respond(r.Context(), w, resp, http.StatusAccepted); err != nil {
+			web.RespondError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *FaucetWebService) handleRefills(svc *faucet.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := svc.RefillRecords(r.Context())
+		if err != nil {
+			h.log.Errorw("failed to get refill records", "remote", r.RemoteAddr, "err", err)
+			respondAdminError(w, err)
+			return
+		}
+
+		if err := web.Respond(r.Context(), w, resp, http.StatusOK); err != nil {
+			web.RespondError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *FaucetWebService) handleRotations(svc *faucet.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := svc.RotationRecords(r.Context())
+		if err != nil {
+			h.log.Errorw("failed to get rotation records", "remote", r.RemoteAddr, "err", err)
+			respondAdminError(w, err)
+			return
+		}
+
+		if err := web.Respond(r.Context(), w, resp, http.StatusOK); err != nil {
+			web.RespondError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *FaucetWebService) handleLedger(svc *faucet.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseLedgerFilter(r.URL.Query())
+		if err != nil {
+			web.RespondError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		resp, err := svc.Ledger(r.Context(), filter)
+		if err != nil {
+			h.log.Errorw("failed to get ledger", "remote", r.RemoteAddr, "err", err)
+			respondAdminError(w, err)
+			return
+		}
+
+		if err := web.Respond(r.Context(), w, resp, http.StatusOK); err != nil {
+			web.RespondError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+// parseLedgerFilter parses the query parameters of the ledger: address, ip, decision,
+// from and to in RFC 3339 format, cursor and limit.
+func parseLedgerFilter(q url.Values) (data.LedgerFilter, error) {
+	filter := data.LedgerFilter{
+		Address:  q.Get("address"),
+		IP:       q.Get("ip"),
+		Decision: q.Get("decision"),
+		Cursor:   q.Get("cursor"),
+	}
+
+	switch filter.Decision {
+	case "", faucet.LedgerGranted, faucet.LedgerRejected:
+	default:
+		return data.LedgerFilter{}, fmt.Errorf("invalid decision %q", filter.Decision)
+	}
+
+	for name, t := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if v := q.Get(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return data.LedgerFilter{}, fmt.Errorf("invalid %s time %q", name, v)
+			}
+			*t = parsed
+		}
+	}
+
+	if v := q.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return data.LedgerFilter{}, fmt.Errorf("invalid limit %q", v)
+		}
+		filter.Limit = limit
+	}
+
+	return filter, nil
+}
+
+// respondAdminError maps errors returned by the admin methods of the faucet service to HTTP responses.
+func respondAdminError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, faucet.ErrAccountNotFound):
+		web.RespondError(w, http.StatusNotFound, err)
+	case errors.Is(err, faucet.ErrAccountExists), errors.Is(err, faucet.ErrLastAccount):
+		web.RespondError(w, http.StatusConflict, err)
+	case errors.Is(err, faucet.ErrInvalidAccountKey):
+		web.RespondError(w, http.StatusBadRequest, err)
+	default:
+		respondFundError(w, err)
+	}
+}
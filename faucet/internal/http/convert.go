@@ -0,0 +1,125 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/gorilla/mux"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	"github.com/consensus-shipyard/calibration/faucet/internal/platform/web"
+	"github.com/consensus-shipyard/calibration/faucet/internal/types"
+)
+
+// handleConvert returns the equivalent representations of an address.
+// The conversion doesn't need a network: without one, the network-specific forms are omitted.
+func (h *FaucetWebService) handleConvert(w http.ResponseWriter, r *http.Request) {
+	input, err := url.PathUnescape(mux.Vars(r)["address"])
+	if err != nil {
+		web.RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rcpt, err := types.ParseAddress(input)
+	if err != nil {
+		web.RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, filecoinAddr, err := convertAddress(input, rcpt)
+	if err != nil {
+		web.RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	network := r.URL.Query().Get("network")
+	svc, err := h.service(r, network, rcpt.Subnet)
+	switch {
+	case err == nil:
+		if err := resolveForms(r, svc, rcpt, filecoinAddr, &resp); err != nil {
+			respondFundError(w, err)
+			return
+		}
+	case errors.Is(err, errUnknownNetwork) && network == "" && mux.Vars(r)["network"] == "":
+	default:
+		respondFundError(w, err)
+		return
+	}
+
+	if err := web.Respond(r.Context(), w, resp, http.StatusOK); err != nil {
+		web.RespondError(w, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+// convertAddress returns the network-independent representations of the recipient
+// and its Filecoin address.
+func convertAddress(input string, rcpt types.Address) (data.ConvertResponse, address.Address, error) {
+	filecoinAddr := rcpt.Filecoin
+	if !rcpt.IsFilecoin() {
+		var err error
+		if filecoinAddr, err = types.EthAddress(rcpt.Eth).ToFilecoinAddress(); err != nil {
+			return data.ConvertResponse{}, address.Undef, err
+		}
+	}
+
+	resp := data.ConvertResponse{
+		Input:   input,
+		Type:    types.ProtocolName(filecoinAddr.Protocol()),
+		Mainnet: types.FilecoinAddressString(filecoinAddr, address.MainnetPrefix),
+		Testnet: types.FilecoinAddressString(filecoinAddr, address.TestnetPrefix),
+	}
+
+	raw := input
+	if rcpt.Subnet != nil {
+		raw = input[strings.LastIndex(input, ":")+1:]
+	}
+	if strings.HasPrefix(raw, "0x") {
+		resp.Type = "ethereum"
+	}
+
+	if !rcpt.IsFilecoin() {
+		resp.Ethereum = rcpt.Eth.Hex()
+	}
+	if filecoinAddr.Protocol() == address.ID {
+		resp.ID = resp.Mainnet
+		resp.MaskedID = rcpt.Eth.Hex()
+	}
+
+	return resp, filecoinAddr, nil
+}
+
+// resolveForms adds the representations specific to the network of the faucet.
+// f1 and f3 addresses are resolved to their ID if the faucet has a Lotus API and the actor exists.
+func resolveForms(r *http.Request, svc *faucet.Service, rcpt types.Address, filecoinAddr address.Address, resp *data.ConvertResponse) error {
+	prefix := svc.NetworkPrefix()
+	resp.Filecoin = types.FilecoinAddressString(filecoinAddr, prefix)
+
+	if !rcpt.IsFilecoin() {
+		if filecoinAddr.Protocol() == address.ID {
+			resp.ID = resp.Filecoin
+		}
+		return nil
+	}
+
+	masked, err := svc.ResolveFilecoinAddress(r.Context(), rcpt.Filecoin)
+	switch {
+	case errors.Is(err, faucet.ErrActorNotFound), errors.Is(err, faucet.ErrUnsupportedAddress),
+		errors.Is(err, faucet.ErrInvalidAddress):
+		return nil
+	case err != nil:
+		return err
+	}
+
+	id, err := types.EthAddress(masked).ToFilecoinAddress()
+	if err != nil {
+		return err
+	}
+	resp.ID = types.FilecoinAddressString(id, prefix)
+	resp.MaskedID = masked.Hex()
+	return nil
+}
@@ -1,12 +1,23 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"html/template"
+	"math"
+	"math/big"
+	"net"
 	"net/http"
+	"net/url"
 	"path"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/gorilla/mux"
 	logging "github.com/ipfs/go-log/v2"
 
 	"github.com/consensus-shipyard/calibration/faucet/internal/data"
@@ -15,52 +26,363 @@ import (
 	"github.com/consensus-shipyard/calibration/faucet/internal/types"
 )
 
+var errUnknownNetwork = errors.New("unknown network")
+
+// defaultForwardedHeader is the header the trusted proxies put the address of the client in by default.
+const defaultForwardedHeader = "X-Forwarded-For"
+
 type FaucetWebService struct {
 	log            *logging.ZapEventLogger
-	faucet         *faucet.Service
+	faucets        map[string]*faucet.Service
 	backendAddress string
+	proxies        Proxies
+}
+
+// Proxies are the reverse proxies trusted to forward the address of the client of a request.
+type Proxies struct {
+	// Networks are the networks of the trusted proxies.
+	Networks []*net.IPNet
+	// Header is the header holding the address of the client, X-Forwarded-For by default.
+	Header string
+}
+
+func NewWebService(log *logging.ZapEventLogger, svc *faucet.Service, backendAddress string, proxies Proxies) *FaucetWebService {
+	return NewNetworksWebService(log, map[string]*faucet.Service{"": svc}, backendAddress, proxies)
 }
 
-func NewWebService(log *logging.ZapEventLogger, faucet *faucet.Service, backendAddress string) *FaucetWebService {
+// NewNetworksWebService returns a web service that routes requests to the faucet of the requested network.
+func NewNetworksWebService(log *logging.ZapEventLogger, faucets map[string]*faucet.Service, backendAddress string, proxies Proxies) *FaucetWebService {
+	if proxies.Header == "" {
+		proxies.Header = defaultForwardedHeader
+	}
 	return &FaucetWebService{
 		log:            log,
-		faucet:         faucet,
+		faucets:        faucets,
 		backendAddress: backendAddress,
+		proxies:        proxies,
+	}
+}
+
+// clientIP returns the IP address of the client of the request. The address of a trusted proxy is replaced by
+// the last address of the forwarded header that is not a trusted proxy: the addresses before it are set by
+// the client, which can forge them.
+func (h *FaucetWebService) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return ""
+	}
+	if !h.proxies.trusted(net.ParseIP(host)) {
+		return host
+	}
+
+	var forwarded []string
+	for _, value := range r.Header.Values(h.proxies.Header) {
+		for _, addr := range strings.Split(value, ",") {
+			forwarded = append(forwarded, strings.TrimSpace(addr))
+		}
 	}
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := net.ParseIP(forwarded[i])
+		if ip == nil {
+			break
+		}
+		if !h.proxies.trusted(ip) || i == 0 {
+			return ip.String()
+		}
+	}
+	return host
+}
+
+func (p Proxies) trusted(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, n := range p.Networks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// service returns the faucet of the network named in the URL path or, if absent, in the request.
+// If the recipient is qualified by a subnet and no network is named, the faucet serving the subnet is returned.
+// In single-network mode the only faucet is returned.
+func (h *FaucetWebService) service(r *http.Request, network string, subnet *types.SubnetID) (*faucet.Service, error) {
+	if n, ok := mux.Vars(r)["network"]; ok {
+		network = n
+	}
+
+	if single, ok := h.faucets[""]; ok {
+		return single, checkSubnet(single, subnet)
+	}
+
+	if network == "" && subnet != nil {
+		names := make([]string, 0, len(h.faucets))
+		for name := range h.faucets {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if h.faucets[name].CheckSubnet(*subnet) == nil {
+				return h.faucets[name], nil
+			}
+		}
+		return nil, fmt.Errorf("%w: %s", faucet.ErrSubnetNotServed, subnet)
+	}
+
+	svc, ok := h.faucets[network]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", errUnknownNetwork, network)
+	}
+	return svc, checkSubnet(svc, subnet)
+}
+
+func checkSubnet(svc *faucet.Service, subnet *types.SubnetID) error {
+	if subnet == nil {
+		return nil
+	}
+	return svc.CheckSubnet(*subnet)
 }
 
 func (h *FaucetWebService) handleFunds(w http.ResponseWriter, r *http.Request) {
 	var req data.FundRequest
 
+	if ip := h.clientIP(r); ip != "" {
+		r = r.WithContext(faucet.WithRequester(r.Context(), ip))
+	}
+
 	if err := web.Decode(r, &req); err != nil {
 		h.log.Errorw("failed to decode request", "remote", r.RemoteAddr, "error", err)
 		web.RespondError(w, http.StatusBadRequest, err)
 		return
 	}
 
-	var ethAddr common.Address
-	var err error
+	rcpt, err := types.ParseAddress(req.Address)
+	if err != nil {
+		h.log.Errorw("invalid recipient address", "remote", r.RemoteAddr, "addr", req.Address, "error", err)
+		web.RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	svc, err := h.service(r, req.Network, rcpt.Subnet)
+	if err != nil {
+		respondFundError(w, err)
+		return
+	}
 
-	if strings.HasPrefix(req.Address, "0x") {
-		ethAddr = common.HexToAddress(req.Address)
+	if err := rcpt.Check(svc.AddressOptions()); err != nil {
+		web.RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if req.Bundle != "" {
+		if rcpt.IsFilecoin() {
+			if rcpt.Eth, err = svc.ResolveFilecoinAddress(r.Context(), rcpt.Filecoin); err != nil {
+				respondFundError(w, err)
+				return
+			}
+		}
+		h.fundBundle(w, r, svc, req.Bundle, rcpt.Eth)
+		return
+	}
+
+	h.log.Infof("%s requests funds for %s", r.RemoteAddr, rcpt)
+
+	var resp data.FundResponse
+	if rcpt.IsFilecoin() {
+		resp, err = svc.FundFilecoinAddress(r.Context(), req.Asset, rcpt.Filecoin)
 	} else {
-		ethAddr, err = types.EthAddressFromFilecoinAddressString(req.Address)
-		if err != nil {
-			h.log.Errorw("unable to convert Filecoin address", "remote", r.RemoteAddr, "addr", ethAddr, "error", err)
-			web.RespondError(w, http.StatusBadRequest, err)
+		resp, err = svc.FundAddress(r.Context(), req.Asset, rcpt.Eth)
+	}
+	if err != nil {
+		h.log.Errorw("failed to fund address", "remote", r.RemoteAddr, "addr", rcpt, "err", err)
+		respondFundError(w, err)
+		return
+	}
+
+	if err := web.Respond(r.Context(), w, resp, http.StatusCreated); err != nil {
+		web.RespondError(w, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+func (h *FaucetWebService) fundBundle(w http.ResponseWriter, r *http.Request, svc *faucet.Service, bundle string, ethAddr common.Address) {
+	h.log.Infof("%s requests bundle %s for %s", r.RemoteAddr, bundle, ethAddr)
+
+	resp, err := svc.FundBundle(r.Context(), bundle, ethAddr)
+	if err != nil {
+		h.log.Errorw("failed to fund bundle", "remote", r.RemoteAddr, "addr", ethAddr, "bundle", bundle, "err", err)
+		respondFundError(w, err)
+		return
+	}
+
+	statusCode := http.StatusCreated
+	if resp.Failed() > 0 {
+		statusCode = http.StatusMultiStatus
+	}
+
+	if err := web.Respond(r.Context(), w, resp, statusCode); err != nil {
+		web.RespondError(w, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+func (h *FaucetWebService) handleAccount(w http.ResponseWriter, r *http.Request) {
+	addr, err := url.PathUnescape(mux.Vars(r)["address"])
+	if err != nil {
+		web.RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rcpt, err := types.ParseAddress(addr)
+	if err != nil {
+		h.log.Errorw("invalid recipient address", "remote", r.RemoteAddr, "addr", addr, "error", err)
+		web.RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	svc, err := h.service(r, r.URL.Query().Get("network"), rcpt.Subnet)
+	if err != nil {
+		respondFundError(w, err)
+		return
+	}
+
+	if err := rcpt.Check(svc.AddressOptions()); err != nil {
+		web.RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if rcpt.IsFilecoin() {
+		if rcpt.Eth, err = svc.ResolveFilecoinAddress(r.Context(), rcpt.Filecoin); err != nil {
+			respondFundError(w, err)
 			return
 		}
 	}
 
-	h.log.Infof("%s requests funds for %s", r.RemoteAddr, ethAddr)
+	resp, err := svc.AccountInfo(r.Context(), r.URL.Query().Get("asset"), rcpt.Eth)
+	if err != nil {
+		h.log.Errorw("failed to get account info", "remote", r.RemoteAddr, "addr", rcpt, "err", err)
+		respondFundError(w, err)
+		return
+	}
+
+	if err := web.Respond(r.Context(), w, resp, http.StatusOK); err != nil {
+		web.RespondError(w, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+func (h *FaucetWebService) handleCrossNet(w http.ResponseWriter, r *http.Request) {
+	svc, err := h.service(r, r.URL.Query().Get("network"), nil)
+	if err != nil {
+		respondFundError(w, err)
+		return
+	}
+
+	tx := mux.Vars(r)["tx"]
+	b, err := hexutil.Decode(tx)
+	if err != nil || len(b) != common.HashLength {
+		web.RespondError(w, http.StatusBadRequest, fmt.Errorf("invalid transaction hash %q", tx))
+		return
+	}
+
+	resp, err := svc.CrossNetMessage(r.Context(), common.BytesToHash(b))
+	if err != nil {
+		respondFundError(w, err)
+		return
+	}
+
+	if err := web.Respond(r.Context(), w, resp, http.StatusOK); err != nil {
+		web.RespondError(w, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+func (h *FaucetWebService) handleDrip(w http.ResponseWriter, r *http.Request) {
+	svc, err := h.service(r, r.URL.Query().Get("network"), nil)
+	if err != nil {
+		respondFundError(w, err)
+		return
+	}
+
+	tx := mux.Vars(r)["tx"]
+	b, err := hexutil.Decode(tx)
+	if err != nil || len(b) != common.HashLength {
+		web.RespondError(w, http.StatusBadRequest, fmt.Errorf("invalid transaction hash %q", tx))
+		return
+	}
+
+	resp, err := svc.DripRecord(r.Context(), common.BytesToHash(b))
+	if err != nil {
+		respondFundError(w, err)
+		return
+	}
+
+	if err := web.Respond(r.Context(), w, resp, http.StatusOK); err != nil {
+		web.RespondError(w, http.StatusInternalServerError, err)
+		return
+	}
+}
+
+func (h *FaucetWebService) handleVoucher(w http.ResponseWriter, r *http.Request) {
+	svc, err := h.service(r, r.URL.Query().Get("network"), nil)
+	if err != nil {
+		respondFundError(w, err)
+		return
+	}
+
+	n := mux.Vars(r)["nonce"]
+	nonce, ok := new(big.Int).SetString(n, 10)
+	if !ok || nonce.Sign() < 0 {
+		web.RespondError(w, http.StatusBadRequest, fmt.Errorf("invalid voucher nonce %q", n))
+		return
+	}
 
-	if err = h.faucet.FundAddress(r.Context(), ethAddr); err != nil {
-		h.log.Errorw("failed to fund address", "remote", r.RemoteAddr, "addr", ethAddr, "err", err)
+	resp, err := svc.VoucherRecord(r.Context(), nonce)
+	if err != nil {
+		respondFundError(w, err)
+		return
+	}
+
+	if err := web.Respond(r.Context(), w, resp, http.StatusOK); err != nil {
 		web.RespondError(w, http.StatusInternalServerError, err)
 		return
 	}
+}
+
+// respondFundError maps errors returned by the faucet service to HTTP responses.
+func respondFundError(w http.ResponseWriter, err error) {
+	var limitErr *faucet.LimitError
 
-	w.WriteHeader(http.StatusCreated)
+	switch {
+	case errors.As(err, &limitErr):
+		retryAfter := math.Ceil(limitErr.RetryAfter(time.Now()).Seconds())
+		w.Header().Set("Retry-After", strconv.FormatFloat(retryAfter, 'f', 0, 64))
+		w.Header().Set("X-RateLimit-Limit", strconv.FormatUint(limitErr.Limit, 10))
+		w.Header().Set("X-RateLimit-Remaining", strconv.FormatUint(limitErr.Remaining, 10))
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(limitErr.Reset.Unix(), 10))
+		web.RespondError(w, http.StatusTooManyRequests, err)
+	case errors.Is(err, faucet.ErrDripRejected):
+		web.RespondError(w, http.StatusTooManyRequests, err)
+	case errors.Is(err, faucet.ErrInvalidAddress), errors.Is(err, types.ErrInvalidAddress),
+		errors.Is(err, faucet.ErrUnknownAsset), errors.Is(err, faucet.ErrUnknownBundle),
+		errors.Is(err, faucet.ErrVoucherBundle):
+		web.RespondError(w, http.StatusBadRequest, err)
+	case errors.Is(err, faucet.ErrSubnetNotServed), errors.Is(err, faucet.ErrUnsupportedAddress):
+		web.RespondError(w, http.StatusBadRequest, err)
+	case errors.Is(err, faucet.ErrMessageNotFound), errors.Is(err, faucet.ErrActorNotFound),
+		errors.Is(err, faucet.ErrDripNotFound), errors.Is(err, faucet.ErrVoucherNotFound),
+		errors.Is(err, errUnknownNetwork):
+		web.RespondError(w, http.StatusNotFound, err)
+	case errors.Is(err, faucet.ErrDenied):
+		web.RespondError(w, http.StatusForbidden, err)
+	case errors.Is(err, faucet.ErrChainUnavailable), errors.Is(err, faucet.ErrInsufficientFunds),
+		errors.Is(err, faucet.ErrSignerUnavailable):
+		web.RespondError(w, http.StatusServiceUnavailable, err)
+	default:
+		web.RespondError(w, http.StatusInternalServerError, err)
+	}
 }
 
 func (h *FaucetWebService) handleHome(w http.ResponseWriter, r *http.Request) {
@@ -6,21 +6,21 @@ import (
 	"os"
 	"time"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	logging "github.com/ipfs/go-log/v2"
 
 	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
 	"github.com/consensus-shipyard/calibration/faucet/internal/platform/web"
 	"github.com/consensus-shipyard/calibration/faucet/pkg/version"
 )
 
 type Health struct {
 	log    *logging.ZapEventLogger
-	client *ethclient.Client
+	client faucet.Backend
 	build  string
 }
 
-func NewHealth(log *logging.ZapEventLogger, client *ethclient.Client, build string) *Health {
+func NewHealth(log *logging.ZapEventLogger, client faucet.Backend, build string) *Health {
 	h := Health{
 		log:    log,
 		client: client,
@@ -74,7 +74,7 @@ func (h *Health) Readiness(w http.ResponseWriter, r *http.Request) {
 
 	h.log.Infow("readiness check", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr)
 
-	if _, err := h.client.BlockByNumber(ctx, nil); err != nil {
+	if err := h.check(ctx); err != nil {
 		status = "eth client not ready"
 		statusCode = http.StatusInternalServerError
 		h.log.Infow("readiness failure", "status", status)
@@ -93,3 +93,44 @@ func (h *Health) Readiness(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// NetworksReadiness checks the readiness of every network and returns a 500 status if any of them is not ready.
+func NetworksReadiness(healths map[string]*Health) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+		defer cancel()
+
+		status := "ok"
+		statusCode := http.StatusOK
+		networks := make(map[string]string, len(healths))
+
+		for name, h := range healths {
+			h.log.Infow("readiness check", "network", name, "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr)
+			networks[name] = "ok"
+			if err := h.check(ctx); err != nil {
+				h.log.Infow("readiness failure", "network", name, "status", "eth client not ready")
+				networks[name] = "eth client not ready"
+				status = "not ready"
+				statusCode = http.StatusInternalServerError
+			}
+		}
+
+		resp := struct {
+			Status   string            `json:"status"`
+			Networks map[string]string `json:"networks"`
+		}{
+			Status:   status,
+			Networks: networks,
+		}
+
+		if err := web.Respond(ctx, w, resp, statusCode); err != nil {
+			web.RespondError(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+}
+
+func (h *Health) check(ctx context.Context) error {
+	_, err := h.client.BlockByNumber(ctx, nil)
+	return err
+}
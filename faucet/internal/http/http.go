@@ -1,34 +1,95 @@
 package http
 
 import (
+	"context"
 	"net/http"
 
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/gorilla/mux"
 	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/rs/cors"
 
 	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
 )
 
-func FaucetHandler(logger *logging.ZapEventLogger, client *ethclient.Client, db datastore.Batching, build string, cfg *faucet.Config) http.Handler {
+// Network is a chain served by the faucet in multi-network mode.
+type Network struct {
+	Name   string
+	Client faucet.Backend
+	Config *faucet.Config
+}
+
+// FaucetHandler serves the faucet of one network. Its background work runs until the context is done.
+func FaucetHandler(ctx context.Context, logger *logging.ZapEventLogger, client faucet.Backend, db datastore.Batching, build string, cfg *faucet.Config) http.Handler {
 	h := NewHealth(logger, client, build)
-	faucetService := faucet.NewService(logger, client, db, cfg)
-	srv := NewWebService(logger, faucetService, cfg.BackendAddress)
+	faucetService := faucet.NewService(ctx, logger, client, db, cfg)
+	srv := NewWebService(logger, faucetService, cfg.BackendAddress, Proxies{Networks: cfg.TrustedProxies, Header: cfg.ForwardedHeader})
 
-	r := mux.NewRouter().StrictSlash(true)
+	// Subnet-qualified addresses are passed path-escaped, so routes match the encoded path.
+	r := mux.NewRouter().StrictSlash(true).UseEncodedPath()
 
 	r.HandleFunc("/readiness", h.Readiness).Methods("GET")
 	r.HandleFunc("/liveness", h.Liveness).Methods("GET")
 	r.HandleFunc("/fund", srv.handleFunds).Methods("POST")
+	r.HandleFunc("/accounts/{address}", srv.handleAccount).Methods("GET")
+	r.HandleFunc("/crossnet/{tx}", srv.handleCrossNet).Methods("GET")
+	r.HandleFunc("/drips/{tx}", srv.handleDrip).Methods("GET")
+	r.HandleFunc("/vouchers/{nonce}", srv.handleVoucher).Methods("GET")
+	r.HandleFunc("/convert/{address}", srv.handleConvert).Methods("GET")
+	srv.adminRoutes(r, "", faucetService, cfg.AdminToken)
+
+	return staticHandler(r, srv, cfg.AllowedOrigins)
+}
+
+// NetworksHandler serves several networks from one process.
+// The storage of every network is namespaced by the network name. Their background work runs until the context is done.
+func NetworksHandler(ctx context.Context, logger *logging.ZapEventLogger, networks []Network, db datastore.Batching, build string, allowedOrigins []string, backendAddress string, proxies Proxies) http.Handler {
+	healths := make(map[string]*Health, len(networks))
+	faucets := make(map[string]*faucet.Service, len(networks))
+
+	for _, n := range networks {
+		store := namespace.Wrap(db, datastore.NewKey(n.Name))
+		healths[n.Name] = NewHealth(logger, n.Client, build)
+		faucets[n.Name] = faucet.NewService(ctx, logger, n.Client, store, n.Config)
+	}
+
+	srv := NewNetworksWebService(logger, faucets, backendAddress, proxies)
+
+	// Subnet-qualified addresses are passed path-escaped, so routes match the encoded path.
+	r := mux.NewRouter().StrictSlash(true).UseEncodedPath()
+
+	r.HandleFunc("/readiness", NetworksReadiness(healths)).Methods("GET")
+	r.HandleFunc("/fund", srv.handleFunds).Methods("POST")
+	r.HandleFunc("/accounts/{address}", srv.handleAccount).Methods("GET")
+	r.HandleFunc("/convert/{address}", srv.handleConvert).Methods("GET")
+
+	for name, h := range healths {
+		r.HandleFunc("/"+name+"/readiness", h.Readiness).Methods("GET")
+		r.HandleFunc("/"+name+"/liveness", h.Liveness).Methods("GET")
+	}
+	for _, n := range networks {
+		srv.adminRoutes(r, "/"+n.Name, faucets[n.Name], n.Config.AdminToken)
+	}
+	r.HandleFunc("/{network}/fund", srv.handleFunds).Methods("POST")
+	r.HandleFunc("/{network}/accounts/{address}", srv.handleAccount).Methods("GET")
+	r.HandleFunc("/{network}/crossnet/{tx}", srv.handleCrossNet).Methods("GET")
+	r.HandleFunc("/{network}/drips/{tx}", srv.handleDrip).Methods("GET")
+	r.HandleFunc("/{network}/vouchers/{nonce}", srv.handleVoucher).Methods("GET")
+	r.HandleFunc("/{network}/convert/{address}", srv.handleConvert).Methods("GET")
+
+	return staticHandler(r, srv, allowedOrigins)
+}
+
+func staticHandler(r *mux.Router, srv *FaucetWebService, allowedOrigins []string) http.Handler {
 	r.HandleFunc("/", srv.handleHome)
 	r.HandleFunc("/js/scripts.js", srv.handleScript)
 	r.PathPrefix("/").Handler(http.StripPrefix("/", http.FileServer(http.Dir("./static"))))
 
 	c := cors.New(cors.Options{
-		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowedOrigins:   allowedOrigins,
 		AllowCredentials: true,
+		ExposedHeaders:   []string{"Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"},
 	})
 
 	return c.Handler(r)
@@ -0,0 +1,179 @@
+package lotus
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/ipfs/go-cid"
+)
+
+// ErrActorNotFound is returned when an address has no actor on chain.
+var ErrActorNotFound = errors.New("actor not found")
+
+// ErrNoRobustAddress is returned when an actor has no robust address, like the actors created by the system.
+var ErrNoRobustAddress = errors.New("address not found")
+
+// Client is a minimal client of the Lotus JSON-RPC API.
+type Client struct {
+	url   string
+	token string
+	http  *http.Client
+	id    atomic.Int64
+}
+
+// NewClient returns a client of the Lotus API at the URL.
+// The token is sent as a bearer token if it is not empty.
+func NewClient(url, token string) *Client {
+	return &Client{
+		url:   url,
+		token: token,
+		http:  http.DefaultClient,
+	}
+}
+
+// MessageSendSpec limits the fees estimated for a message.
+type MessageSendSpec struct {
+	MaxFee big.Int
+}
+
+// MpoolGetNonce returns the next nonce of the address.
+func (c *Client) MpoolGetNonce(ctx context.Context, addr address.Address) (uint64, error) {
+	var nonce uint64
+	err := c.call(ctx, "Filecoin.MpoolGetNonce", &nonce, addr)
+	return nonce, err
+}
+
+// GasEstimateMessageGas returns the message with the gas fields estimated.
+func (c *Client) GasEstimateMessageGas(ctx context.Context, msg *Message) (*Message, error) {
+	var estimated Message
+	err := c.call(ctx, "Filecoin.GasEstimateMessageGas", &estimated, msg, &MessageSendSpec{MaxFee: big.Zero()}, nil)
+	return &estimated, err
+}
+
+// MpoolPush pushes the signed message to the message pool and returns its CID.
+func (c *Client) MpoolPush(ctx context.Context, msg *SignedMessage) (cid.Cid, error) {
+	var c0 cid.Cid
+	err := c.call(ctx, "Filecoin.MpoolPush", &c0, msg)
+	return c0, err
+}
+
+// Actor is the on-chain state of an actor.
+type Actor struct {
+	Nonce   uint64
+	Balance big.Int
+}
+
+// StateGetActor returns the nonce and the balance of the actor at the address.
+// ErrActorNotFound is returned if the actor doesn't exist.
+func (c *Client) StateGetActor(ctx context.Context, addr address.Address) (*Actor, error) {
+	var actor Actor
+	err := c.call(ctx, "Filecoin.StateGetActor", &actor, addr, nil)
+	var lotusErr *Error
+	if errors.As(err, &lotusErr) && strings.Contains(lotusErr.Message, ErrActorNotFound.Error()) {
+		return nil, fmt.Errorf("%w: %s", ErrActorNotFound, addr)
+	}
+	return &actor, err
+}
+
+// StateLookupID returns the ID address of the actor at the address.
+// ErrActorNotFound is returned if the actor doesn't exist.
+func (c *Client) StateLookupID(ctx context.Context, addr address.Address) (address.Address, error) {
+	var id address.Address
+	err := c.call(ctx, "Filecoin.StateLookupID", &id, addr, nil)
+	var lotusErr *Error
+	if errors.As(err, &lotusErr) && strings.Contains(lotusErr.Message, ErrActorNotFound.Error()) {
+		return address.Undef, fmt.Errorf("%w: %s", ErrActorNotFound, addr)
+	}
+	return id, err
+}
+
+// StateLookupRobustAddress returns the robust address of the actor at the ID address.
+// ErrActorNotFound is returned if the actor doesn't exist, and ErrNoRobustAddress if it has no robust address.
+func (c *Client) StateLookupRobustAddress(ctx context.Context, addr address.Address) (address.Address, error) {
+	var robust address.Address
+	err := c.call(ctx, "Filecoin.StateLookupRobustAddress", &robust, addr, nil)
+	var lotusErr *Error
+	if errors.As(err, &lotusErr) {
+		switch {
+		case strings.Contains(lotusErr.Message, ErrActorNotFound.Error()):
+			return address.Undef, fmt.Errorf("%w: %s", ErrActorNotFound, addr)
+		case strings.Contains(lotusErr.Message, ErrNoRobustAddress.Error()):
+			return address.Undef, fmt.Errorf("%w: %s", ErrNoRobustAddress, addr)
+		}
+	}
+	return robust, err
+}
+
+type request struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      int64  `json:"id"`
+	Method  string `json:"method"`
+	Params  []any  `json:"params"`
+}
+
+type response struct {
+	ID     int64           `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// Error is an error returned by the Lotus API.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("lotus: %s (%d)", e.Message, e.Code)
+}
+
+func (c *Client) call(ctx context.Context, method string, result any, params ...any) error {
+	body, err := json.Marshal(request{
+		JSONRPC: "2.0",
+		ID:      c.id.Add(1),
+		Method:  method,
+		Params:  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to call %s: %s", method, resp.Status)
+	}
+
+	var r response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", method, err)
+	}
+	if r.Error != nil {
+		return r.Error
+	}
+	if err := json.Unmarshal(r.Result, result); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package lotus
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+	cbg "github.com/whyrusleeping/cbor-gen"
+)
+
+// MethodSend is the method number of a plain value transfer.
+const MethodSend = 0
+
+// messageCidPrefix is the CID prefix of chain messages: DAG-CBOR hashed with BLAKE2b-256.
+var messageCidPrefix = cid.Prefix{
+	Version:  1,
+	Codec:    cid.DagCBOR,
+	MhType:   multihash.BLAKE2B_MIN + 31,
+	MhLength: 32,
+}
+
+// Message is a Filecoin chain message in the form used by the Lotus API.
+type Message struct {
+	Version    uint64
+	To         address.Address
+	From       address.Address
+	Nonce      uint64
+	Value      big.Int
+	GasLimit   int64
+	GasFeeCap  big.Int
+	GasPremium big.Int
+	Method     uint64
+	Params     []byte
+}
+
+// SignedMessage is a message with the signature of the sender.
+type SignedMessage struct {
+	Message   Message
+	Signature crypto.Signature
+}
+
+// MarshalCBOR encodes the message as a CBOR tuple, the form it is hashed and signed in.
+func (m *Message) MarshalCBOR() ([]byte, error) {
+	var buf bytes.Buffer
+	cw := cbg.NewCborWriter(&buf)
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, 10); err != nil {
+		return nil, err
+	}
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, m.Version); err != nil {
+		return nil, err
+	}
+	if err := m.To.MarshalCBOR(cw); err != nil {
+		return nil, fmt.Errorf("failed to encode recipient: %w", err)
+	}
+	if err := m.From.MarshalCBOR(cw); err != nil {
+		return nil, fmt.Errorf("failed to encode sender: %w", err)
+	}
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, m.Nonce); err != nil {
+		return nil, err
+	}
+	if err := m.Value.MarshalCBOR(cw); err != nil {
+		return nil, err
+	}
+	if m.GasLimit >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(m.GasLimit)); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-m.GasLimit-1)); err != nil {
+			return nil, err
+		}
+	}
+	if err := m.GasFeeCap.MarshalCBOR(cw); err != nil {
+		return nil, err
+	}
+	if err := m.GasPremium.MarshalCBOR(cw); err != nil {
+		return nil, err
+	}
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, m.Method); err != nil {
+		return nil, err
+	}
+	if err := cbg.WriteByteArray(cw, m.Params); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Cid returns the CID of the message. The sender signs the bytes of the CID.
+func (m *Message) Cid() (cid.Cid, error) {
+	b, err := m.MarshalCBOR()
+	if err != nil {
+		return cid.Undef, err
+	}
+	return messageCidPrefix.Sum(b)
+}
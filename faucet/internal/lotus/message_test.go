@@ -0,0 +1,41 @@
+package lotus
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageCBOR(t *testing.T) {
+	to, err := address.NewIDAddress(1)
+	require.NoError(t, err)
+	from, err := address.NewIDAddress(2)
+	require.NoError(t, err)
+
+	msg := Message{
+		To:         to,
+		From:       from,
+		Nonce:      3,
+		Value:      big.Zero(),
+		GasLimit:   4,
+		GasFeeCap:  big.Zero(),
+		GasPremium: big.Zero(),
+		Method:     MethodSend,
+	}
+
+	b, err := msg.MarshalCBOR()
+	require.NoError(t, err)
+	require.Equal(t, "8a0042000142000203400440400040", hex.EncodeToString(b))
+
+	c, err := msg.Cid()
+	require.NoError(t, err)
+	require.Equal(t, messageCidPrefix, c.Prefix())
+
+	msg.Value = big.NewInt(1)
+	c2, err := msg.Cid()
+	require.NoError(t, err)
+	require.NotEqual(t, c, c2)
+}
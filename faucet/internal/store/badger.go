@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	badger "github.com/ipfs/go-ds-badger"
+)
+
+// badgerDatastore works around the descending queries of go-ds-badger, which return nothing under a prefix:
+// the reverse iterator is rewound to the last key of the database rather than the last key under the prefix.
+// Descending queries are run in ascending order, and sorted.
+type badgerDatastore struct {
+	*badger.Datastore
+}
+
+var _ datastore.TxnDatastore = badgerDatastore{}
+
+func (d badgerDatastore) Query(ctx context.Context, q query.Query) (query.Results, error) {
+	return descendingQuery(ctx, q, d.Datastore.Query)
+}
+
+func (d badgerDatastore) NewTransaction(ctx context.Context, readOnly bool) (datastore.Txn, error) {
+	txn, err := d.Datastore.NewTransaction(ctx, readOnly)
+	if err != nil {
+		return nil, err
+	}
+	return badgerTxn{txn}, nil
+}
+
+type badgerTxn struct {
+	datastore.Txn
+}
+
+func (t badgerTxn) Query(ctx context.Context, q query.Query) (query.Results, error) {
+	return descendingQuery(ctx, q, t.Txn.Query)
+}
+
+func descendingQuery(ctx context.Context, q query.Query, run func(context.Context, query.Query) (query.Results, error)) (query.Results, error) {
+	if len(q.Orders) == 0 {
+		return run(ctx, q)
+	}
+	switch q.Orders[0].(type) {
+	case query.OrderByKeyDescending, *query.OrderByKeyDescending:
+	default:
+		return run(ctx, q)
+	}
+
+	base := q
+	base.Orders = []query.Order{query.OrderByKey{}}
+	base.Offset = 0
+	base.Limit = 0
+	res, err := run(ctx, base)
+	if err != nil {
+		return nil, err
+	}
+
+	// The prefix and the filters are already applied.
+	naive := q
+	naive.Prefix = ""
+	naive.Filters = nil
+	return query.NaiveQueryApply(naive, query.ResultsReplaceQuery(res, q)), nil
+}
@@ -0,0 +1,322 @@
+//go:build cgo
+
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	// SQLite driver.
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteSchema stores the entries in the kv table, as the datastore keys and the JSON records of the faucet.
+// It is not relational: the views only decode the records with json_extract, so that they can be read with SQL
+// for reporting, and nothing indexes their fields. The key column of a view holds the network and the asset
+// of the record in multi-network mode and for tokens.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS kv (
+	key   TEXT PRIMARY KEY,
+	value BLOB NOT NULL
+) WITHOUT ROWID;
+
+CREATE VIEW IF NOT EXISTS quotas AS
+SELECT
+	key,
+	json_extract(CAST(value AS TEXT), '$.amount')          AS amount,
+	json_extract(CAST(value AS TEXT), '$.latest_transfer') AS latest_transfer,
+	json_extract(CAST(value AS TEXT), '$.last_grant')      AS last_grant,
+	json_extract(CAST(value AS TEXT), '$.last_tx_hash')    AS last_tx_hash
+FROM kv WHERE key LIKE '%:value';
+
+CREATE VIEW IF NOT EXISTS refills AS
+SELECT
+	key,
+	json_extract(CAST(value AS TEXT), '$.time')     AS time,
+	json_extract(CAST(value AS TEXT), '$.treasury') AS treasury,
+	json_extract(CAST(value AS TEXT), '$.account')  AS account,
+	json_extract(CAST(value AS TEXT), '$.balance')  AS balance,
+	json_extract(CAST(value AS TEXT), '$.amount')   AS amount,
+	json_extract(CAST(value AS TEXT), '$.status')   AS status,
+	json_extract(CAST(value AS TEXT), '$.tx_hash')  AS tx_hash,
+	json_extract(CAST(value AS TEXT), '$.error')    AS error
+FROM kv WHERE key LIKE '%/refill/log/%';
+
+CREATE VIEW IF NOT EXISTS rotations AS
+SELECT
+	key,
+	json_extract(CAST(value AS TEXT), '$.time')      AS time,
+	json_extract(CAST(value AS TEXT), '$.completed') AS completed,
+	json_extract(CAST(value AS TEXT), '$.old')       AS old,
+	json_extract(CAST(value AS TEXT), '$.new')       AS new,
+	json_extract(CAST(value AS TEXT), '$.status')    AS status,
+	json_extract(CAST(value AS TEXT), '$.swept')     AS swept,
+	json_extract(CAST(value AS TEXT), '$.tx_hash')   AS tx_hash,
+	json_extract(CAST(value AS TEXT), '$.error')     AS error
+FROM kv WHERE key LIKE '%/rotation/log/%';
+
+CREATE VIEW IF NOT EXISTS vouchers AS
+SELECT
+	key,
+	json_extract(CAST(value AS TEXT), '$.nonce')     AS nonce,
+	json_extract(CAST(value AS TEXT), '$.recipient') AS recipient,
+	json_extract(CAST(value AS TEXT), '$.identity')  AS identity,
+	json_extract(CAST(value AS TEXT), '$.amount')    AS amount,
+	json_extract(CAST(value AS TEXT), '$.granted')   AS granted,
+	json_extract(CAST(value AS TEXT), '$.issued')    AS issued,
+	json_extract(CAST(value AS TEXT), '$.expiry')    AS expiry,
+	json_extract(CAST(value AS TEXT), '$.status')    AS status,
+	json_extract(CAST(value AS TEXT), '$.resolved')  AS resolved
+FROM kv WHERE key LIKE '%/vouchers/%';
+
+CREATE VIEW IF NOT EXISTS drips AS
+SELECT
+	key,
+	json_extract(CAST(value AS TEXT), '$.tx_hash')   AS tx_hash,
+	json_extract(CAST(value AS TEXT), '$.recipient') AS recipient,
+	json_extract(CAST(value AS TEXT), '$.amount')    AS amount,
+	json_extract(CAST(value AS TEXT), '$.status')    AS status,
+	json_extract(CAST(value AS TEXT), '$.sent')      AS sent,
+	json_extract(CAST(value AS TEXT), '$.confirmed') AS confirmed
+FROM kv WHERE key LIKE '%/drips/%';
+
+CREATE VIEW IF NOT EXISTS crossnet_messages AS
+SELECT
+	key,
+	json_extract(CAST(value AS TEXT), '$.tx_hash')   AS tx_hash,
+	json_extract(CAST(value AS TEXT), '$.subnet')    AS subnet,
+	json_extract(CAST(value AS TEXT), '$.recipient') AS recipient,
+	json_extract(CAST(value AS TEXT), '$.amount')    AS amount,
+	json_extract(CAST(value AS TEXT), '$.status')    AS status,
+	json_extract(CAST(value AS TEXT), '$.sent')      AS sent,
+	json_extract(CAST(value AS TEXT), '$.delivered') AS delivered
+FROM kv WHERE key LIKE '%/crossnet/%';
+
+CREATE VIEW IF NOT EXISTS ledger AS
+SELECT
+	key,
+	json_extract(CAST(value AS TEXT), '$.time')      AS time,
+	json_extract(CAST(value AS TEXT), '$.decision')  AS decision,
+	json_extract(CAST(value AS TEXT), '$.reason')    AS reason,
+	json_extract(CAST(value AS TEXT), '$.ip')        AS ip,
+	json_extract(CAST(value AS TEXT), '$.identity')  AS identity,
+	json_extract(CAST(value AS TEXT), '$.recipient') AS recipient,
+	json_extract(CAST(value AS TEXT), '$.asset')     AS asset,
+	json_extract(CAST(value AS TEXT), '$.bundle')    AS bundle,
+	json_extract(CAST(value AS TEXT), '$.amount')    AS amount,
+	json_extract(CAST(value AS TEXT), '$.tx_hash')   AS tx_hash
+FROM kv WHERE key LIKE '%/ledger/entries/%';
+`
+
+// sqliteEnabled reports whether the sqlite backend is built in. The SQLite driver needs cgo.
+const sqliteEnabled = true
+
+func openSQLite(opts Options) (datastore.Batching, error) {
+	ds, err := NewSQLiteDatastore(opts.Path, opts.Readonly)
+	if err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// SQLiteDatastore is a datastore in an SQLite database file.
+type SQLiteDatastore struct {
+	db *sql.DB
+}
+
+var _ datastore.Batching = (*SQLiteDatastore)(nil)
+
+// NewSQLiteDatastore opens the SQLite database at the path, and creates its schema unless it is read-only.
+func NewSQLiteDatastore(file string, readonly bool) (*SQLiteDatastore, error) {
+	dsn := fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL", file)
+	if readonly {
+		dsn = fmt.Sprintf("file:%s?mode=ro", file)
+	}
+
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// SQLite has a single writer. Queries are read entirely before they return, so they don't hold the connection.
+	db.SetMaxOpenConns(1)
+
+	if err := db.Ping(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if !readonly {
+		if _, err := db.Exec(sqliteSchema); err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("failed to create schema: %w", err)
+		}
+	}
+
+	return &SQLiteDatastore{db: db}, nil
+}
+
+func (d *SQLiteDatastore) Get(ctx context.Context, key datastore.Key) ([]byte, error) {
+	var value []byte
+	err := d.db.QueryRowContext(ctx, "SELECT value FROM kv WHERE key = ?", key.String()).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, datastore.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (d *SQLiteDatastore) Has(ctx context.Context, key datastore.Key) (bool, error) {
+	var exists bool
+	err := d.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM kv WHERE key = ?)", key.String()).Scan(&exists)
+	return exists, err
+}
+
+func (d *SQLiteDatastore) GetSize(ctx context.Context, key datastore.Key) (int, error) {
+	var size int
+	err := d.db.QueryRowContext(ctx, "SELECT length(value) FROM kv WHERE key = ?", key.String()).Scan(&size)
+	if errors.Is(err, sql.ErrNoRows) {
+		return -1, datastore.ErrNotFound
+	}
+	if err != nil {
+		return -1, err
+	}
+	return size, nil
+}
+
+func (d *SQLiteDatastore) Put(ctx context.Context, key datastore.Key, value []byte) error {
+	return put(ctx, d.db, key, value)
+}
+
+func (d *SQLiteDatastore) Delete(ctx context.Context, key datastore.Key) error {
+	return remove(ctx, d.db, key)
+}
+
+// Query selects the entries under the prefix of the query in SQL, ordered by key.
+// Filters, orders, offset and limit are applied to the selected entries.
+func (d *SQLiteDatastore) Query(ctx context.Context, q query.Query) (query.Results, error) {
+	columns := "key, value"
+	if q.KeysOnly {
+		columns = "key, length(value)"
+	}
+	stmt := "SELECT " + columns + " FROM kv"
+	var args []any
+
+	prefix := path.Clean("/" + q.Prefix)
+	if prefix != "/" {
+		// The keys under /prefix are between /prefix/ and /prefix0, since 0 follows / in ASCII.
+		stmt += " WHERE key >= ? AND key < ?"
+		args = append(args, prefix+"/", prefix+"0")
+	}
+	stmt += " ORDER BY key"
+
+	rows, err := d.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() // nolint
+
+	var entries []query.Entry
+	for rows.Next() {
+		var entry query.Entry
+		if q.KeysOnly {
+			err = rows.Scan(&entry.Key, &entry.Size)
+		} else {
+			err = rows.Scan(&entry.Key, &entry.Value)
+			entry.Size = len(entry.Value)
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return query.NaiveQueryApply(q, query.ResultsWithEntries(q, entries)), nil
+}
+
+func (d *SQLiteDatastore) Sync(_ context.Context, _ datastore.Key) error {
+	return nil
+}
+
+func (d *SQLiteDatastore) Close() error {
+	return d.db.Close()
+}
+
+// Batch returns a batch written in a single SQLite transaction.
+func (d *SQLiteDatastore) Batch(_ context.Context) (datastore.Batch, error) {
+	return &sqliteBatch{db: d.db}, nil
+}
+
+type sqliteOp struct {
+	key    datastore.Key
+	value  []byte
+	delete bool
+}
+
+type sqliteBatch struct {
+	db  *sql.DB
+	ops []sqliteOp
+}
+
+func (b *sqliteBatch) Put(_ context.Context, key datastore.Key, value []byte) error {
+	b.ops = append(b.ops, sqliteOp{key: key, value: value})
+	return nil
+}
+
+func (b *sqliteBatch) Delete(_ context.Context, key datastore.Key) error {
+	b.ops = append(b.ops, sqliteOp{key: key, delete: true})
+	return nil
+}
+
+func (b *sqliteBatch) Commit(ctx context.Context) error {
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, op := range b.ops {
+		if op.delete {
+			err = remove(ctx, tx, op.key)
+		} else {
+			err = put(ctx, tx, op.key, op.value)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	b.ops = nil
+	return nil
+}
+
+// execer is a database or a transaction.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func put(ctx context.Context, db execer, key datastore.Key, value []byte) error {
+	if value == nil {
+		value = []byte{}
+	}
+	_, err := db.ExecContext(ctx,
+		"INSERT INTO kv (key, value) VALUES (?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value",
+		key.String(), value)
+	return err
+}
+
+func remove(ctx context.Context, db execer, key datastore.Key) error {
+	_, err := db.ExecContext(ctx, "DELETE FROM kv WHERE key = ?", key.String())
+	return err
+}
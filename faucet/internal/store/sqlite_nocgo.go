@@ -0,0 +1,16 @@
+//go:build !cgo
+
+package store
+
+import (
+	"errors"
+
+	"github.com/ipfs/go-datastore"
+)
+
+// sqliteEnabled reports whether the sqlite backend is built in. The SQLite driver needs cgo.
+const sqliteEnabled = false
+
+func openSQLite(Options) (datastore.Batching, error) {
+	return nil, errors.New("the sqlite backend is not built in: it needs cgo (CGO_ENABLED=1)")
+}
@@ -0,0 +1,42 @@
+//go:build cgo
+
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/db"
+)
+
+func TestSQLiteViews(t *testing.T) {
+	ds, err := NewSQLiteDatastore(filepath.Join(t.TempDir(), "faucet.db"), false)
+	require.NoError(t, err)
+	defer ds.Close() // nolint
+
+	ctx := context.Background()
+	faucetDB := db.NewDatabase(namespace.Wrap(ds, datastore.NewKey("calibration")))
+	require.NoError(t, faucetDB.UpdateAddrInfo(ctx, common.HexToAddress(testAddr), data.AddrInfo{Amount: 12, LastTxHash: "0x01"}))
+	require.NoError(t, faucetDB.AddRefillRecord(ctx, data.RefillRecord{Time: time.Now(), Account: testAddr, Amount: 5, Status: "sent"}))
+
+	var amount uint64
+	var txHash string
+	require.NoError(t, ds.db.QueryRow("SELECT amount, last_tx_hash FROM quotas").Scan(&amount, &txHash))
+	require.Equal(t, uint64(12), amount)
+	require.Equal(t, "0x01", txHash)
+
+	var key, account, status string
+	require.NoError(t, ds.db.QueryRow("SELECT key, account, amount, status FROM refills").Scan(&key, &account, &amount, &status))
+	require.Regexp(t, "^/calibration/refill/log/", key)
+	require.Equal(t, testAddr, account)
+	require.Equal(t, uint64(5), amount)
+	require.Equal(t, "sent", status)
+}
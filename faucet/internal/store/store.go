@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	badger "github.com/ipfs/go-ds-badger"
+	leveldb "github.com/ipfs/go-ds-leveldb"
+	ldbopts "github.com/syndtr/goleveldb/leveldb/opt"
+)
+
+// Storage backends of the faucet database.
+const (
+	Memory  = "memory"
+	LevelDB = "leveldb"
+	Badger  = "badger"
+	SQLite  = "sqlite"
+)
+
+// Backends lists the storage backends of the build. The sqlite backend is only built with cgo.
+var Backends = backends()
+
+func backends() []string {
+	b := []string{Memory, LevelDB, Badger}
+	if sqliteEnabled {
+		b = append(b, SQLite)
+	}
+	return b
+}
+
+// Options configure a storage backend.
+// Path is the directory of LevelDB and Badger, and the database file of SQLite. The in-memory backend has no path.
+type Options struct {
+	Path     string
+	Readonly bool
+}
+
+// Open opens the datastore of the backend.
+// The in-memory backend doesn't persist anything, it is meant for tests and ephemeral deployments.
+func Open(backend string, opts Options) (datastore.Batching, error) {
+	switch backend {
+	case Memory:
+		return newMemory(), nil
+	case LevelDB:
+		ds, err := leveldb.NewDatastore(opts.Path, &leveldb.Options{
+			Compression: ldbopts.NoCompression,
+			NoSync:      false,
+			Strict:      ldbopts.StrictAll,
+			ReadOnly:    opts.Readonly,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't initialize leveldb database: %w", err)
+		}
+		return ds, nil
+	case Badger:
+		badgerOpts := badger.DefaultOptions
+		badgerOpts.ReadOnly = opts.Readonly
+		ds, err := badger.NewDatastore(opts.Path, &badgerOpts)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't initialize badger database: %w", err)
+		}
+		return badgerDatastore{ds}, nil
+	case SQLite:
+		ds, err := openSQLite(opts)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't initialize sqlite database: %w", err)
+		}
+		return ds, nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
+
+// memoryDatastore is a map datastore safe for concurrent use.
+// Unlike the batches of MutexDatastore, its batches are written under its lock.
+type memoryDatastore struct {
+	*dssync.MutexDatastore
+}
+
+func newMemory() memoryDatastore {
+	return memoryDatastore{dssync.MutexWrap(datastore.NewMapDatastore())}
+}
+
+func (d memoryDatastore) Batch(_ context.Context) (datastore.Batch, error) {
+	return datastore.NewBasicBatch(d), nil
+}
@@ -0,0 +1,248 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	dstest "github.com/ipfs/go-datastore/test"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/db"
+)
+
+const testAddr = "0x4592d8f8d7b001e72cb26a73e4fa1806a51ac79d"
+
+func init() {
+	// The persistent backends sync every write, the smallest count of the suite keeps it short.
+	dstest.ElemCount = 20
+}
+
+// TestConformance runs the datastore test suite and the faucet database against every backend.
+func TestConformance(t *testing.T) {
+	for _, backend := range Backends {
+		backend := backend
+		t.Run(backend, func(t *testing.T) {
+			t.Parallel()
+			opts := Options{Path: filepath.Join(t.TempDir(), "db")}
+
+			ds, err := Open(backend, opts)
+			require.NoError(t, err)
+
+			dstest.SubtestAll(t, ds)
+
+			ctx := context.Background()
+			addr := common.HexToAddress(testAddr)
+			info := data.AddrInfo{Amount: 12, LatestTransfer: time.Now().UTC().Round(0)}
+			record := data.RefillRecord{Time: time.Now().UTC().Round(0), Account: testAddr, Amount: 5, Status: "sent"}
+
+			faucetDB := db.NewDatabase(namespace.Wrap(ds, datastore.NewKey("calibration")))
+			require.NoError(t, faucetDB.UpdateAddrInfo(ctx, addr, info))
+			require.NoError(t, faucetDB.AddRefillRecord(ctx, record))
+			require.NoError(t, ds.Close())
+
+			if backend == Memory {
+				return
+			}
+
+			// The entries persist, and a read-only database can't be written.
+			ds, err = Open(backend, Options{Path: opts.Path, Readonly: true})
+			require.NoError(t, err)
+			defer ds.Close() // nolint
+
+			faucetDB = db.NewDatabase(namespace.Wrap(ds, datastore.NewKey("calibration")))
+			got, err := faucetDB.GetAddrInfo(ctx, addr)
+			require.NoError(t, err)
+			require.Equal(t, info, got)
+
+			records, err := faucetDB.GetRefillRecords(ctx)
+			require.NoError(t, err)
+			require.Equal(t, []data.RefillRecord{record}, records)
+
+			require.Error(t, faucetDB.UpdateAddrInfo(ctx, addr, data.AddrInfo{}))
+		})
+	}
+}
+
+var errCrash = errors.New("crash")
+
+// accounting returns the writes of a grant: the quota of the address, the global quota and the ledger entry.
+func accounting(amount uint64) []func(ctx context.Context, tx *db.Database) error {
+	addr := common.HexToAddress(testAddr)
+	return []func(ctx context.Context, tx *db.Database) error{
+		func(ctx context.Context, tx *db.Database) error {
+			return tx.UpdateAddrInfo(ctx, addr, data.AddrInfo{Amount: amount})
+		},
+		func(ctx context.Context, tx *db.Database) error {
+			return tx.UpdateTotalInfo(ctx, data.TotalInfo{Amount: amount})
+		},
+		func(ctx context.Context, tx *db.Database) error {
+			return tx.AddLedgerEntry(ctx, data.LedgerEntry{
+				ID:        db.LedgerID(time.Now(), uint32(amount)),
+				Recipient: testAddr,
+				Amount:    amount,
+			})
+		},
+	}
+}
+
+// requireAccounting checks that the quotas hold the amount, and that the ledger holds the amounts.
+func requireAccounting(t *testing.T, d *db.Database, amount uint64, ledger ...uint64) {
+	ctx := context.Background()
+
+	info, err := d.GetAddrInfo(ctx, common.HexToAddress(testAddr))
+	require.NoError(t, err)
+	require.Equal(t, amount, info.Amount)
+
+	total, err := d.GetTotalInfo(ctx)
+	require.NoError(t, err)
+	require.Equal(t, amount, total.Amount)
+
+	entries, _, err := d.GetLedgerEntries(ctx, data.LedgerFilter{Address: testAddr, Limit: 10})
+	require.NoError(t, err)
+	amounts := make([]uint64, 0, len(entries))
+	for _, e := range entries {
+		amounts = append(amounts, e.Amount)
+	}
+	require.ElementsMatch(t, ledger, amounts)
+}
+
+// databases returns the faucet database of the store, updated in transactions if the store supports them,
+// and of the namespaced store, updated in batches.
+func databases(ds datastore.Batching) map[string]*db.Database {
+	return map[string]*db.Database{
+		"store":     db.NewDatabase(ds),
+		"namespace": db.NewDatabase(namespace.Wrap(ds, datastore.NewKey("calibration"))),
+	}
+}
+
+// TestUpdate checks that an update failing or panicking after any of its writes leaves the database unchanged.
+func TestUpdate(t *testing.T) {
+	for _, backend := range Backends {
+		backend := backend
+		t.Run(backend, func(t *testing.T) {
+			t.Parallel()
+
+			ds, err := Open(backend, Options{Path: filepath.Join(t.TempDir(), "db")})
+			require.NoError(t, err)
+			defer ds.Close() // nolint
+
+			for name, d := range databases(ds) {
+				ctx := context.Background()
+				writes := accounting(10)
+
+				for crash := 0; crash <= len(writes); crash++ {
+					err := d.Update(ctx, func(tx *db.Database) error {
+						for _, write := range writes[:crash] {
+							require.NoError(t, write(ctx, tx))
+						}
+						return errCrash
+					})
+					require.ErrorIs(t, err, errCrash, name)
+					requireAccounting(t, d, 0)
+				}
+
+				require.Panics(t, func() {
+					_ = d.Update(ctx, func(tx *db.Database) error {
+						for _, write := range writes {
+							require.NoError(t, write(ctx, tx))
+						}
+						panic(errCrash)
+					})
+				}, name)
+				requireAccounting(t, d, 0)
+
+				err := d.Update(ctx, func(tx *db.Database) error {
+					for _, write := range writes {
+						if err := write(ctx, tx); err != nil {
+							return err
+						}
+					}
+					// The update reads its own writes.
+					info, err := tx.GetAddrInfo(ctx, common.HexToAddress(testAddr))
+					require.NoError(t, err)
+					require.Equal(t, uint64(10), info.Amount)
+					return nil
+				})
+				require.NoError(t, err, name)
+				requireAccounting(t, d, 10, 10)
+			}
+		})
+	}
+}
+
+// TestUpdateCrash kills a process in the middle of an update, and checks that the database holds the previous update.
+func TestUpdateCrash(t *testing.T) {
+	if backend := os.Getenv("FAUCET_CRASH_BACKEND"); backend != "" {
+		crashUpdate(backend, os.Getenv("FAUCET_CRASH_PATH"), os.Getenv("FAUCET_CRASH_DATABASE"))
+		return
+	}
+
+	for _, backend := range Backends {
+		if backend == Memory {
+			continue
+		}
+		for _, name := range []string{"store", "namespace"} {
+			backend, name := backend, name
+			t.Run(backend+"/"+name, func(t *testing.T) {
+				t.Parallel()
+				path := filepath.Join(t.TempDir(), "db")
+
+				cmd := exec.Command(os.Args[0], "-test.run=^TestUpdateCrash$")
+				cmd.Env = append(os.Environ(),
+					"FAUCET_CRASH_BACKEND="+backend, "FAUCET_CRASH_PATH="+path, "FAUCET_CRASH_DATABASE="+name)
+				out, err := cmd.CombinedOutput()
+				var exitErr *exec.ExitError
+				require.ErrorAs(t, err, &exitErr, string(out))
+				require.Equal(t, crashExitCode, exitErr.ExitCode(), string(out))
+
+				// Badger replays its log after a crash, which a read-only database can't.
+				ds, err := Open(backend, Options{Path: path})
+				require.NoError(t, err)
+				defer ds.Close() // nolint
+
+				requireAccounting(t, databases(ds)[name], 10, 10)
+			})
+		}
+	}
+}
+
+const crashExitCode = 3
+
+// crashUpdate commits an update, and exits in the middle of the next one without closing the store.
+func crashUpdate(backend, path, name string) {
+	ds, err := Open(backend, Options{Path: path})
+	if err != nil {
+		panic(err)
+	}
+	d := databases(ds)[name]
+	ctx := context.Background()
+
+	update := func(amount uint64, crash int) error {
+		return d.Update(ctx, func(tx *db.Database) error {
+			for i, write := range accounting(amount) {
+				if i == crash {
+					os.Exit(crashExitCode)
+				}
+				if err := write(ctx, tx); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+
+	if err := update(10, -1); err != nil {
+		panic(err)
+	}
+	_ = update(20, 2)
+	panic("not crashed")
+}
@@ -0,0 +1,57 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+)
+
+func Test_StrictAddressValidation(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 20,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		RequireChecksum:      true,
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	for addr, reason := range map[string]string{
+		"0xffcf8fdee72ac11b5c542428b35eef5769c409zz": "invalid hex character 'z' at position 40",
+		"0xFFcf8FDEE72ac11b5c542428B35EEF5769C409":   "expected 40 hex characters, got 38",
+		strings.ToLower(TestAddr2):                   "not EIP-55 checksummed",
+		"f" + FilecoinTestAddr1[1:]:                  "doesn't match the network prefix",
+		"0x0000000000000000000000000000000000000004": "precompile address",
+		"t010": "reserved built-in actor f010",
+	} {
+		w := post(t, srv, "/fund", data.FundRequest{Address: addr})
+		require.Equal(t, http.StatusBadRequest, w.Code, addr)
+		require.Contains(t, w.Body.String(), reason, addr)
+
+		r := httptest.NewRequest(http.MethodGet, "/accounts/"+addr, nil)
+		w = httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		require.Equal(t, http.StatusBadRequest, w.Code, addr)
+	}
+
+	w := post(t, srv, "/fund", data.FundRequest{Address: TestAddr2})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = post(t, srv, "/fund", data.FundRequest{Address: FilecoinTestAddr1})
+	require.Equal(t, http.StatusCreated, w.Code)
+}
@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	faucetDB "github.com/consensus-shipyard/calibration/faucet/internal/db"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+)
+
+// revertingRuntime rejects every call.
+const revertingRuntime = `
+	PUSH 0
+	DUP1
+	REVERT
+`
+
+func Test_BundleFaucet(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+
+	token := deployMockERC20(t, sim, account, faucet.TokenAmount(1_000_000, mockTokenDecimals))
+	broken := deployContract(t, sim, account.PrivateKey, deployCode(t, "", compileAsm(t, revertingRuntime)), nil)
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 50,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		Tokens: []faucet.TokenConfig{{
+			Symbol:               mockTokenSymbol,
+			Address:              token,
+			Decimals:             mockTokenDecimals,
+			TransferAmount:       100,
+			AddressTransferLimit: 200,
+			TotalTransferLimit:   1000,
+		}, {
+			Symbol:               "BROKEN",
+			Address:              broken,
+			Decimals:             18,
+			TransferAmount:       1,
+			AddressTransferLimit: 10,
+			TotalTransferLimit:   100,
+		}},
+		Bundles: []faucet.BundleConfig{{
+			Name: "starter",
+			Legs: []faucet.BundleLeg{{Asset: faucet.NativeAsset, Amount: 5}, {Asset: mockTokenSymbol}},
+		}, {
+			Name: "partial",
+			Legs: []faucet.BundleLeg{{Asset: faucet.NativeAsset}, {Asset: "BROKEN"}},
+		}, {
+			Name: "large",
+			Legs: []faucet.BundleLeg{{Asset: faucet.NativeAsset, Amount: 60}},
+		}, {
+			Name: "double",
+			Legs: []faucet.BundleLeg{{Asset: faucet.NativeAsset, Amount: 30}, {Asset: faucet.NativeAsset, Amount: 30}},
+		}},
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+	db := faucetDB.NewDatabase(store)
+
+	t.Run("fullBundle", func(t *testing.T) {
+		recipient := common.HexToAddress(TestAddr3)
+		oldBalance, err := sim.BalanceAt(context.Background(), recipient, nil)
+		require.NoError(t, err)
+
+		w := fundBundle(t, srv, TestAddr3, "starter")
+		require.Equal(t, http.StatusCreated, w.Code)
+		sim.Commit()
+
+		var resp data.BundleResponse
+		err = json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Len(t, resp.Legs, 2)
+		for _, leg := range resp.Legs {
+			require.Equal(t, faucet.LegSent, leg.Status)
+			require.NotEmpty(t, leg.TxHash)
+		}
+
+		newBalance, err := sim.BalanceAt(context.Background(), recipient, nil)
+		require.NoError(t, err)
+		require.Equal(t, new(big.Int).Add(oldBalance, faucet.TransferAmount(5)), newBalance)
+		require.Equal(t, faucet.TokenAmount(100, mockTokenDecimals), tokenBalance(t, sim, token, recipient))
+
+		require.Equal(t, uint64(5), accountInfo(t, srv, TestAddr3, "").Received)
+		require.Equal(t, uint64(100), accountInfo(t, srv, TestAddr3, mockTokenSymbol).Received)
+	})
+
+	t.Run("partialBundle", func(t *testing.T) {
+		w := fundBundle(t, srv, TestAddr4, "partial")
+		require.Equal(t, http.StatusMultiStatus, w.Code)
+		sim.Commit()
+
+		var resp data.BundleResponse
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Len(t, resp.Legs, 2)
+		require.Equal(t, faucet.LegSent, resp.Legs[0].Status)
+		require.Equal(t, faucet.LegFailed, resp.Legs[1].Status)
+		require.NotEmpty(t, resp.Legs[1].Error)
+
+		// The quota of the failed leg is credited back.
+		require.Equal(t, uint64(10), accountInfo(t, srv, TestAddr4, "").Received)
+		addrInfo, err := db.GetAssetAddrInfo(context.Background(), "broken", common.HexToAddress(TestAddr4))
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), addrInfo.Amount)
+		totalInfo, err := db.GetAssetTotalInfo(context.Background(), "broken")
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), totalInfo.Amount)
+	})
+
+	t.Run("bundleOverQuota", func(t *testing.T) {
+		w := fundBundle(t, srv, TestAddr3, "starter")
+		require.Equal(t, http.StatusCreated, w.Code)
+		sim.Commit()
+
+		// The token leg is exhausted, so nothing is sent.
+		w = fundBundle(t, srv, TestAddr3, "starter")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		require.Equal(t, uint64(10), accountInfo(t, srv, TestAddr3, "").Received)
+	})
+
+	t.Run("legsOverQuota", func(t *testing.T) {
+		// A leg larger than the quota, and legs of an asset exceeding it together, are rejected.
+		for _, bundle := range []string{"large", "double"} {
+			w := fundBundle(t, srv, TestAddr2, bundle)
+			require.Equal(t, http.StatusTooManyRequests, w.Code, bundle)
+		}
+		require.Equal(t, uint64(0), accountInfo(t, srv, TestAddr2, "").Received)
+	})
+
+	t.Run("unknownBundle", func(t *testing.T) {
+		w := fundBundle(t, srv, TestAddr3, "unknown")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+func fundBundle(t *testing.T, h http.Handler, addr, bundle string) *httptest.ResponseRecorder {
+	body, err := json.Marshal(&data.FundRequest{Address: addr, Bundle: bundle})
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, "/fund", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+	return w
+}
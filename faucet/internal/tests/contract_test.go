@@ -0,0 +1,227 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/contract"
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+)
+
+func Test_FaucetContract(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+
+	owner, err := bind.NewKeyedTransactorWithChainID(account.PrivateKey, simulatedChainID)
+	require.NoError(t, err)
+
+	// The contract allows 10 coins per drip and 15 coins per recipient in a day.
+	addr, _, faucetContract, err := contract.DeployFaucet(owner, sim,
+		faucet.TransferAmount(10), big.NewInt(int64(24*time.Hour/time.Second)), faucet.TransferAmount(15))
+	require.NoError(t, err)
+	sim.Commit()
+
+	owner.Value = faucet.TransferAmount(25)
+	_, err = faucetContract.Receive(owner)
+	require.NoError(t, err)
+	sim.Commit()
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 100,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		Contract: &faucet.ContractConfig{
+			Address:      addr,
+			PollInterval: 10 * time.Millisecond,
+			Timeout:      5 * time.Second,
+		},
+		Bundles: []faucet.BundleConfig{{
+			Name: "starter",
+			Legs: []faucet.BundleLeg{{Asset: faucet.NativeAsset, Amount: 5}},
+		}},
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	drip := func(txHash string) data.DripRecord {
+		r := httptest.NewRequest(http.MethodGet, "/drips/"+txHash, nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var rec data.DripRecord
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rec))
+		return rec
+	}
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	to := crypto.PubkeyToAddress(key.PublicKey)
+
+	w := post(t, srv, "/fund", data.FundRequest{Address: to.Hex()})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var resp data.FundResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	rec := drip(resp.TxHash)
+	require.Equal(t, faucet.DripPending, rec.Status)
+	require.Equal(t, addr.Hex(), rec.Contract)
+	require.Equal(t, to.Hex(), rec.Recipient)
+
+	sim.Commit()
+
+	// The drip is confirmed by its event.
+	require.Eventually(t, func() bool {
+		return drip(resp.TxHash).Status == faucet.DripConfirmed
+	}, 5*time.Second, 10*time.Millisecond)
+	rec = drip(resp.TxHash)
+	require.NotNil(t, rec.Confirmed)
+	require.NotZero(t, rec.Block)
+
+	balance, err := sim.BalanceAt(context.Background(), to, nil)
+	require.NoError(t, err)
+	require.Equal(t, faucet.TransferAmount(10), balance)
+
+	// The funds come from the contract, the funding account only pays for gas.
+	balance, err = sim.BalanceAt(context.Background(), addr, nil)
+	require.NoError(t, err)
+	require.Equal(t, faucet.TransferAmount(15), balance)
+
+	// The contract limit is stricter than the quota of the faucet.
+	w = post(t, srv, "/fund", data.FundRequest{Address: to.Hex()})
+	require.Equal(t, http.StatusTooManyRequests, w.Code, w.Body.String())
+
+	info := accountInfo(t, srv, to.Hex(), "")
+	require.Equal(t, uint64(10), info.Received)
+
+	// The native coin of a bundle is dripped by the contract too.
+	w = fundBundle(t, srv, TestAddr3, "starter")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var bundle data.BundleResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bundle))
+	require.Len(t, bundle.Legs, 1)
+	rec = drip(bundle.Legs[0].TxHash)
+	require.Equal(t, common.HexToAddress(TestAddr3).Hex(), rec.Recipient)
+	sim.Commit()
+
+	balance, err = sim.BalanceAt(context.Background(), addr, nil)
+	require.NoError(t, err)
+	require.Equal(t, faucet.TransferAmount(10), balance)
+
+	// A drained contract makes the faucet unavailable.
+	owner.Value = nil
+	_, err = faucetContract.Withdraw(owner, account.Address, faucet.TransferAmount(10))
+	require.NoError(t, err)
+	sim.Commit()
+
+	w = post(t, srv, "/fund", data.FundRequest{Address: TestAddr2})
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+
+	r := httptest.NewRequest(http.MethodGet, "/drips/"+common.Hash{}.Hex(), nil)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, r)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_FaucetContractDripRecovery(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+
+	owner, err := bind.NewKeyedTransactorWithChainID(account.PrivateKey, simulatedChainID)
+	require.NoError(t, err)
+
+	addr, _, faucetContract, err := contract.DeployFaucet(owner, sim,
+		faucet.TransferAmount(10), big.NewInt(int64(24*time.Hour/time.Second)), faucet.TransferAmount(100))
+	require.NoError(t, err)
+	sim.Commit()
+
+	owner.Value = faucet.TransferAmount(100)
+	_, err = faucetContract.Receive(owner)
+	require.NoError(t, err)
+	sim.Commit()
+
+	contractCfg := faucet.ContractConfig{
+		Address: addr,
+		// The first run doesn't poll before it stops, so the drip is left pending.
+		PollInterval: time.Hour,
+		Timeout:      time.Hour,
+	}
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 100,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		Contract:             &contractCfg,
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	ctx, stop := context.WithCancel(context.Background())
+	srv := handler.FaucetHandler(ctx, logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	drip := func(txHash string) data.DripRecord {
+		r := httptest.NewRequest(http.MethodGet, "/drips/"+txHash, nil)
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var rec data.DripRecord
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rec))
+		return rec
+	}
+	fund := func(addr string) string {
+		w := post(t, srv, "/fund", data.FundRequest{Address: addr})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var resp data.FundResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		return resp.TxHash
+	}
+
+	txHash := fund(TestAddr2)
+	sim.Commit()
+
+	stop()
+
+	// The next run confirms the drip left pending.
+	restarted := cfg
+	restartedContract := contractCfg
+	restartedContract.PollInterval = 10 * time.Millisecond
+	restartedContract.Timeout = time.Second
+	restarted.Contract = &restartedContract
+	srv = handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &restarted)
+
+	require.Eventually(t, func() bool {
+		return drip(txHash).Status == faucet.DripConfirmed
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, uint64(10), accountInfo(t, srv, TestAddr2, "").Received)
+
+	// A drip that is never mined fails, and its amount is credited back to the quota.
+	txHash = fund(TestAddr3)
+	require.Equal(t, uint64(10), accountInfo(t, srv, TestAddr3, "").Received)
+
+	require.Eventually(t, func() bool {
+		return drip(txHash).Status == faucet.DripFailed
+	}, 5*time.Second, 10*time.Millisecond)
+	rec := drip(txHash)
+	require.True(t, rec.Credited)
+	require.Equal(t, uint64(0), accountInfo(t, srv, TestAddr3, "").Received)
+}
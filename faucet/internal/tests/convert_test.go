@@ -0,0 +1,142 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+	"github.com/consensus-shipyard/calibration/faucet/internal/lotus"
+	ftypes "github.com/consensus-shipyard/calibration/faucet/internal/types"
+)
+
+func Test_ConvertAddress(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+	lotusNode, lotusSrv := newFakeLotus(t)
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 20,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		Filecoin:             lotus.NewClient(lotusSrv.URL, fakeLotusToken),
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	ethAddr := common.HexToAddress(TestAddr1)
+	f4, err := ftypes.EthAddress(ethAddr).ToFilecoinAddress()
+	require.NoError(t, err)
+	f4Mainnet := ftypes.FilecoinAddressString(f4, address.MainnetPrefix)
+	f4Testnet := ftypes.FilecoinAddressString(f4, address.TestnetPrefix)
+
+	id, err := address.NewIDAddress(100)
+	require.NoError(t, err)
+	masked, err := ftypes.EthAddressFromFilecoinAddress(id)
+	require.NoError(t, err)
+	maskedAddr := common.Address(masked)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	f1, err := address.NewSecp256k1Address(crypto.FromECDSAPub(&key.PublicKey))
+	require.NoError(t, err)
+	f1ID := lotusNode.addActor(f1, 1234)
+	f1Masked, err := ftypes.EthAddressFromFilecoinAddress(f1ID)
+	require.NoError(t, err)
+
+	unknownKey, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	unknownF1, err := address.NewSecp256k1Address(crypto.FromECDSAPub(&unknownKey.PublicKey))
+	require.NoError(t, err)
+
+	t.Run("ethereum", func(t *testing.T) {
+		resp := convert(t, srv, "/convert/"+TestAddr1)
+		require.Equal(t, "ethereum", resp.Type)
+		require.Equal(t, ethAddr.Hex(), resp.Ethereum)
+		require.Equal(t, f4Mainnet, resp.Mainnet)
+		require.Equal(t, f4Testnet, resp.Testnet)
+		require.Equal(t, f4Testnet, resp.Filecoin)
+		require.Empty(t, resp.ID)
+	})
+
+	t.Run("delegated", func(t *testing.T) {
+		resp := convert(t, srv, "/convert/"+f4Mainnet)
+		require.Equal(t, "delegated", resp.Type)
+		require.Equal(t, ethAddr.Hex(), resp.Ethereum)
+		require.Equal(t, f4Testnet, resp.Filecoin)
+	})
+
+	t.Run("id", func(t *testing.T) {
+		resp := convert(t, srv, "/convert/f0100")
+		require.Equal(t, "id", resp.Type)
+		require.Equal(t, maskedAddr.Hex(), resp.Ethereum)
+		require.Equal(t, maskedAddr.Hex(), resp.MaskedID)
+		require.Equal(t, "t0100", resp.ID)
+		require.Equal(t, "f0100", resp.Mainnet)
+
+		resp = convert(t, srv, "/convert/"+maskedAddr.Hex())
+		require.Equal(t, "ethereum", resp.Type)
+		require.Equal(t, "t0100", resp.ID)
+	})
+
+	t.Run("secp256k1", func(t *testing.T) {
+		resp := convert(t, srv, "/convert/"+f1.String())
+		require.Equal(t, "secp256k1", resp.Type)
+		require.Empty(t, resp.Ethereum)
+		require.Equal(t, ftypes.FilecoinAddressString(f1, address.TestnetPrefix), resp.Filecoin)
+		require.Equal(t, "t01234", resp.ID)
+		require.Equal(t, common.Address(f1Masked).Hex(), resp.MaskedID)
+
+		resp = convert(t, srv, "/convert/"+unknownF1.String())
+		require.Empty(t, resp.ID)
+		require.Empty(t, resp.MaskedID)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, addr := range []string{"x1234", "f9abc", "f1abc"} {
+			r := httptest.NewRequest(http.MethodGet, "/convert/"+addr, nil)
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, r)
+			require.Equal(t, http.StatusBadRequest, w.Code, addr)
+		}
+	})
+
+	t.Run("networks", func(t *testing.T) {
+		h := handler.NetworksHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), []handler.Network{
+			{Name: "parent", Client: sim, Config: &cfg},
+		}, store, "0.0.1", nil, "", handler.Proxies{})
+
+		resp := convert(t, h, "/convert/"+TestAddr1)
+		require.Empty(t, resp.Filecoin)
+		require.Equal(t, f4Testnet, resp.Testnet)
+
+		resp = convert(t, h, "/parent/convert/"+TestAddr1)
+		require.Equal(t, f4Testnet, resp.Filecoin)
+	})
+}
+
+func convert(t *testing.T, h http.Handler, path string) data.ConvertResponse {
+	r := httptest.NewRequest(http.MethodGet, path, nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp data.ConvertResponse
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	require.NoError(t, err)
+	return resp
+}
@@ -0,0 +1,382 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+	ftypes "github.com/consensus-shipyard/calibration/faucet/internal/types"
+)
+
+var gatewayFundedTopic = crypto.Keccak256Hash([]byte("Funded(uint256,bytes)"))
+
+// mockGatewayRuntime accepts every call and logs the call value followed by the call data.
+// It then emits the NewTopDownMessage event of the template that follows the code,
+// with the nonce counted in slot 0.
+var mockGatewayRuntime = fmt.Sprintf(`
+	CALLDATASIZE
+	PUSH 0
+	PUSH 32
+	CALLDATACOPY
+	CALLVALUE
+	PUSH 0
+	MSTORE
+	PUSH %s
+	CALLDATASIZE
+	PUSH 32
+	ADD
+	PUSH 0
+	LOG1
+
+	PUSH %d
+	PUSH @template
+	PUSH 1
+	ADD
+	PUSH 0
+	CODECOPY
+	PUSH 0
+	SLOAD
+	DUP1
+	PUSH %d
+	MSTORE
+	PUSH 1
+	ADD
+	PUSH 0
+	SSTORE
+	PUSH 0
+	PUSH %s
+	PUSH %d
+	PUSH 0
+	LOG2
+	STOP
+template:
+`, gatewayFundedTopic.Hex(), len(topDownTemplate), topDownNonceOffset, topDownMessageTopic.Hex(), len(topDownTemplate))
+
+// mockChildGatewayRuntime returns the applied top-down nonce in slot 0 to calls with data,
+// and applies the next top-down message on calls without data.
+const mockChildGatewayRuntime = `
+	CALLDATASIZE
+	PUSH @get
+	JUMPI
+	PUSH 0
+	SLOAD
+	PUSH 1
+	ADD
+	PUSH 0
+	SSTORE
+	STOP
+get:
+	PUSH 0
+	SLOAD
+	PUSH 0
+	MSTORE
+	PUSH 32
+	PUSH 0
+	RETURN
+`
+
+var topDownMessageTopic = crypto.Keccak256Hash([]byte(
+	"NewTopDownMessage(address,(uint8,((uint64,address[]),(uint8,bytes)),((uint64,address[]),(uint8,bytes)),uint64,uint256,bytes))",
+))
+
+// topDownNonceOffset is the offset of the nonce in the data of the NewTopDownMessage event:
+// it follows the offset of the envelope, its kind and the offsets of its addresses.
+const topDownNonceOffset = 4 * 32
+
+// topDownTemplate is the data of a NewTopDownMessage event with nonce 0.
+var topDownTemplate = func() []byte {
+	type ipcAddress struct {
+		SubnetId struct {
+			Root  uint64
+			Route []common.Address
+		}
+		RawAddress struct {
+			AddrType uint8
+			Payload  []byte
+		}
+	}
+	envelopeType, err := abi.NewType("tuple", "", []abi.ArgumentMarshaling{
+		{Name: "kind", Type: "uint8"},
+		{Name: "to", Type: "tuple", Components: ipcAddressComponents},
+		{Name: "from", Type: "tuple", Components: ipcAddressComponents},
+		{Name: "nonce", Type: "uint64"},
+		{Name: "value", Type: "uint256"},
+		{Name: "message", Type: "bytes"},
+	})
+	if err != nil {
+		panic(err)
+	}
+	out, err := abi.Arguments{{Type: envelopeType}}.Pack(struct {
+		Kind    uint8
+		To      ipcAddress
+		From    ipcAddress
+		Nonce   uint64
+		Value   *big.Int
+		Message []byte
+	}{Value: new(big.Int), Message: []byte{}})
+	if err != nil {
+		panic(err)
+	}
+	return out
+}()
+
+var ipcAddressComponents = []abi.ArgumentMarshaling{
+	{Name: "subnetId", Type: "tuple", Components: []abi.ArgumentMarshaling{
+		{Name: "root", Type: "uint64"},
+		{Name: "route", Type: "address[]"},
+	}},
+	{Name: "rawAddress", Type: "tuple", Components: []abi.ArgumentMarshaling{
+		{Name: "addrType", Type: "uint8"},
+		{Name: "payload", Type: "bytes"},
+	}},
+}
+
+const gatewayFundABI = `[{"type":"function","name":"fund","stateMutability":"payable","inputs":[
+	{"name":"subnetId","type":"tuple","components":[{"name":"root","type":"uint64"},{"name":"route","type":"address[]"}]},
+	{"name":"to","type":"tuple","components":[{"name":"addrType","type":"uint8"},{"name":"payload","type":"bytes"}]}],
+	"outputs":[]}]`
+
+const testSubnetID = "/r314159/t410f77hy7xxhflarwxcuequlgxxpk5u4icpqb4cl5ha"
+
+func Test_CrossNetFaucet(t *testing.T) {
+	parent, account := newSimulatedChain(t)
+	child, _ := newSimulatedChain(t)
+	gateway, childGateway := deployMockGateways(t, parent, child, account.PrivateKey)
+
+	subnet, err := ftypes.ParseSubnetID(testSubnetID)
+	require.NoError(t, err)
+	require.Equal(t, testSubnetID, subnet.String())
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 50,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		CrossNet: &faucet.CrossNetConfig{
+			Gateway:      gateway,
+			ChildGateway: childGateway,
+			Subnet:       subnet,
+			Child:        child,
+			PollInterval: 10 * time.Millisecond,
+			Timeout:      10 * time.Second,
+		},
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), parent, store, "0.0.1", &cfg)
+
+	w := fundAsset(t, srv, TestAddr2, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	parent.Commit()
+
+	var resp data.FundResponse
+	err = json.Unmarshal(w.Body.Bytes(), &resp)
+	require.NoError(t, err)
+
+	gatewayBalance, err := parent.BalanceAt(context.Background(), gateway, nil)
+	require.NoError(t, err)
+	require.Equal(t, faucet.TransferAmount(10), gatewayBalance)
+
+	msg := crossNetMessage(t, srv, resp.TxHash)
+	require.Equal(t, faucet.CrossNetPending, msg.Status)
+	require.Equal(t, testSubnetID, msg.Subnet)
+
+	// The nonce of the message is read from the event of the gateway.
+	require.Eventually(t, func() bool {
+		return crossNetMessage(t, srv, resp.TxHash).Nonce != nil
+	}, 5*time.Second, 20*time.Millisecond)
+	require.Equal(t, uint64(0), *crossNetMessage(t, srv, resp.TxHash).Nonce)
+
+	// Other transfers to the recipient on the child don't deliver the message.
+	sendValue(t, child, account.PrivateKey, common.HexToAddress(TestAddr2), faucet.TransferAmount(10))
+	require.Never(t, func() bool {
+		return crossNetMessage(t, srv, resp.TxHash).Status != faucet.CrossNetPending
+	}, 200*time.Millisecond, 20*time.Millisecond)
+
+	relayTopDown(t, parent, child, account.PrivateKey, gateway, childGateway, subnet)
+
+	require.Eventually(t, func() bool {
+		return crossNetMessage(t, srv, resp.TxHash).Status == faucet.CrossNetDelivered
+	}, 5*time.Second, 20*time.Millisecond)
+
+	balance, err := child.BalanceAt(context.Background(), common.HexToAddress(TestAddr2), nil)
+	require.NoError(t, err)
+	require.Equal(t, faucet.TransferAmount(20), balance)
+
+	r := httptest.NewRequest(http.MethodGet, "/crossnet/"+common.Hash{}.Hex(), nil)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, r)
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func Test_CrossNetResume(t *testing.T) {
+	parent, account := newSimulatedChain(t)
+	child, _ := newSimulatedChain(t)
+	gateway, childGateway := deployMockGateways(t, parent, child, account.PrivateKey)
+
+	subnet, err := ftypes.ParseSubnetID(testSubnetID)
+	require.NoError(t, err)
+
+	crossNet := faucet.CrossNetConfig{
+		Gateway:      gateway,
+		ChildGateway: childGateway,
+		Subnet:       subnet,
+		Child:        child,
+		// The first run doesn't poll before it stops, so the message is left pending.
+		PollInterval: time.Hour,
+		Timeout:      time.Hour,
+	}
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 50,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		CrossNet:             &crossNet,
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	ctx, stop := context.WithCancel(context.Background())
+	srv := handler.FaucetHandler(ctx, logging.Logger("TEST-FAUCET"), parent, store, "0.0.1", &cfg)
+
+	w := fundAsset(t, srv, TestAddr2, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	parent.Commit()
+
+	var resp data.FundResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	relayTopDown(t, parent, child, account.PrivateKey, gateway, childGateway, subnet)
+
+	stop()
+
+	// The next run tracks the message left pending.
+	restarted := cfg
+	restartedCrossNet := crossNet
+	restartedCrossNet.PollInterval = 10 * time.Millisecond
+	restartedCrossNet.Timeout = 10 * time.Second
+	restarted.CrossNet = &restartedCrossNet
+	srv = handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), parent, store, "0.0.1", &restarted)
+
+	require.Eventually(t, func() bool {
+		return crossNetMessage(t, srv, resp.TxHash).Status == faucet.CrossNetDelivered
+	}, 5*time.Second, 20*time.Millisecond)
+}
+
+// deployMockGateways deploys the mock gateways of the parent and of the child subnet.
+func deployMockGateways(t *testing.T, parent, child *backends.SimulatedBackend, key *ecdsa.PrivateKey) (common.Address, common.Address) {
+	runtime := append(compileAsm(t, mockGatewayRuntime), topDownTemplate...)
+	gateway := deployContract(t, parent, key, deployCode(t, "", runtime), nil)
+	childGateway := deployContract(t, child, key, deployCode(t, "", compileAsm(t, mockChildGatewayRuntime)), nil)
+	return gateway, childGateway
+}
+
+// relayTopDown plays the role of the IPC relayer: it decodes the gateway calls on the parent,
+// credits the recipients on the child and applies the messages on the child gateway.
+func relayTopDown(t *testing.T, parent, child *backends.SimulatedBackend, key *ecdsa.PrivateKey, gateway, childGateway common.Address, subnet ftypes.SubnetID) {
+	logs, err := parent.FilterLogs(context.Background(), ethereum.FilterQuery{
+		Addresses: []common.Address{gateway},
+		Topics:    [][]common.Hash{{gatewayFundedTopic}},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, logs)
+
+	gatewayABI, err := abi.JSON(strings.NewReader(gatewayFundABI))
+	require.NoError(t, err)
+
+	for _, l := range logs {
+		value := new(big.Int).SetBytes(l.Data[:32])
+		input := l.Data[32:]
+
+		var args struct {
+			SubnetId struct {
+				Root  uint64
+				Route []common.Address
+			}
+			To struct {
+				AddrType uint8
+				Payload  []byte
+			}
+		}
+		values, err := gatewayABI.Methods["fund"].Inputs.Unpack(input[4:])
+		require.NoError(t, err)
+		require.Len(t, values, 2)
+		abi.ConvertType(values[0], &args.SubnetId)
+		abi.ConvertType(values[1], &args.To)
+
+		require.Equal(t, subnet.Root, args.SubnetId.Root)
+		require.Equal(t, subnet.Route, args.SubnetId.Route)
+		require.Equal(t, uint8(4), args.To.AddrType)
+
+		// The recipient is encoded as a delegated f410 address.
+		to := common.HexToAddress(TestAddr2)
+		payload, err := faucet.FvmAddressPayload(to)
+		require.NoError(t, err)
+		require.Equal(t, payload, args.To.Payload)
+
+		sendValue(t, child, key, to, value)
+		redeem(t, child, key, childGateway, nil)
+	}
+}
+
+// sendValue transfers value from the key and mines it.
+func sendValue(t *testing.T, sim *backends.SimulatedBackend, key *ecdsa.PrivateKey, to common.Address, value *big.Int) {
+	ctx := context.Background()
+
+	nonce, err := sim.PendingNonceAt(ctx, crypto.PubkeyToAddress(key.PublicKey))
+	require.NoError(t, err)
+
+	head, err := sim.HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(simulatedChainID), &types.DynamicFeeTx{
+		ChainID:   simulatedChainID,
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), big.NewInt(params.GWei)),
+		Gas:       21_000,
+		To:        &to,
+		Value:     value,
+	})
+	require.NoError(t, err)
+
+	err = sim.SendTransaction(ctx, tx)
+	require.NoError(t, err)
+	sim.Commit()
+}
+
+func crossNetMessage(t *testing.T, h http.Handler, txHash string) data.CrossNetMessage {
+	r := httptest.NewRequest(http.MethodGet, "/crossnet/"+txHash, nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var msg data.CrossNetMessage
+	err := json.Unmarshal(w.Body.Bytes(), &msg)
+	require.NoError(t, err)
+	return msg
+}
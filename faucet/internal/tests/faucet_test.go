@@ -7,17 +7,17 @@ import (
 	"math/big"
 	"net/http"
 	"net/http/httptest"
-	"os"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
-	datastore "github.com/ipfs/go-ds-leveldb"
+	"github.com/filecoin-project/go-address"
+	"github.com/ipfs/go-datastore"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/stretchr/testify/require"
-	ldbopts "github.com/syndtr/goleveldb/leveldb/opt"
 
 	"github.com/consensus-shipyard/calibration/faucet/internal/types"
 
@@ -25,11 +25,12 @@ import (
 	faucetDB "github.com/consensus-shipyard/calibration/faucet/internal/db"
 	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
 	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+	"github.com/consensus-shipyard/calibration/faucet/internal/store"
 )
 
 type FaucetTests struct {
 	handler        http.Handler
-	store          *datastore.Datastore
+	store          datastore.Batching
 	db             *faucetDB.Database
 	faucetCfg      *faucet.Config
 	client         *ethclient.Client
@@ -37,7 +38,6 @@ type FaucetTests struct {
 }
 
 const (
-	storePath             = "./_store"
 	localEthereumNodeURL  = "http://localhost:8545"
 	ganacheDefaultChainID = 1
 )
@@ -47,12 +47,7 @@ func newClient() (*ethclient.Client, error) {
 }
 
 func Test_Faucet(t *testing.T) {
-	store, err := datastore.NewDatastore(storePath, &datastore.Options{
-		Compression: ldbopts.NoCompression,
-		NoSync:      false,
-		Strict:      ldbopts.StrictAll,
-		ReadOnly:    false,
-	})
+	ds, err := store.Open(store.Memory, store.Options{})
 	require.NoError(t, err)
 
 	log := logging.Logger("TEST-FAUCET")
@@ -68,28 +63,30 @@ func Test_Faucet(t *testing.T) {
 	chainID, err := client.ChainID(context.Background())
 	require.NoError(t, err)
 
+	deniedAddr, err := types.EthAddress(common.HexToAddress(TestAddr4)).ToFilecoinAddress()
+	require.NoError(t, err)
+
 	cfg := faucet.Config{
 		TotalTransferLimit:   1000,
 		AddressTransferLimit: 50,
 		TransferAmount:       10,
-		Account:              account,
+		Accounts:             []*data.EthereumAccount{account},
 		ChainID:              chainID,
+		Denylist:             []address.Address{deniedAddr},
 	}
 
-	srv := handler.FaucetHandler(log, client, store, "0.0.1", &cfg)
+	srv := handler.FaucetHandler(serviceContext(t), log, client, ds, "0.0.1", &cfg)
 
-	db := faucetDB.NewDatabase(store)
+	db := faucetDB.NewDatabase(ds)
 
 	defer func() {
-		err = store.Close()
-		require.NoError(t, err)
-		err = os.RemoveAll(storePath)
+		err = ds.Close()
 		require.NoError(t, err)
 	}()
 
 	tests := FaucetTests{
 		handler:        srv,
-		store:          store,
+		store:          ds,
 		db:             db,
 		faucetCfg:      &cfg,
 		client:         client,
@@ -99,8 +96,10 @@ func Test_Faucet(t *testing.T) {
 	t.Run("addrsBaseline", tests.addrsBaseline)
 	t.Run("clientAvailable", tests.clientAvailable)
 	t.Run("fundEmptyAddress", tests.emptyAddress)
+	t.Run("fundDeniedAddress", tests.deniedAddress)
 	t.Run("fundAddress201EthAddr", tests.fundAddress201EthAddr)
 	t.Run("fundAddress201FilecoinAddr", tests.fundAddress201FilecoinAddr)
+	t.Run("accountInfo", tests.accountInfo)
 	t.Run("fundAddressWithMoreThanAllowed", tests.fundAddressWithMoreThanAllowed)
 	t.Run("fundAddressWithMoreThanTotal", tests.fundAddressWithMoreThanTotal)
 	t.Run("liveness", tests.liveness)
@@ -154,6 +153,22 @@ func (ft *FaucetTests) emptyAddress(t *testing.T) {
 	require.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+func (ft *FaucetTests) deniedAddress(t *testing.T) {
+	req := data.FundRequest{Address: TestAddr4}
+
+	body, err := json.Marshal(&req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/fund", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	ft.handler.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
+
 func (ft *FaucetTests) fundAddress201EthAddr(t *testing.T) {
 	ft.fundAddress(t, TestAddr1, TestAddr1)
 }
@@ -192,6 +207,28 @@ func (ft *FaucetTests) fundAddress(t *testing.T, fundAddr, checkAddr string) {
 	require.Equal(t, new(big.Int).Add(oldBalance, ft.transferAmount), newBalance)
 }
 
+func (ft *FaucetTests) accountInfo(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/accounts/"+FilecoinTestAddr1, nil)
+	w := httptest.NewRecorder()
+
+	ft.handler.ServeHTTP(w, r)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp data.AccountResponse
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	require.NoError(t, err)
+
+	require.Equal(t, TestAddr1, resp.Address)
+	require.Equal(t, FilecoinTestAddr1, resp.FilecoinAddress)
+	require.Equal(t, 2*ft.faucetCfg.TransferAmount, resp.Received)
+	require.Equal(t, ft.faucetCfg.AddressTransferLimit-resp.Received, resp.Remaining)
+	require.NotNil(t, resp.NextReset)
+	require.NotNil(t, resp.LastGrant)
+	require.NotEmpty(t, resp.LastTxHash)
+	require.Equal(t, ft.faucetCfg.TotalTransferLimit-resp.Received, resp.TotalRemaining)
+}
+
 // fundAddressWithMoreThanAllowed tests that exceeding daily allowed funds per address is not allowed.
 func (ft *FaucetTests) fundAddressWithMoreThanAllowed(t *testing.T) {
 	targetAddr := common.HexToAddress(TestAddr1)
@@ -214,7 +251,10 @@ func (ft *FaucetTests) fundAddressWithMoreThanAllowed(t *testing.T) {
 
 	ft.handler.ServeHTTP(w, r)
 
-	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+	require.Equal(t, strconv.FormatUint(ft.faucetCfg.AddressTransferLimit, 10), w.Header().Get("X-RateLimit-Limit"))
+	require.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
 
 	got := w.Body.String()
 	exp := faucet.ErrExceedAddrAllowedFunds.Error()
@@ -245,7 +285,9 @@ func (ft *FaucetTests) fundAddressWithMoreThanTotal(t *testing.T) {
 
 	ft.handler.ServeHTTP(w, r)
 
-	require.Equal(t, http.StatusInternalServerError, w.Code)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.NotEmpty(t, w.Header().Get("Retry-After"))
+	require.Equal(t, strconv.FormatUint(ft.faucetCfg.TotalTransferLimit, 10), w.Header().Get("X-RateLimit-Limit"))
 
 	got := w.Body.String()
 	exp := faucet.ErrExceedTotalAllowedFunds.Error()
@@ -0,0 +1,183 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+)
+
+func Test_Ledger(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 10,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		AdminToken:           adminToken,
+		PoolRefreshInterval:  50 * time.Millisecond,
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	fundFrom := func(ip, addr string) *httptest.ResponseRecorder {
+		body, err := json.Marshal(data.FundRequest{Address: addr})
+		require.NoError(t, err)
+
+		r := httptest.NewRequest(http.MethodPost, "/fund", bytes.NewReader(body))
+		r.RemoteAddr = ip + ":40000"
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		return w
+	}
+
+	w := fundFrom("192.0.2.1", TestAddr2)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var resp data.FundResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+	// The second request exceeds the quota of the address.
+	w = fundFrom("203.0.113.7", TestAddr2)
+	require.Equal(t, http.StatusTooManyRequests, w.Code, w.Body.String())
+
+	w = fundFrom("192.0.2.1", FilecoinTestAddr3)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	entries := ledger(t, srv, nil).Entries
+	require.Len(t, entries, 3)
+
+	require.Equal(t, faucet.LedgerGranted, entries[0].Decision)
+	require.Equal(t, TestAddr3, entries[0].Ethereum)
+	require.Equal(t, FilecoinTestAddr3, entries[0].Filecoin)
+
+	require.Equal(t, faucet.LedgerRejected, entries[1].Decision)
+	require.Equal(t, "203.0.113.7", entries[1].IP)
+	require.NotEmpty(t, entries[1].Reason)
+	require.Empty(t, entries[1].TxHash)
+
+	granted := entries[2]
+	require.Equal(t, faucet.LedgerGranted, granted.Decision)
+	require.Equal(t, "192.0.2.1", granted.IP)
+	require.Equal(t, TestAddr2, granted.Recipient)
+	require.Equal(t, TestAddr2, granted.Identity)
+	require.Equal(t, faucet.NativeAsset, granted.Asset)
+	require.Equal(t, uint64(10), granted.Amount)
+	require.Equal(t, resp.TxHash, granted.TxHash)
+
+	// The gas paid is recorded once the transaction is mined.
+	require.Empty(t, granted.GasPaid)
+	require.Nil(t, granted.Mined)
+	sim.Commit()
+
+	require.Eventually(t, func() bool {
+		granted = ledger(t, srv, nil).Entries[2]
+		return granted.Mined != nil
+	}, 5*time.Second, 50*time.Millisecond)
+	gasPaid, ok := new(big.Int).SetString(granted.GasPaid, 10)
+	require.True(t, ok)
+	require.Positive(t, gasPaid.Sign())
+
+	// Entries are selected by any form of the recipient, by IP and by decision.
+	require.Len(t, ledger(t, srv, url.Values{"address": {strings.ToLower(TestAddr2)}}).Entries, 2)
+	require.Len(t, ledger(t, srv, url.Values{"address": {TestAddr3}}).Entries, 1)
+	require.Len(t, ledger(t, srv, url.Values{"address": {FilecoinTestAddr2}}).Entries, 2)
+	require.Len(t, ledger(t, srv, url.Values{"ip": {"192.0.2.1"}}).Entries, 2)
+	require.Len(t, ledger(t, srv, url.Values{"address": {TestAddr2}, "ip": {"192.0.2.1"}}).Entries, 1)
+	require.Len(t, ledger(t, srv, url.Values{"decision": {faucet.LedgerRejected}}).Entries, 1)
+	require.Empty(t, ledger(t, srv, url.Values{"from": {time.Now().Add(time.Hour).Format(time.RFC3339)}}).Entries)
+	require.Empty(t, ledger(t, srv, url.Values{"to": {granted.Time.Add(-time.Second).Format(time.RFC3339)}}).Entries)
+
+	// Pages follow each other without overlap.
+	page := ledger(t, srv, url.Values{"limit": {"2"}})
+	require.Len(t, page.Entries, 2)
+	require.Equal(t, entries[1].ID, page.Next)
+	page = ledger(t, srv, url.Values{"limit": {"2"}, "cursor": {page.Next}})
+	require.Len(t, page.Entries, 1)
+	require.Equal(t, entries[2].ID, page.Entries[0].ID)
+	require.Empty(t, page.Next)
+
+	for _, q := range []string{"limit=0", "limit=x", "decision=maybe", "from=yesterday"} {
+		w = admin(t, srv, http.MethodGet, "/admin/ledger?"+q, nil)
+		require.Equal(t, http.StatusBadRequest, w.Code, q)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/admin/ledger", nil)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, r)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func Test_LedgerTrustedProxies(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+
+	_, proxies, err := net.ParseCIDR("198.51.100.0/24")
+	require.NoError(t, err)
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 100,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		AdminToken:           adminToken,
+		TrustedProxies:       []*net.IPNet{proxies},
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	for _, tc := range []struct {
+		remote    string
+		forwarded []string
+		ip        string
+	}{
+		// The client address is the last one not set by a trusted proxy, the ones before can be forged.
+		{"198.51.100.1", []string{"192.0.2.66, 203.0.113.7, 198.51.100.2"}, "203.0.113.7"},
+		{"198.51.100.1", []string{"192.0.2.66", "203.0.113.8"}, "203.0.113.8"},
+		{"198.51.100.1", nil, "198.51.100.1"},
+		{"198.51.100.1", []string{"not an address"}, "198.51.100.1"},
+		// Untrusted clients can't set their address.
+		{"203.0.113.9", []string{"192.0.2.66"}, "203.0.113.9"},
+	} {
+		body, err := json.Marshal(data.FundRequest{Address: TestAddr2})
+		require.NoError(t, err)
+
+		r := httptest.NewRequest(http.MethodPost, "/fund", bytes.NewReader(body))
+		r.RemoteAddr = tc.remote + ":40000"
+		for _, f := range tc.forwarded {
+			r.Header.Add("X-Forwarded-For", f)
+		}
+		w := httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		require.Equal(t, tc.ip, ledger(t, srv, url.Values{"limit": {"1"}}).Entries[0].IP, tc.forwarded)
+	}
+}
+
+func ledger(t *testing.T, h http.Handler, q url.Values) data.LedgerPage {
+	w := admin(t, h, http.MethodGet, "/admin/ledger?"+q.Encode(), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var page data.LedgerPage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	return page
+}
@@ -0,0 +1,493 @@
+package tests
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/filecoin-project/go-address"
+	fbig "github.com/filecoin-project/go-state-types/big"
+	fcrypto "github.com/filecoin-project/go-state-types/crypto"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+	"github.com/consensus-shipyard/calibration/faucet/internal/lotus"
+	ftypes "github.com/consensus-shipyard/calibration/faucet/internal/types"
+)
+
+const fakeLotusToken = "lotus-token"
+
+// fakeLotus is an in-process Lotus JSON-RPC server that verifies and records pushed messages.
+type fakeLotus struct {
+	t *testing.T
+
+	mu       sync.Mutex
+	nonces   map[address.Address]uint64
+	balances map[address.Address]fbig.Int
+	messages []lotus.SignedMessage
+	failPush bool
+	actors   map[address.Address]address.Address
+	lookups  int
+	// failLookup fails the robust address lookups with a node error.
+	failLookup bool
+}
+
+func newFakeLotus(t *testing.T) (*fakeLotus, *httptest.Server) {
+	f := &fakeLotus{
+		t:        t,
+		nonces:   make(map[address.Address]uint64),
+		balances: make(map[address.Address]fbig.Int),
+		actors:   make(map[address.Address]address.Address),
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeLotus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer "+fakeLotusToken {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		ID     int64             `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	result, err := f.call(req.Method, req.Params)
+
+	resp := map[string]any{"jsonrpc": "2.0", "id": req.ID}
+	if err != nil {
+		resp["error"] = map[string]any{"code": 1, "message": err.Error()}
+	} else {
+		resp["result"] = result
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (f *fakeLotus) call(method string, params []json.RawMessage) (any, error) {
+	switch method {
+	case "Filecoin.MpoolGetNonce":
+		var addr address.Address
+		if err := json.Unmarshal(params[0], &addr); err != nil {
+			return nil, err
+		}
+		return f.nonces[addr], nil
+
+	case "Filecoin.GasEstimateMessageGas":
+		var msg lotus.Message
+		if err := json.Unmarshal(params[0], &msg); err != nil {
+			return nil, err
+		}
+		msg.GasLimit = 1_000_000
+		msg.GasFeeCap = fbig.NewInt(200_000)
+		msg.GasPremium = fbig.NewInt(100_000)
+		return msg, nil
+
+	case "Filecoin.MpoolPush":
+		if f.failPush {
+			return nil, fmt.Errorf("mpool is full")
+		}
+
+		var msg lotus.SignedMessage
+		if err := json.Unmarshal(params[0], &msg); err != nil {
+			return nil, err
+		}
+
+		msgCid, err := msg.Message.Cid()
+		if err != nil {
+			return nil, err
+		}
+
+		if msg.Signature.Type != fcrypto.SigTypeSecp256k1 {
+			return nil, fmt.Errorf("unexpected signature type %d", msg.Signature.Type)
+		}
+		digest := blake2b.Sum256(msgCid.Bytes())
+		pub, err := crypto.Ecrecover(digest[:], msg.Signature.Data)
+		if err != nil {
+			return nil, err
+		}
+		signer, err := address.NewSecp256k1Address(pub)
+		if err != nil {
+			return nil, err
+		}
+		if signer != msg.Message.From {
+			return nil, fmt.Errorf("message from %s is signed by %s", msg.Message.From, signer)
+		}
+		if msg.Message.Nonce != f.nonces[signer] {
+			return nil, fmt.Errorf("unexpected nonce %d", msg.Message.Nonce)
+		}
+
+		cost := fbig.Add(msg.Message.Value, fbig.Mul(msg.Message.GasFeeCap, fbig.NewInt(msg.Message.GasLimit)))
+		if balance := f.balances[signer]; balance.Int == nil || balance.LessThan(cost) {
+			return nil, fmt.Errorf("not enough funds")
+		}
+
+		f.nonces[signer]++
+		f.balances[signer] = fbig.Sub(f.balances[signer], msg.Message.Value)
+		f.messages = append(f.messages, msg)
+		return msgCid, nil
+
+	case "Filecoin.StateGetActor":
+		var addr address.Address
+		if err := json.Unmarshal(params[0], &addr); err != nil {
+			return nil, err
+		}
+		balance, ok := f.balances[addr]
+		if !ok {
+			return nil, fmt.Errorf("resolution lookup failed (%s): actor not found", addr)
+		}
+		// Pushed messages are included at once.
+		return lotus.Actor{Nonce: f.nonces[addr], Balance: balance}, nil
+
+	case "Filecoin.StateLookupID":
+		var addr address.Address
+		if err := json.Unmarshal(params[0], &addr); err != nil {
+			return nil, err
+		}
+		f.lookups++
+		if f.failLookup {
+			return nil, fmt.Errorf("chain head is not available")
+		}
+		for robust, id := range f.actors {
+			if addr == robust || addr == id {
+				return id, nil
+			}
+		}
+		return nil, fmt.Errorf("resolution lookup failed (%s): actor not found", addr)
+
+	case "Filecoin.StateLookupRobustAddress":
+		var addr address.Address
+		if err := json.Unmarshal(params[0], &addr); err != nil {
+			return nil, err
+		}
+		f.lookups++
+		if f.failLookup {
+			return nil, fmt.Errorf("chain head is not available")
+		}
+		for robust, id := range f.actors {
+			if addr == id {
+				return robust, nil
+			}
+		}
+		return nil, fmt.Errorf("actor not found")
+
+	default:
+		return nil, fmt.Errorf("unknown method %s", method)
+	}
+}
+
+// addActor registers an actor with the robust address and the ID.
+func (f *fakeLotus) addActor(robust address.Address, id uint64) address.Address {
+	idAddr, err := address.NewIDAddress(id)
+	require.NoError(f.t, err)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actors[robust] = idAddr
+	return idAddr
+}
+
+// setBalance sets the balance of the actor at the address, and creates the actor.
+func (f *fakeLotus) setBalance(addr address.Address, balance *big.Int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.balances[addr] = fbig.NewFromGo(balance)
+}
+
+func (f *fakeLotus) lookupCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lookups
+}
+
+func (f *fakeLotus) pushed() []lotus.SignedMessage {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]lotus.SignedMessage(nil), f.messages...)
+}
+
+func Test_FilecoinFaucet(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+	lotusNode, lotusSrv := newFakeLotus(t)
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 20,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		Filecoin:             lotus.NewClient(lotusSrv.URL, fakeLotusToken),
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	f1, err := address.NewSecp256k1Address(crypto.FromECDSAPub(&key.PublicKey))
+	require.NoError(t, err)
+
+	blsKey := make([]byte, address.BlsPublicKeyBytes)
+	_, err = rand.Read(blsKey)
+	require.NoError(t, err)
+	f3, err := address.NewBLSAddress(blsKey)
+	require.NoError(t, err)
+
+	from, err := account.FilecoinAddress()
+	require.NoError(t, err)
+	lotusNode.setBalance(from, faucet.TransferAmount(1000))
+
+	t.Run("fundF1", func(t *testing.T) {
+		w := fundAsset(t, srv, f1.String(), "")
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var resp data.FundResponse
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NoError(t, err)
+
+		msgs := lotusNode.pushed()
+		require.Len(t, msgs, 1)
+		msgCid, err := msgs[0].Message.Cid()
+		require.NoError(t, err)
+		require.Equal(t, msgCid.String(), resp.TxHash)
+
+		msg := msgs[0].Message
+		require.Equal(t, f1, msg.To)
+		require.Equal(t, from, msg.From)
+		require.Equal(t, uint64(lotus.MethodSend), msg.Method)
+		require.Equal(t, fbig.NewFromGo(faucet.TransferAmount(10)), msg.Value)
+		require.Equal(t, int64(1_000_000), msg.GasLimit)
+	})
+
+	t.Run("fundF3", func(t *testing.T) {
+		w := fundAsset(t, srv, f3.String(), "")
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		msgs := lotusNode.pushed()
+		require.Len(t, msgs, 2)
+		require.Equal(t, f3, msgs[1].Message.To)
+		require.Equal(t, uint64(1), msgs[1].Message.Nonce)
+	})
+
+	t.Run("quota", func(t *testing.T) {
+		w := fundAsset(t, srv, f1.String(), "")
+		require.Equal(t, http.StatusCreated, w.Code)
+
+		w = fundAsset(t, srv, f1.String(), "")
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		require.Len(t, lotusNode.pushed(), 3)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		// Concurrent messages get consecutive nonces.
+		var wg sync.WaitGroup
+		codes := make([]int, 5)
+		for i := range codes {
+			blsKey := make([]byte, address.BlsPublicKeyBytes)
+			_, err := rand.Read(blsKey)
+			require.NoError(t, err)
+			to, err := address.NewBLSAddress(blsKey)
+			require.NoError(t, err)
+
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				codes[i] = fundAsset(t, srv, to.String(), "").Code
+			}(i)
+		}
+		wg.Wait()
+
+		for _, code := range codes {
+			require.Equal(t, http.StatusCreated, code)
+		}
+		msgs := lotusNode.pushed()
+		require.Len(t, msgs, 8)
+		for i, msg := range msgs {
+			require.Equal(t, uint64(i), msg.Message.Nonce)
+		}
+	})
+
+	t.Run("insufficientFunds", func(t *testing.T) {
+		// The value and the maximum fee of the message must be covered.
+		lotusNode.setBalance(from, faucet.TransferAmount(10))
+
+		w := fundAsset(t, srv, f3.String(), "")
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Contains(t, w.Body.String(), faucet.ErrInsufficientFunds.Error())
+		require.Len(t, lotusNode.pushed(), 8)
+
+		lotusNode.setBalance(from, faucet.TransferAmount(1000))
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		// Actors that don't exist yet can only receive native messages.
+		w := post(t, srv, "/fund", data.FundRequest{Address: f3.String(), Bundle: "starter"})
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		r := httptest.NewRequest(http.MethodGet, "/accounts/"+f3.String(), nil)
+		w = httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		require.Equal(t, http.StatusNotFound, w.Code)
+
+		noLotus := cfg
+		noLotus.Filecoin = nil
+		h := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &noLotus)
+		w = fundAsset(t, h, f3.String(), "")
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.Contains(t, w.Body.String(), faucet.ErrUnsupportedAddress.Error())
+	})
+
+	t.Run("lotusUnavailable", func(t *testing.T) {
+		lotusNode.mu.Lock()
+		lotusNode.failPush = true
+		lotusNode.mu.Unlock()
+
+		w := fundAsset(t, srv, f3.String(), "")
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+}
+
+func Test_ResolveFilecoinAddresses(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+	lotusNode, lotusSrv := newFakeLotus(t)
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 20,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		Filecoin:             lotus.NewClient(lotusSrv.URL, fakeLotusToken),
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	f1, err := address.NewSecp256k1Address(crypto.FromECDSAPub(&key.PublicKey))
+	require.NoError(t, err)
+
+	id := lotusNode.addActor(f1, 1234)
+	masked, err := ftypes.EthAddressFromFilecoinAddress(id)
+	require.NoError(t, err)
+	maskedAddr := common.Address(masked)
+
+	// An existing actor is funded at its masked ID address without a native message.
+	w := fundAsset(t, srv, f1.String(), "")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	sim.Commit()
+	require.Empty(t, lotusNode.pushed())
+
+	balance, err := sim.BalanceAt(context.Background(), maskedAddr, nil)
+	require.NoError(t, err)
+	require.Equal(t, faucet.TransferAmount(10), balance)
+
+	// Resolutions are cached.
+	require.Equal(t, 1, lotusNode.lookupCount())
+	info := accountInfo(t, srv, f1.String(), "")
+	require.Equal(t, maskedAddr.Hex(), info.Address)
+	require.Equal(t, uint64(10), info.Received)
+	require.Equal(t, 1, lotusNode.lookupCount())
+
+	// f0 recipients must exist.
+	w = fundAsset(t, srv, id.String(), "")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	sim.Commit()
+
+	unknown, err := address.NewIDAddress(9999)
+	require.NoError(t, err)
+	w = fundAsset(t, srv, unknown.String(), "")
+	require.Equal(t, http.StatusNotFound, w.Code)
+	require.Contains(t, w.Body.String(), faucet.ErrActorNotFound.Error())
+}
+
+func Test_CanonicalIdentity(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+	lotusNode, lotusSrv := newFakeLotus(t)
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	deniedF1, err := address.NewSecp256k1Address(crypto.FromECDSAPub(&key.PublicKey))
+	require.NoError(t, err)
+	deniedID := lotusNode.addActor(deniedF1, 3000)
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 20,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		Filecoin:             lotus.NewClient(lotusSrv.URL, fakeLotusToken),
+		Denylist:             []address.Address{deniedID},
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	f4, err := ftypes.EthAddress(common.HexToAddress(TestAddr3)).ToFilecoinAddress()
+	require.NoError(t, err)
+	id := lotusNode.addActor(f4, 2000)
+	masked, err := ftypes.EthAddressFromFilecoinAddress(id)
+	require.NoError(t, err)
+
+	// A failed lookup is not taken for an actor without a robust address.
+	lotusNode.mu.Lock()
+	lotusNode.failLookup = true
+	lotusNode.mu.Unlock()
+	w := fundAsset(t, srv, id.String(), "")
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+	lotusNode.mu.Lock()
+	lotusNode.failLookup = false
+	lotusNode.mu.Unlock()
+
+	// The 0x, f0 and masked ID forms of the actor share one allowance.
+	w = fundAsset(t, srv, TestAddr3, "")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	sim.Commit()
+
+	w = fundAsset(t, srv, id.String(), "")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	sim.Commit()
+
+	w = fundAsset(t, srv, common.Address(masked).Hex(), "")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	w = fundAsset(t, srv, f4.String(), "")
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	info := accountInfo(t, srv, id.String(), "")
+	require.Equal(t, common.HexToAddress(TestAddr3).Hex(), info.Identity)
+	require.Equal(t, uint64(20), info.Received)
+
+	// The denylist matches every form of a denylisted actor.
+	w = fundAsset(t, srv, deniedF1.String(), "")
+	require.Equal(t, http.StatusForbidden, w.Code)
+	maskedDenied, err := ftypes.EthAddressFromFilecoinAddress(deniedID)
+	require.NoError(t, err)
+	w = fundAsset(t, srv, common.Address(maskedDenied).Hex(), "")
+	require.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,176 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+	ftypes "github.com/consensus-shipyard/calibration/faucet/internal/types"
+)
+
+func Test_NetworksFaucet(t *testing.T) {
+	sim1, account := newSimulatedChain(t)
+	sim2, _ := newSimulatedChain(t)
+
+	newCfg := func(amount uint64) *faucet.Config {
+		return &faucet.Config{
+			TotalTransferLimit:   1000,
+			AddressTransferLimit: 2 * amount,
+			TransferAmount:       amount,
+			Accounts:             []*data.EthereumAccount{account},
+			ChainID:              simulatedChainID,
+		}
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.NetworksHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), []handler.Network{
+		{Name: "parent", Client: sim1, Config: newCfg(10)},
+		{Name: "child", Client: sim2, Config: newCfg(3)},
+	}, store, "0.0.1", nil, "", handler.Proxies{})
+
+	recipient := common.HexToAddress(TestAddr1)
+	balance := func(sim interface {
+		BalanceAt(context.Context, common.Address, *big.Int) (*big.Int, error)
+	}) *big.Int {
+		b, err := sim.BalanceAt(context.Background(), recipient, nil)
+		require.NoError(t, err)
+		return b
+	}
+
+	// Route by path.
+	w := post(t, srv, "/parent/fund", data.FundRequest{Address: TestAddr1})
+	require.Equal(t, http.StatusCreated, w.Code)
+	sim1.Commit()
+	require.Equal(t, faucet.TransferAmount(10), balance(sim1))
+	require.Equal(t, big.NewInt(0), balance(sim2))
+
+	// Route by request field.
+	w = post(t, srv, "/fund", data.FundRequest{Address: TestAddr1, Network: "child"})
+	require.Equal(t, http.StatusCreated, w.Code)
+	sim2.Commit()
+	require.Equal(t, faucet.TransferAmount(3), balance(sim2))
+
+	// Quotas are namespaced per network.
+	w = post(t, srv, "/parent/fund", data.FundRequest{Address: TestAddr1})
+	require.Equal(t, http.StatusCreated, w.Code)
+	sim1.Commit()
+	w = post(t, srv, "/parent/fund", data.FundRequest{Address: TestAddr1})
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	w = post(t, srv, "/child/fund", data.FundRequest{Address: TestAddr1})
+	require.Equal(t, http.StatusCreated, w.Code)
+	sim2.Commit()
+
+	r := httptest.NewRequest(http.MethodGet, "/child/accounts/"+TestAddr1, nil)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var info data.AccountResponse
+	err := json.Unmarshal(w.Body.Bytes(), &info)
+	require.NoError(t, err)
+	require.Equal(t, uint64(6), info.Received)
+
+	// Unknown networks are rejected.
+	w = post(t, srv, "/fund", data.FundRequest{Address: TestAddr1, Network: "unknown"})
+	require.Equal(t, http.StatusNotFound, w.Code)
+	w = post(t, srv, "/fund", data.FundRequest{Address: TestAddr1})
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	// Every network has its own readiness check.
+	for _, path := range []string{"/readiness", "/parent/readiness", "/child/readiness"} {
+		r = httptest.NewRequest(http.MethodGet, path, nil)
+		w = httptest.NewRecorder()
+		srv.ServeHTTP(w, r)
+		require.Equal(t, http.StatusOK, w.Code, path)
+	}
+}
+
+func Test_SubnetQualifiedAddresses(t *testing.T) {
+	sim1, account := newSimulatedChain(t)
+	sim2, _ := newSimulatedChain(t)
+
+	childSubnet, err := ftypes.ParseSubnetID("/r1337/t410f77hy7xxhflarwxcuequlgxxpk5u4icpqb4cl5ha")
+	require.NoError(t, err)
+
+	newCfg := func(amount uint64, subnet ftypes.SubnetID) *faucet.Config {
+		return &faucet.Config{
+			TotalTransferLimit:   1000,
+			AddressTransferLimit: 2 * amount,
+			TransferAmount:       amount,
+			Accounts:             []*data.EthereumAccount{account},
+			ChainID:              simulatedChainID,
+			Subnet:               subnet,
+		}
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.NetworksHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), []handler.Network{
+		{Name: "parent", Client: sim1, Config: newCfg(10, ftypes.SubnetID{})},
+		{Name: "child", Client: sim2, Config: newCfg(3, childSubnet)},
+	}, store, "0.0.1", nil, "", handler.Proxies{})
+
+	f4, err := ftypes.EthAddress(common.HexToAddress(TestAddr2)).ToFilecoinAddress()
+	require.NoError(t, err)
+
+	// The request is routed to the network serving the subnet.
+	w := post(t, srv, "/fund", data.FundRequest{Address: childSubnet.String() + ":" + f4.String()})
+	require.Equal(t, http.StatusCreated, w.Code)
+	sim2.Commit()
+
+	w = post(t, srv, "/fund", data.FundRequest{Address: "/r1337:" + TestAddr2})
+	require.Equal(t, http.StatusCreated, w.Code)
+	sim1.Commit()
+
+	for sim, amount := range map[interface {
+		BalanceAt(context.Context, common.Address, *big.Int) (*big.Int, error)
+	}]uint64{sim1: 10, sim2: 3} {
+		b, err := sim.BalanceAt(context.Background(), common.HexToAddress(TestAddr2), nil)
+		require.NoError(t, err)
+		require.Equal(t, faucet.TransferAmount(amount), b)
+	}
+
+	// The subnet must match the named network.
+	w = post(t, srv, "/parent/fund", data.FundRequest{Address: childSubnet.String() + ":" + TestAddr2})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Contains(t, w.Body.String(), "subnet is not served")
+
+	// Subnets that are not served are rejected.
+	w = post(t, srv, "/fund", data.FundRequest{Address: "/r314159:" + TestAddr2})
+	require.Equal(t, http.StatusBadRequest, w.Code)
+
+	r := httptest.NewRequest(http.MethodGet, "/accounts/"+url.PathEscape(childSubnet.String()+":"+TestAddr2), nil)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var info data.AccountResponse
+	err = json.Unmarshal(w.Body.Bytes(), &info)
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), info.Received)
+}
+
+func post(t *testing.T, h http.Handler, path string, req any) *httptest.ResponseRecorder {
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+	return w
+}
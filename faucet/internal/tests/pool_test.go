@@ -0,0 +1,328 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+)
+
+const (
+	poolPrivateKey1 = "6cbed15c793ce57650b9877cf6fa156fbef513c4e6134f022a85b1ffdd59b2a1"
+	poolPrivateKey2 = "395df67f0c2d2d9fe1ad08d1bc8b6627011959b79c53d7dd6a3536a33ab8a4fd"
+	adminToken      = "admin-secret"
+)
+
+func Test_AccountPool(t *testing.T) {
+	acc0, err := data.NewAccount(FaucetPrivateKey)
+	require.NoError(t, err)
+	acc1, err := data.NewAccount(poolPrivateKey1)
+	require.NoError(t, err)
+	// The third account has no funds.
+	acc2, err := data.NewAccount(poolPrivateKey2)
+	require.NoError(t, err)
+
+	funds := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 100,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{acc0, acc1},
+		ChainID:              simulatedChainID,
+		AdminToken:           adminToken,
+		PoolRefreshInterval:  50 * time.Millisecond,
+	}
+
+	sim, srv := newSimulatedFaucet(t, core.GenesisAlloc{
+		acc0.Address: {Balance: funds},
+		acc1.Address: {Balance: funds},
+	}, &cfg)
+
+	// fund returns the sender and the nonce of the transaction funding the address.
+	fund := func(addr string) (common.Address, uint64) {
+		w := post(t, srv, "/fund", data.FundRequest{Address: addr})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var resp data.FundResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+		tx, _, err := sim.TransactionByHash(context.Background(), common.HexToHash(resp.TxHash))
+		require.NoError(t, err)
+		from, err := types.Sender(types.LatestSignerForChainID(simulatedChainID), tx)
+		require.NoError(t, err)
+		return from, tx.Nonce()
+	}
+
+	// Requests go to the account with the fewest pending transactions.
+	from, nonce := fund(TestAddr2)
+	require.Equal(t, acc0.Address, from)
+	require.Equal(t, uint64(0), nonce)
+
+	from, nonce = fund(TestAddr3)
+	require.Equal(t, acc1.Address, from)
+	require.Equal(t, uint64(0), nonce)
+
+	accounts := poolAccounts(t, srv)
+	require.Len(t, accounts, 2)
+	for _, a := range accounts {
+		require.Equal(t, uint64(1), a.Pending, a.Address)
+	}
+
+	w := admin(t, srv, http.MethodPost, "/admin/accounts", data.AddAccountRequest{AccountKey: keyFile(t, poolPrivateKey2)})
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	w = admin(t, srv, http.MethodPost, "/admin/accounts", data.AddAccountRequest{AccountKey: keyFile(t, poolPrivateKey2)})
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// Accounts without enough balance are skipped, and nonces are assigned locally.
+	from, nonce = fund(TestAddr4)
+	require.Equal(t, acc0.Address, from)
+	require.Equal(t, uint64(1), nonce)
+
+	sim.Commit()
+
+	// The accounts are listed from the state the pool refreshes periodically.
+	require.Eventually(t, func() bool {
+		accounts = poolAccounts(t, srv)
+		for _, a := range accounts {
+			if a.Pending != 0 {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Len(t, accounts, 3)
+	require.Equal(t, acc2.Address.Hex(), accounts[2].Address)
+	require.Equal(t, "0", accounts[2].Balance)
+	require.Equal(t, uint64(2), accounts[0].Nonce)
+
+	for _, addr := range []string{TestAddr2, TestAddr3, TestAddr4} {
+		balance, err := sim.BalanceAt(context.Background(), common.HexToAddress(addr), nil)
+		require.NoError(t, err)
+		require.Equal(t, faucet.TransferAmount(cfg.TransferAmount), balance, addr)
+	}
+
+	// Accounts are removed without a restart, except for the last one.
+	w = admin(t, srv, http.MethodDelete, "/admin/accounts/"+acc0.Address.Hex(), nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+	w = admin(t, srv, http.MethodDelete, "/admin/accounts/"+acc0.Address.Hex(), nil)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	from, _ = fund(TestAddr2)
+	require.Equal(t, acc1.Address, from)
+
+	w = admin(t, srv, http.MethodDelete, "/admin/accounts/"+acc1.Address.Hex(), nil)
+	require.Equal(t, http.StatusNoContent, w.Code)
+
+	w = post(t, srv, "/fund", data.FundRequest{Address: TestAddr3})
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	w = admin(t, srv, http.MethodDelete, "/admin/accounts/"+acc2.Address.Hex(), nil)
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// The admin API requires the token.
+	r := httptest.NewRequest(http.MethodGet, "/admin/accounts", nil)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, r)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	r = httptest.NewRequest(http.MethodGet, "/admin/accounts", nil)
+	r.Header.Set("Authorization", "Bearer wrong")
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, r)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func Test_AccountPoolFunds(t *testing.T) {
+	acc0, err := data.NewAccount(FaucetPrivateKey)
+	require.NoError(t, err)
+	acc1, err := data.NewAccount(poolPrivateKey1)
+	require.NoError(t, err)
+
+	value := faucet.TransferAmount(10)
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 100,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{acc0, acc1},
+		ChainID:              simulatedChainID,
+		AdminToken:           adminToken,
+	}
+
+	// The first account holds the value but not the fee, the second one holds enough for a single transfer.
+	sim, srv := newSimulatedFaucet(t, core.GenesisAlloc{
+		acc0.Address: {Balance: value},
+		acc1.Address: {Balance: new(big.Int).Add(value, new(big.Int).Div(value, big.NewInt(2)))},
+	}, &cfg)
+
+	w := post(t, srv, "/fund", data.FundRequest{Address: TestAddr2})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var resp data.FundResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	tx, _, err := sim.TransactionByHash(context.Background(), common.HexToHash(resp.TxHash))
+	require.NoError(t, err)
+	from, err := types.Sender(types.LatestSignerForChainID(simulatedChainID), tx)
+	require.NoError(t, err)
+	require.Equal(t, acc1.Address, from)
+
+	// The pending transfer of the second account is reserved against its balance.
+	w = post(t, srv, "/fund", data.FundRequest{Address: TestAddr3})
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+
+	sim.Commit()
+	w = post(t, srv, "/fund", data.FundRequest{Address: TestAddr3})
+	require.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+}
+
+func Test_AccountPoolNonceRejected(t *testing.T) {
+	acc, err := data.NewAccount(FaucetPrivateKey)
+	require.NoError(t, err)
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 100,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{acc},
+		ChainID:              simulatedChainID,
+		AdminToken:           adminToken,
+	}
+
+	funds := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	sim, srv := newSimulatedFaucet(t, core.GenesisAlloc{
+		acc.Address: {Balance: funds},
+	}, &cfg)
+
+	w := post(t, srv, "/fund", data.FundRequest{Address: TestAddr2})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	sim.Commit()
+
+	// A transaction sent with the key outside the faucet takes the next nonce of the account.
+	sendValue(t, sim, acc.PrivateKey, common.HexToAddress(TestAddr4), big.NewInt(params.Ether))
+
+	// The faucet reads the nonce from the chain again, and sends the transfer once more.
+	w = post(t, srv, "/fund", data.FundRequest{Address: TestAddr3})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var resp data.FundResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	tx, _, err := sim.TransactionByHash(context.Background(), common.HexToHash(resp.TxHash))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), tx.Nonce())
+}
+
+func Test_AdminAccountKeys(t *testing.T) {
+	acc, err := data.NewAccount(FaucetPrivateKey)
+	require.NoError(t, err)
+	other, err := data.NewAccount(poolPrivateKey1)
+	require.NoError(t, err)
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 100,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{acc},
+		ChainID:              simulatedChainID,
+		AdminToken:           adminToken,
+	}
+
+	funds := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	sim, srv := newSimulatedFaucet(t, core.GenesisAlloc{
+		acc.Address: {Balance: funds},
+	}, &cfg)
+
+	// Private keys are refused unless the faucet allows them.
+	w := admin(t, srv, http.MethodPost, "/admin/accounts", data.AddAccountRequest{AccountKey: data.AccountKey{PrivateKey: poolPrivateKey1}})
+	require.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	both := keyFile(t, poolPrivateKey1)
+	both.PrivateKey = poolPrivateKey1
+	signer := "http://127.0.0.1:1"
+	for _, key := range []data.AccountKey{
+		{},
+		both,
+		{KeyFile: filepath.Join(t.TempDir(), "missing")},
+		keyFile(t, "not a key"),
+		{SignerAccount: other.Address.Hex()},
+		{SignerURL: signer, SignerAccount: "0x1234"},
+		{SignerURL: signer, SignerAccount: other.Address.Hex(), SignerMethod: "sign"},
+	} {
+		w := admin(t, srv, http.MethodPost, "/admin/accounts", data.AddAccountRequest{AccountKey: key})
+		require.Equal(t, http.StatusBadRequest, w.Code, "%+v: %s", key, w.Body.String())
+	}
+	require.Len(t, poolAccounts(t, srv), 1)
+
+	// An account of an external signer signs the transfers once the configured account is removed.
+	f, signerSrv := newFakeSigner(t, sim, acc.PrivateKey)
+	signerAddr := crypto.PubkeyToAddress(f.key.PublicKey)
+	signerHTTP := httptest.NewServer(signerSrv)
+	t.Cleanup(signerHTTP.Close)
+
+	w = admin(t, srv, http.MethodPost, "/admin/accounts", data.AddAccountRequest{AccountKey: data.AccountKey{
+		SignerURL:     signerHTTP.URL,
+		SignerAccount: signerAddr.Hex(),
+	}})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	w = admin(t, srv, http.MethodDelete, "/admin/accounts/"+acc.Address.Hex(), nil)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+
+	w = post(t, srv, "/fund", data.FundRequest{Address: TestAddr2})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	require.Equal(t, 1, f.calls)
+
+	// Private keys are taken once the faucet allows them.
+	cfg.AdminPrivateKeys = true
+	_, srv = newSimulatedFaucet(t, core.GenesisAlloc{
+		acc.Address: {Balance: funds},
+	}, &cfg)
+	w = admin(t, srv, http.MethodPost, "/admin/accounts", data.AddAccountRequest{AccountKey: data.AccountKey{PrivateKey: poolPrivateKey1}})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+// keyFile writes the private key to a file, and returns the reference of the file for the admin API.
+func keyFile(t *testing.T, key string) data.AccountKey {
+	path := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(path, []byte(key), 0o600))
+	return data.AccountKey{KeyFile: path}
+}
+
+func admin(t *testing.T, h http.Handler, method, path string, req any) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	if req != nil {
+		require.NoError(t, json.NewEncoder(&body).Encode(req))
+	}
+
+	r := httptest.NewRequest(method, path, &body)
+	r.Header.Set("Authorization", "Bearer "+adminToken)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func poolAccounts(t *testing.T, h http.Handler) []data.PoolAccount {
+	w := admin(t, h, http.MethodGet, "/admin/accounts", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var accounts []data.PoolAccount
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &accounts))
+	return accounts
+}
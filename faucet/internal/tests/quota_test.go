@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+)
+
+// Test_ConcurrentFunding checks that concurrent requests never exceed the quotas, and that every grant is counted.
+func Test_ConcurrentFunding(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+
+	cfg := faucet.Config{
+		TotalTransferLimit: 100,
+		// The limit isn't a multiple of the transfer amount, the last transfer must not overshoot it.
+		AddressTransferLimit: 35,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	parallel := func(addrs []string) map[int]int {
+		var mu sync.Mutex
+		codes := make(map[int]int)
+
+		var wg sync.WaitGroup
+		for _, addr := range addrs {
+			addr := addr
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w := post(t, srv, "/fund", data.FundRequest{Address: addr})
+				mu.Lock()
+				codes[w.Code]++
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		return codes
+	}
+
+	// Requests for the same address are granted up to its quota.
+	same := make([]string, 10)
+	for i := range same {
+		same[i] = TestAddr2
+	}
+	codes := parallel(same)
+	require.Equal(t, map[int]int{http.StatusCreated: 3, http.StatusTooManyRequests: 7}, codes)
+	require.Equal(t, uint64(30), accountInfo(t, srv, TestAddr2, "").Received)
+
+	// Requests for distinct addresses are granted up to the total quota, which counts every grant.
+	var distinct []string
+	for i := 0; i < 20; i++ {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		distinct = append(distinct, crypto.PubkeyToAddress(key.PublicKey).Hex())
+	}
+	codes = parallel(distinct)
+	require.Equal(t, map[int]int{http.StatusCreated: 7, http.StatusTooManyRequests: 13}, codes)
+
+	info := accountInfo(t, srv, TestAddr3, "")
+	require.Zero(t, info.TotalRemaining)
+}
@@ -0,0 +1,189 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+)
+
+func Test_TreasuryRefill(t *testing.T) {
+	hot, err := data.NewAccount(FaucetPrivateKey)
+	require.NoError(t, err)
+	treasury, err := data.NewAccount(poolPrivateKey1)
+	require.NoError(t, err)
+
+	ether := func(n int64) *big.Int {
+		return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.Ether))
+	}
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 5,
+		TransferAmount:       5,
+		Accounts:             []*data.EthereumAccount{hot},
+		ChainID:              simulatedChainID,
+		AdminToken:           adminToken,
+		Refill: &faucet.RefillConfig{
+			Treasury: treasury,
+			LowWater: 20,
+			Amount:   10,
+			DailyCap: 15,
+			Interval: time.Hour,
+		},
+	}
+
+	sim, srv := newSimulatedFaucet(t, core.GenesisAlloc{
+		hot.Address:      {Balance: ether(25)},
+		treasury.Address: {Balance: ether(1000)},
+	}, &cfg)
+
+	refills := func() []data.RefillRecord {
+		w := admin(t, srv, http.MethodGet, "/admin/refills", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var records []data.RefillRecord
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &records))
+		return records
+	}
+	waitRefills := func(n int) []data.RefillRecord {
+		var records []data.RefillRecord
+		require.Eventually(t, func() bool {
+			records = refills()
+			return len(records) == n
+		}, 5*time.Second, 10*time.Millisecond)
+		return records
+	}
+	fund := func() {
+		key, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		addr := crypto.PubkeyToAddress(key.PublicKey).Hex()
+
+		w := post(t, srv, "/fund", data.FundRequest{Address: addr})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		sim.Commit()
+	}
+
+	require.Empty(t, refills())
+
+	// The hot account falls below the low-water mark and is refilled.
+	fund()
+	records := waitRefills(1)
+	require.Equal(t, faucet.RefillSent, records[0].Status)
+	require.Equal(t, treasury.Address.Hex(), records[0].Treasury)
+	require.Equal(t, hot.Address.Hex(), records[0].Account)
+	require.Equal(t, uint64(10), records[0].Amount)
+	require.NotEmpty(t, records[0].TxHash)
+	sim.Commit()
+
+	balance, err := sim.BalanceAt(context.Background(), hot.Address, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, balance.Cmp(ether(25)))
+
+	fund()
+	time.Sleep(50 * time.Millisecond)
+	require.Len(t, refills(), 1)
+
+	// The next refill is limited by the daily cap.
+	fund()
+	records = waitRefills(2)
+	require.Equal(t, faucet.RefillSent, records[1].Status)
+	require.Equal(t, uint64(5), records[1].Amount)
+	sim.Commit()
+
+	// Once the cap is reached, the rejected refill is recorded once.
+	fund()
+	records = waitRefills(3)
+	require.Equal(t, faucet.RefillCapped, records[2].Status)
+	require.Equal(t, uint64(0), records[2].Amount)
+
+	fund()
+	time.Sleep(50 * time.Millisecond)
+	require.Len(t, refills(), 3)
+
+	balance, err = sim.BalanceAt(context.Background(), treasury.Address, nil)
+	require.NoError(t, err)
+	require.Equal(t, -1, balance.Cmp(ether(1000-15)))
+	require.Equal(t, 1, balance.Cmp(ether(1000-16)))
+}
+
+func Test_TreasuryRefillDropped(t *testing.T) {
+	hot, err := data.NewAccount(FaucetPrivateKey)
+	require.NoError(t, err)
+	treasury, err := data.NewAccount(poolPrivateKey1)
+	require.NoError(t, err)
+
+	ether := func(n int64) *big.Int {
+		return new(big.Int).Mul(big.NewInt(n), big.NewInt(params.Ether))
+	}
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 5,
+		TransferAmount:       5,
+		Accounts:             []*data.EthereumAccount{hot},
+		ChainID:              simulatedChainID,
+		AdminToken:           adminToken,
+		Refill: &faucet.RefillConfig{
+			Treasury: treasury,
+			LowWater: 20,
+			Amount:   10,
+			DailyCap: 10,
+			Interval: 10 * time.Millisecond,
+			Timeout:  100 * time.Millisecond,
+		},
+	}
+
+	sim, srv := newSimulatedFaucet(t, core.GenesisAlloc{
+		hot.Address:      {Balance: ether(25)},
+		treasury.Address: {Balance: ether(1000)},
+	}, &cfg)
+
+	refills := func() []data.RefillRecord {
+		w := admin(t, srv, http.MethodGet, "/admin/refills", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var records []data.RefillRecord
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &records))
+		return records
+	}
+
+	w := post(t, srv, "/fund", data.FundRequest{Address: TestAddr2})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	sim.Commit()
+
+	require.Eventually(t, func() bool {
+		return len(refills()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The refill is dropped from the pending block, so it is never mined.
+	sim.Rollback()
+
+	// The dropped refill fails after the timeout, its amount is returned to the daily cap,
+	// and the account is refilled again with the same nonce.
+	var records []data.RefillRecord
+	require.Eventually(t, func() bool {
+		records = refills()
+		return len(records) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, faucet.RefillSent, records[0].Status)
+	require.Equal(t, faucet.RefillFailed, records[1].Status)
+	require.Equal(t, records[0].TxHash, records[1].TxHash)
+	require.Equal(t, faucet.RefillSent, records[2].Status)
+	require.Equal(t, uint64(10), records[2].Amount)
+	sim.Commit()
+
+	balance, err := sim.BalanceAt(context.Background(), hot.Address, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, balance.Cmp(ether(25)))
+}
@@ -0,0 +1,196 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+)
+
+func Test_AccountRotation(t *testing.T) {
+	acc0, err := data.NewAccount(FaucetPrivateKey)
+	require.NoError(t, err)
+	acc1, err := data.NewAccount(poolPrivateKey1)
+	require.NoError(t, err)
+	// The new key of the first account has no funds.
+	acc2, err := data.NewAccount(poolPrivateKey2)
+	require.NoError(t, err)
+
+	funds := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 100,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{acc0, acc1},
+		ChainID:              simulatedChainID,
+		AdminToken:           adminToken,
+		PoolRefreshInterval:  50 * time.Millisecond,
+	}
+
+	sim, srv := newSimulatedFaucet(t, core.GenesisAlloc{
+		acc0.Address: {Balance: funds},
+		acc1.Address: {Balance: funds},
+	}, &cfg)
+
+	fund := func(addr string) common.Address {
+		w := post(t, srv, "/fund", data.FundRequest{Address: addr})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var resp data.FundResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+		tx, _, err := sim.TransactionByHash(context.Background(), common.HexToHash(resp.TxHash))
+		require.NoError(t, err)
+		from, err := types.Sender(types.LatestSignerForChainID(simulatedChainID), tx)
+		require.NoError(t, err)
+		return from
+	}
+	rotations := func() []data.RotationRecord {
+		w := admin(t, srv, http.MethodGet, "/admin/rotations", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var records []data.RotationRecord
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &records))
+		return records
+	}
+
+	// The first account has a pending transaction when it is rotated.
+	require.Equal(t, acc0.Address, fund(TestAddr2))
+
+	rotate := "/admin/accounts/" + acc0.Address.Hex() + "/rotate"
+	w := admin(t, srv, http.MethodPost, rotate, data.RotateAccountRequest{AccountKey: keyFile(t, poolPrivateKey2), Sweep: true})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+
+	var record data.RotationRecord
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &record))
+	require.Equal(t, faucet.RotationDraining, record.Status)
+	require.Equal(t, acc0.Address.Hex(), record.Old)
+	require.Equal(t, acc2.Address.Hex(), record.New)
+
+	// The new account takes the place of the old one at once.
+	accounts := poolAccounts(t, srv)
+	require.Len(t, accounts, 2)
+	require.Equal(t, acc2.Address.Hex(), accounts[0].Address)
+	require.Equal(t, acc1.Address.Hex(), accounts[1].Address)
+
+	w = admin(t, srv, http.MethodPost, rotate, data.RotateAccountRequest{AccountKey: keyFile(t, FaucetPrivateKey)})
+	require.Equal(t, http.StatusNotFound, w.Code)
+	w = admin(t, srv, http.MethodPost, "/admin/accounts/"+acc1.Address.Hex()+"/rotate", data.RotateAccountRequest{AccountKey: keyFile(t, poolPrivateKey2)})
+	require.Equal(t, http.StatusConflict, w.Code)
+
+	// The faucet keeps serving while the old account drains, and the old account isn't swept before.
+	require.Equal(t, acc1.Address, fund(TestAddr3))
+	time.Sleep(2 * time.Second)
+	require.Equal(t, faucet.RotationDraining, rotations()[0].Status)
+
+	sim.Commit()
+	require.Eventually(t, func() bool {
+		return rotations()[0].Status != faucet.RotationDraining
+	}, 10*time.Second, 100*time.Millisecond)
+
+	record = rotations()[0]
+	require.Equal(t, faucet.RotationCompleted, record.Status, record.Error)
+	require.NotNil(t, record.Completed)
+	require.NotEmpty(t, record.TxHash)
+	sim.Commit()
+
+	balance, err := sim.BalanceAt(context.Background(), acc2.Address, nil)
+	require.NoError(t, err)
+	require.Equal(t, record.Swept, balance.String())
+	require.Positive(t, balance.Sign())
+
+	// The old account only keeps the fee left unused by the sweep.
+	balance, err = sim.BalanceAt(context.Background(), acc0.Address, nil)
+	require.NoError(t, err)
+	require.Negative(t, balance.Cmp(big.NewInt(params.Ether)))
+
+	// The pool sees the sweep once it refreshes its accounts.
+	require.Eventually(t, func() bool {
+		return poolAccounts(t, srv)[0].Balance == record.Swept
+	}, 5*time.Second, 50*time.Millisecond)
+	require.Equal(t, acc2.Address, fund(TestAddr4))
+}
+
+func Test_AccountRotationResume(t *testing.T) {
+	acc0, err := data.NewAccount(FaucetPrivateKey)
+	require.NoError(t, err)
+	acc1, err := data.NewAccount(poolPrivateKey1)
+	require.NoError(t, err)
+	acc2, err := data.NewAccount(poolPrivateKey2)
+	require.NoError(t, err)
+
+	funds := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	sim := newSimulatedBackend(t, core.GenesisAlloc{
+		acc0.Address: {Balance: funds},
+		acc1.Address: {Balance: funds},
+	})
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 100,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{acc0, acc1},
+		ChainID:              simulatedChainID,
+		AdminToken:           adminToken,
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	ctx, stop := context.WithCancel(context.Background())
+	srv := handler.FaucetHandler(ctx, logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	// The first account has a pending transaction when it is rotated, and the faucet stops while it drains.
+	w := post(t, srv, "/fund", data.FundRequest{Address: TestAddr2})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	rotate := "/admin/accounts/" + acc0.Address.Hex() + "/rotate"
+	w = admin(t, srv, http.MethodPost, rotate, data.RotateAccountRequest{AccountKey: keyFile(t, poolPrivateKey2), Sweep: true})
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	stop()
+
+	// The next run takes the configured old account out of the pool again, and finishes the rotation.
+	srv = handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	accounts := poolAccounts(t, srv)
+	require.Len(t, accounts, 1)
+	require.Equal(t, acc1.Address.Hex(), accounts[0].Address)
+
+	rotations := func() []data.RotationRecord {
+		w := admin(t, srv, http.MethodGet, "/admin/rotations", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var records []data.RotationRecord
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &records))
+		return records
+	}
+	require.Equal(t, faucet.RotationDraining, rotations()[0].Status)
+
+	sim.Commit()
+	require.Eventually(t, func() bool {
+		return rotations()[0].Status != faucet.RotationDraining
+	}, 10*time.Second, 100*time.Millisecond)
+
+	record := rotations()[0]
+	require.Equal(t, faucet.RotationCompleted, record.Status, record.Error)
+	require.NotEmpty(t, record.TxHash)
+	sim.Commit()
+
+	balance, err := sim.BalanceAt(context.Background(), acc2.Address, nil)
+	require.NoError(t, err)
+	require.Equal(t, record.Swept, balance.String())
+}
@@ -0,0 +1,195 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+)
+
+// signTxArgs are the arguments of account_signTransaction and eth_signTransaction.
+type signTxArgs struct {
+	From                 common.Address  `json:"from"`
+	To                   *common.Address `json:"to"`
+	Gas                  hexutil.Uint64  `json:"gas"`
+	MaxFeePerGas         *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big    `json:"value"`
+	Nonce                hexutil.Uint64  `json:"nonce"`
+	Data                 hexutil.Bytes   `json:"data"`
+	ChainID              *hexutil.Big    `json:"chainId"`
+}
+
+// fakeSigner is an external signer holding a key, served in-process.
+type fakeSigner struct {
+	key *ecdsa.PrivateKey
+	// tamper makes the signer sign another value than the requested one.
+	tamper bool
+	calls  int
+}
+
+func (f *fakeSigner) sign(args signTxArgs) (*types.Transaction, error) {
+	f.calls++
+	value := (*big.Int)(args.Value)
+	if f.tamper {
+		value = new(big.Int).Add(value, big.NewInt(1))
+	}
+	chainID := (*big.Int)(args.ChainID)
+	return types.SignNewTx(f.key, types.LatestSignerForChainID(chainID), &types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     uint64(args.Nonce),
+		GasTipCap: (*big.Int)(args.MaxPriorityFeePerGas),
+		GasFeeCap: (*big.Int)(args.MaxFeePerGas),
+		Gas:       uint64(args.Gas),
+		To:        args.To,
+		Value:     value,
+		Data:      args.Data,
+	})
+}
+
+// signTypedData signs the typed data with a recovery id of 27 or 28, as Clef does.
+func (f *fakeSigner) signTypedData(typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	f.calls++
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(hash, f.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+// clefAPI serves account_signTransaction, which returns the raw and the decoded transaction.
+type clefAPI struct{ *fakeSigner }
+
+type clefResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+func (a clefAPI) SignTransaction(args signTxArgs) (*clefResult, error) {
+	tx, err := a.sign(args)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return &clefResult{Raw: raw, Tx: tx}, nil
+}
+
+func (a clefAPI) SignTypedData(_ common.Address, typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	return a.signTypedData(typedData)
+}
+
+// ethAPI serves eth_signTransaction the way Web3Signer does, returning the raw transaction only.
+type ethAPI struct{ *fakeSigner }
+
+func (a ethAPI) SignTransaction(args signTxArgs) (hexutil.Bytes, error) {
+	tx, err := a.sign(args)
+	if err != nil {
+		return nil, err
+	}
+	return tx.MarshalBinary()
+}
+
+func (a ethAPI) SignTypedData(_ common.Address, typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	return a.signTypedData(typedData)
+}
+
+// newFakeSigner serves a signer of a new key funded by the faucet account.
+func newFakeSigner(t *testing.T, sim *backends.SimulatedBackend, funder *ecdsa.PrivateKey) (*fakeSigner, *rpc.Server) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sendValue(t, sim, funder, crypto.PubkeyToAddress(key.PublicKey), faucet.TransferAmount(100))
+
+	f := &fakeSigner{key: key}
+	srv := rpc.NewServer()
+	require.NoError(t, srv.RegisterName("account", clefAPI{f}))
+	require.NoError(t, srv.RegisterName("eth", ethAPI{f}))
+	t.Cleanup(srv.Stop)
+	return f, srv
+}
+
+func Test_RemoteSigner(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+
+	for _, method := range []string{faucet.ClefSignMethod, faucet.EthSignMethod} {
+		t.Run(method, func(t *testing.T) {
+			f, signerSrv := newFakeSigner(t, sim, account.PrivateKey)
+			addr := crypto.PubkeyToAddress(f.key.PublicKey)
+
+			// The faucet holds no key.
+			cfg := faucet.Config{
+				TotalTransferLimit:   1000,
+				AddressTransferLimit: 100,
+				TransferAmount:       10,
+				Signers:              []faucet.Signer{faucet.NewRemoteSigner(rpc.DialInProc(signerSrv), method, addr)},
+				ChainID:              simulatedChainID,
+			}
+
+			store := dssync.MutexWrap(datastore.NewMapDatastore())
+			srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+			key, err := crypto.GenerateKey()
+			require.NoError(t, err)
+			to := crypto.PubkeyToAddress(key.PublicKey)
+
+			w := post(t, srv, "/fund", data.FundRequest{Address: to.Hex()})
+			require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+			require.Equal(t, 1, f.calls)
+
+			var resp data.FundResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			sim.Commit()
+
+			tx, _, err := sim.TransactionByHash(context.Background(), common.HexToHash(resp.TxHash))
+			require.NoError(t, err)
+			from, err := types.Sender(types.LatestSignerForChainID(simulatedChainID), tx)
+			require.NoError(t, err)
+			require.Equal(t, addr, from)
+
+			balance, err := sim.BalanceAt(context.Background(), to, nil)
+			require.NoError(t, err)
+			require.Equal(t, faucet.TransferAmount(10), balance)
+
+			// A transaction altered by the signer is not sent, and the quota is not charged.
+			f.tamper = true
+			w = post(t, srv, "/fund", data.FundRequest{Address: TestAddr2})
+			require.Equal(t, http.StatusInternalServerError, w.Code, w.Body.String())
+			require.Zero(t, accountInfo(t, srv, TestAddr2, "").Received)
+
+			// An unreachable signer makes the faucet unavailable.
+			f.tamper = false
+			signerSrv.Stop()
+			w = post(t, srv, "/fund", data.FundRequest{Address: TestAddr2})
+			require.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+
+			nonce, err := sim.PendingNonceAt(context.Background(), addr)
+			require.NoError(t, err)
+			require.Equal(t, uint64(1), nonce)
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/asm"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+)
+
+const simulatedGasLimit = 30_000_000
+
+// simulatedChainID is the chain ID used by backends.SimulatedBackend.
+var simulatedChainID = params.AllEthashProtocolChanges.ChainID
+
+// newSimulatedChain returns an in-memory chain where the faucet account and the test accounts are funded.
+func newSimulatedChain(t *testing.T) (*backends.SimulatedBackend, *data.EthereumAccount) {
+	account, err := data.NewAccount(FaucetPrivateKey)
+	require.NoError(t, err)
+
+	funds := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	sim := newSimulatedBackend(t, core.GenesisAlloc{
+		account.Address: {Balance: funds},
+	})
+
+	return sim, account
+}
+
+// newSimulatedBackend returns an in-memory chain with the genesis allocation, which is closed with the test.
+func newSimulatedBackend(t *testing.T, alloc core.GenesisAlloc) *backends.SimulatedBackend {
+	sim := backends.NewSimulatedBackend(alloc, simulatedGasLimit)
+
+	t.Cleanup(func() {
+		err := sim.Close()
+		require.NoError(t, err)
+	})
+
+	return sim
+}
+
+// newSimulatedFaucet returns an in-memory chain with the genesis allocation, and the handler of a faucet
+// with the configuration and an empty store, which runs on the chain until the test ends.
+func newSimulatedFaucet(t *testing.T, alloc core.GenesisAlloc, cfg *faucet.Config) (*backends.SimulatedBackend, http.Handler) {
+	sim := newSimulatedBackend(t, alloc)
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", cfg)
+	return sim, srv
+}
+
+// serviceContext returns the lifetime of the faucet services of the test, which ends with the test.
+func serviceContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// compileAsm compiles EVM assembly into bytecode.
+func compileAsm(t *testing.T, src string) []byte {
+	c := asm.NewCompiler(false)
+	c.Feed(asm.Lex([]byte(src), false))
+	out, errs := c.Compile()
+	require.Empty(t, errs)
+
+	code, err := hex.DecodeString(out)
+	require.NoError(t, err)
+	return code
+}
+
+// deployCode returns init code that runs the constructor and deploys the runtime code.
+func deployCode(t *testing.T, constructor string, runtime []byte) []byte {
+	initCode := compileAsm(t, constructor+`
+	PUSH `+big.NewInt(int64(len(runtime))).String()+`
+	DUP1
+	PUSH @runtime
+	PUSH 1
+	ADD
+	PUSH 0
+	CODECOPY
+	PUSH 0
+	RETURN
+runtime:
+`)
+	return append(initCode, runtime...)
+}
+
+// deployContract deploys the contract from the key and mines it.
+func deployContract(t *testing.T, sim *backends.SimulatedBackend, key *ecdsa.PrivateKey, code []byte, value *big.Int) common.Address {
+	ctx := context.Background()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	nonce, err := sim.PendingNonceAt(ctx, from)
+	require.NoError(t, err)
+
+	head, err := sim.HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(simulatedChainID), &types.DynamicFeeTx{
+		ChainID:   simulatedChainID,
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), big.NewInt(params.GWei)),
+		Gas:       5_000_000,
+		Value:     value,
+		Data:      code,
+	})
+	require.NoError(t, err)
+
+	err = sim.SendTransaction(ctx, tx)
+	require.NoError(t, err)
+	sim.Commit()
+
+	receipt, err := sim.TransactionReceipt(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	return receipt.ContractAddress
+}
@@ -0,0 +1,215 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+)
+
+// mockERC20Runtime implements balanceOf(address) and transfer(address,uint256).
+// Balances are stored in the storage slot equal to the holder address.
+var mockERC20Runtime = fmt.Sprintf(`
+	PUSH 0
+	CALLDATALOAD
+	PUSH 224
+	SHR
+	DUP1
+	PUSH 0xa9059cbb
+	EQ
+	JUMPI @transfer
+	DUP1
+	PUSH 0x70a08231
+	EQ
+	JUMPI @balanceOf
+	PUSH 0
+	DUP1
+	REVERT
+
+balanceOf:
+	PUSH 4
+	CALLDATALOAD
+	SLOAD
+	PUSH 0
+	MSTORE
+	PUSH 32
+	PUSH 0
+	RETURN
+
+transfer:
+	CALLER
+	SLOAD
+	PUSH 36
+	CALLDATALOAD
+	DUP1
+	DUP3
+	LT
+	JUMPI @fail
+	SWAP1
+	DUP2
+	SWAP1
+	SUB
+	CALLER
+	SSTORE
+	PUSH 4
+	CALLDATALOAD
+	DUP1
+	SLOAD
+	DUP3
+	ADD
+	SWAP1
+	SSTORE
+	PUSH 0
+	MSTORE
+	PUSH 4
+	CALLDATALOAD
+	CALLER
+	PUSH %s
+	PUSH 32
+	PUSH 0
+	LOG3
+	PUSH 1
+	PUSH 0
+	MSTORE
+	PUSH 32
+	PUSH 0
+	RETURN
+
+fail:
+	PUSH 0
+	DUP1
+	REVERT
+`, crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")).Hex())
+
+const (
+	mockTokenSymbol   = "USDC"
+	mockTokenDecimals = 6
+)
+
+// deployMockERC20 deploys a token that mints the supply to the faucet account.
+func deployMockERC20(t *testing.T, sim *backends.SimulatedBackend, account *data.EthereumAccount, supply *big.Int) common.Address {
+	runtime := compileAsm(t, mockERC20Runtime)
+	code := deployCode(t, fmt.Sprintf(`
+	PUSH %s
+	CALLER
+	SSTORE
+`, supply.String()), runtime)
+	return deployContract(t, sim, account.PrivateKey, code, nil)
+}
+
+func tokenBalance(t *testing.T, sim *backends.SimulatedBackend, token, holder common.Address) *big.Int {
+	input := append(crypto.Keccak256([]byte("balanceOf(address)"))[:4], common.LeftPadBytes(holder.Bytes(), 32)...)
+	out, err := sim.CallContract(context.Background(), ethereum.CallMsg{To: &token, Data: input}, nil)
+	require.NoError(t, err)
+	return new(big.Int).SetBytes(out)
+}
+
+func Test_TokenFaucet(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+
+	supply := faucet.TokenAmount(1_000_000, mockTokenDecimals)
+	token := deployMockERC20(t, sim, account, supply)
+	require.Equal(t, supply, tokenBalance(t, sim, token, account.Address))
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 50,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		Tokens: []faucet.TokenConfig{{
+			Symbol:               mockTokenSymbol,
+			Address:              token,
+			Decimals:             mockTokenDecimals,
+			TransferAmount:       100,
+			AddressTransferLimit: 200,
+			TotalTransferLimit:   1000,
+		}},
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	recipient := common.HexToAddress(TestAddr2)
+	transferAmount := faucet.TokenAmount(100, mockTokenDecimals)
+
+	for i := 1; i <= 2; i++ {
+		w := fundAsset(t, srv, TestAddr2, "usdc")
+		require.Equal(t, http.StatusCreated, w.Code)
+		sim.Commit()
+
+		var resp data.FundResponse
+		err := json.Unmarshal(w.Body.Bytes(), &resp)
+		require.NoError(t, err)
+		require.Equal(t, mockTokenSymbol, resp.Asset)
+
+		receipt, err := sim.TransactionReceipt(context.Background(), common.HexToHash(resp.TxHash))
+		require.NoError(t, err)
+		require.Len(t, receipt.Logs, 1)
+
+		exp := new(big.Int).Mul(transferAmount, big.NewInt(int64(i)))
+		require.Equal(t, exp, tokenBalance(t, sim, token, recipient))
+	}
+
+	w := fundAsset(t, srv, TestAddr2, mockTokenSymbol)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	// Native coin quota is tracked separately.
+	w = fundAsset(t, srv, TestAddr2, "")
+	require.Equal(t, http.StatusCreated, w.Code)
+	sim.Commit()
+
+	info := accountInfo(t, srv, TestAddr2, mockTokenSymbol)
+	require.Equal(t, uint64(200), info.Received)
+	require.Equal(t, uint64(0), info.Remaining)
+	require.Equal(t, uint64(800), info.TotalRemaining)
+	require.Equal(t, new(big.Int).Mul(transferAmount, big.NewInt(2)).String(), info.Balance)
+
+	info = accountInfo(t, srv, TestAddr2, "")
+	require.Equal(t, faucet.NativeAsset, info.Asset)
+	require.Equal(t, uint64(10), info.Received)
+
+	w = fundAsset(t, srv, TestAddr2, "DAI")
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func fundAsset(t *testing.T, h http.Handler, addr, asset string) *httptest.ResponseRecorder {
+	body, err := json.Marshal(&data.FundRequest{Address: addr, Asset: asset})
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, "/fund", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func accountInfo(t *testing.T, h http.Handler, addr, asset string) data.AccountResponse {
+	r := httptest.NewRequest(http.MethodGet, "/accounts/"+addr+"?asset="+asset, nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp data.AccountResponse
+	err := json.Unmarshal(w.Body.Bytes(), &resp)
+	require.NoError(t, err)
+	return resp
+}
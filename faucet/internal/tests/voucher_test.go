@@ -0,0 +1,356 @@
+package tests
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/consensus-shipyard/calibration/faucet/internal/contract"
+	"github.com/consensus-shipyard/calibration/faucet/internal/data"
+	"github.com/consensus-shipyard/calibration/faucet/internal/faucet"
+	handler "github.com/consensus-shipyard/calibration/faucet/internal/http"
+)
+
+func Test_ClaimVouchers(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+
+	owner, err := bind.NewKeyedTransactorWithChainID(account.PrivateKey, simulatedChainID)
+	require.NoError(t, err)
+
+	addr, _, claimContract, err := contract.DeployClaim(owner, sim, account.Address)
+	require.NoError(t, err)
+	sim.Commit()
+
+	owner.Value = faucet.TransferAmount(100)
+	_, err = claimContract.Receive(owner)
+	require.NoError(t, err)
+	sim.Commit()
+
+	// Vouchers are redeemed by a third party that pays for gas.
+	relayer, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sendValue(t, sim, account.PrivateKey, crypto.PubkeyToAddress(relayer.PublicKey), faucet.TransferAmount(1))
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 100,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		Vouchers: &faucet.VoucherConfig{
+			Contract: addr,
+			Signer:   faucet.NewLocalSigner(account),
+			TTL:      time.Hour,
+			Interval: 10 * time.Millisecond,
+		},
+		Bundles: []faucet.BundleConfig{{
+			Name: "starter",
+			Legs: []faucet.BundleLeg{{Asset: faucet.NativeAsset, Amount: 5}},
+		}},
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	fund := func(to common.Address) data.Voucher {
+		w := post(t, srv, "/fund", data.FundRequest{Address: to.Hex()})
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var resp data.FundResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Empty(t, resp.TxHash)
+		require.NotNil(t, resp.Voucher)
+		return *resp.Voucher
+	}
+
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	to := crypto.PubkeyToAddress(key.PublicKey)
+	v := fund(to)
+	require.Equal(t, addr.Hex(), v.Contract)
+	require.Equal(t, simulatedChainID.Uint64(), v.ChainID)
+	require.Equal(t, to.Hex(), v.Recipient)
+	require.Equal(t, faucet.TransferAmount(10).String(), v.Amount)
+
+	// The signature recovers to the signer over the EIP-712 hash of the voucher.
+	nonce, ok := new(big.Int).SetString(v.Nonce, 10)
+	require.True(t, ok)
+	hash, _, err := apitypes.TypedDataAndHash(faucet.VoucherTypedData(simulatedChainID, addr, to, faucet.TransferAmount(10), nonce, v.Expiry))
+	require.NoError(t, err)
+	sig := hexutil.MustDecode(v.Signature)
+	sig[crypto.RecoveryIDOffset] -= 27
+	pub, err := crypto.SigToPub(hash, sig)
+	require.NoError(t, err)
+	require.Equal(t, account.Address, crypto.PubkeyToAddress(*pub))
+
+	// The quota is charged when the voucher is issued.
+	require.Equal(t, uint64(10), accountInfo(t, srv, to.Hex(), "").Received)
+	require.Equal(t, faucet.VoucherIssued, voucher(t, srv, v.Nonce).Status)
+
+	// The faucet doesn't pay anything until the voucher is redeemed.
+	balance, err := sim.BalanceAt(context.Background(), to, nil)
+	require.NoError(t, err)
+	require.Zero(t, balance.Sign())
+
+	redeem(t, sim, relayer, addr, hexutil.MustDecode(v.Calldata))
+
+	balance, err = sim.BalanceAt(context.Background(), to, nil)
+	require.NoError(t, err)
+	require.Equal(t, faucet.TransferAmount(10), balance)
+	require.Equal(t, faucet.VoucherClaimed, voucher(t, srv, v.Nonce).Status)
+
+	// An expired voucher that was not redeemed is credited back to the quota.
+	key, err = crypto.GenerateKey()
+	require.NoError(t, err)
+	other := crypto.PubkeyToAddress(key.PublicKey)
+	expired := fund(other)
+	require.Equal(t, uint64(10), accountInfo(t, srv, other.Hex(), "").Received)
+
+	head, err := sim.HeaderByNumber(context.Background(), nil)
+	require.NoError(t, err)
+	require.NoError(t, sim.AdjustTime(time.Duration(expired.Expiry-int64(head.Time)+1)*time.Second))
+	sim.Commit()
+
+	require.Eventually(t, func() bool {
+		return voucher(t, srv, expired.Nonce).Status == faucet.VoucherCredited
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Zero(t, accountInfo(t, srv, other.Hex(), "").Received)
+	require.NotNil(t, voucher(t, srv, expired.Nonce).Resolved)
+
+	// The redeemed voucher is resolved without a credit.
+	require.Eventually(t, func() bool {
+		return voucher(t, srv, v.Nonce).Resolved != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, faucet.VoucherClaimed, voucher(t, srv, v.Nonce).Status)
+	require.Equal(t, uint64(10), accountInfo(t, srv, to.Hex(), "").Received)
+
+	// The native coin of a bundle can't bypass the vouchers.
+	w := fundBundle(t, srv, other.Hex(), "starter")
+	require.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	require.Zero(t, accountInfo(t, srv, other.Hex(), "").Received)
+
+	r := httptest.NewRequest(http.MethodGet, "/vouchers/1", nil)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, r)
+	require.Equal(t, http.StatusNotFound, w.Code)
+
+	r = httptest.NewRequest(http.MethodGet, "/vouchers/abc", nil)
+	w = httptest.NewRecorder()
+	srv.ServeHTTP(w, r)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func Test_OutstandingVouchers(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+
+	owner, err := bind.NewKeyedTransactorWithChainID(account.PrivateKey, simulatedChainID)
+	require.NoError(t, err)
+
+	addr, _, claimContract, err := contract.DeployClaim(owner, sim, account.Address)
+	require.NoError(t, err)
+	sim.Commit()
+
+	owner.Value = faucet.TransferAmount(25)
+	_, err = claimContract.Receive(owner)
+	require.NoError(t, err)
+	sim.Commit()
+
+	relayer, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	sendValue(t, sim, account.PrivateKey, crypto.PubkeyToAddress(relayer.PublicKey), faucet.TransferAmount(1))
+
+	cfg := faucet.Config{
+		TotalTransferLimit:   1000,
+		AddressTransferLimit: 100,
+		TransferAmount:       10,
+		Accounts:             []*data.EthereumAccount{account},
+		ChainID:              simulatedChainID,
+		Vouchers: &faucet.VoucherConfig{
+			Contract: addr,
+			Signer:   faucet.NewLocalSigner(account),
+			TTL:      time.Hour,
+			Interval: 50 * time.Millisecond,
+		},
+	}
+
+	store := dssync.MutexWrap(datastore.NewMapDatastore())
+	srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+	fund := func(to string) (int, data.Voucher) {
+		w := post(t, srv, "/fund", data.FundRequest{Address: to})
+		var resp data.FundResponse
+		if w.Code == http.StatusCreated {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.NotNil(t, resp.Voucher)
+			return w.Code, *resp.Voucher
+		}
+		return w.Code, data.Voucher{}
+	}
+
+	code, claimed := fund(TestAddr2)
+	require.Equal(t, http.StatusCreated, code)
+	code, unclaimed := fund(TestAddr3)
+	require.Equal(t, http.StatusCreated, code)
+
+	// The contract holds 25, and 20 are owed to the issued vouchers.
+	code, _ = fund(TestAddr4)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+
+	// A redeemed voucher is paid out of the balance, so it no longer counts once its claim is seen.
+	redeem(t, sim, relayer, addr, hexutil.MustDecode(claimed.Calldata))
+	require.Eventually(t, func() bool {
+		return voucher(t, srv, claimed.Nonce).Resolved != nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, faucet.VoucherClaimed, voucher(t, srv, claimed.Nonce).Status)
+
+	// The contract holds 15, and 10 are owed.
+	code, _ = fund(TestAddr4)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+
+	owner.Value = faucet.TransferAmount(5)
+	_, err = claimContract.Receive(owner)
+	require.NoError(t, err)
+	sim.Commit()
+
+	code, _ = fund(TestAddr4)
+	require.Equal(t, http.StatusCreated, code)
+	code, _ = fund(TestAddr1)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+
+	// An expired voucher can't be claimed anymore.
+	head, err := sim.HeaderByNumber(context.Background(), nil)
+	require.NoError(t, err)
+	require.NoError(t, sim.AdjustTime(time.Duration(unclaimed.Expiry-int64(head.Time)+1)*time.Second))
+	sim.Commit()
+
+	code, _ = fund(TestAddr1)
+	require.Equal(t, http.StatusCreated, code)
+}
+
+func Test_RemoteVoucherSigner(t *testing.T) {
+	sim, account := newSimulatedChain(t)
+
+	for _, method := range []string{faucet.ClefSignMethod, faucet.EthSignMethod} {
+		t.Run(method, func(t *testing.T) {
+			f, signerSrv := newFakeSigner(t, sim, account.PrivateKey)
+			signer := crypto.PubkeyToAddress(f.key.PublicKey)
+
+			owner, err := bind.NewKeyedTransactorWithChainID(account.PrivateKey, simulatedChainID)
+			require.NoError(t, err)
+			addr, _, claimContract, err := contract.DeployClaim(owner, sim, signer)
+			require.NoError(t, err)
+			sim.Commit()
+
+			owner.Value = faucet.TransferAmount(100)
+			_, err = claimContract.Receive(owner)
+			require.NoError(t, err)
+			sim.Commit()
+
+			relayer, err := crypto.GenerateKey()
+			require.NoError(t, err)
+			sendValue(t, sim, account.PrivateKey, crypto.PubkeyToAddress(relayer.PublicKey), faucet.TransferAmount(1))
+
+			// The faucet holds no key, the vouchers are signed by the external signer.
+			remote := faucet.NewRemoteSigner(rpc.DialInProc(signerSrv), method, signer)
+			cfg := faucet.Config{
+				TotalTransferLimit:   1000,
+				AddressTransferLimit: 100,
+				TransferAmount:       10,
+				Signers:              []faucet.Signer{remote},
+				ChainID:              simulatedChainID,
+				Vouchers: &faucet.VoucherConfig{
+					Contract: addr,
+					Signer:   remote,
+					TTL:      time.Hour,
+					Interval: time.Hour,
+				},
+			}
+
+			store := dssync.MutexWrap(datastore.NewMapDatastore())
+			srv := handler.FaucetHandler(serviceContext(t), logging.Logger("TEST-FAUCET"), sim, store, "0.0.1", &cfg)
+
+			key, err := crypto.GenerateKey()
+			require.NoError(t, err)
+			to := crypto.PubkeyToAddress(key.PublicKey)
+
+			w := post(t, srv, "/fund", data.FundRequest{Address: to.Hex()})
+			require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+			require.Equal(t, 1, f.calls)
+
+			var resp data.FundResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			require.NotNil(t, resp.Voucher)
+
+			redeem(t, sim, relayer, addr, hexutil.MustDecode(resp.Voucher.Calldata))
+			balance, err := sim.BalanceAt(context.Background(), to, nil)
+			require.NoError(t, err)
+			require.Equal(t, faucet.TransferAmount(10), balance)
+
+			// An unreachable signer makes the faucet unavailable, and the quota is not charged.
+			signerSrv.Stop()
+			w = post(t, srv, "/fund", data.FundRequest{Address: TestAddr2})
+			require.Equal(t, http.StatusServiceUnavailable, w.Code, w.Body.String())
+			require.Zero(t, accountInfo(t, srv, TestAddr2, "").Received)
+		})
+	}
+}
+
+func voucher(t *testing.T, h http.Handler, nonce string) data.VoucherRecord {
+	r := httptest.NewRequest(http.MethodGet, "/vouchers/"+nonce, nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, r)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var rec data.VoucherRecord
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rec))
+	return rec
+}
+
+// redeem sends the claim calldata of a voucher from the key and mines it.
+func redeem(t *testing.T, sim *backends.SimulatedBackend, key *ecdsa.PrivateKey, claim common.Address, input []byte) {
+	ctx := context.Background()
+
+	nonce, err := sim.PendingNonceAt(ctx, crypto.PubkeyToAddress(key.PublicKey))
+	require.NoError(t, err)
+
+	head, err := sim.HeaderByNumber(ctx, nil)
+	require.NoError(t, err)
+
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(simulatedChainID), &types.DynamicFeeTx{
+		ChainID:   simulatedChainID,
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(params.GWei),
+		GasFeeCap: new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), big.NewInt(params.GWei)),
+		Gas:       200_000,
+		To:        &claim,
+		Data:      input,
+	})
+	require.NoError(t, err)
+
+	require.NoError(t, sim.SendTransaction(ctx, tx))
+	sim.Commit()
+
+	receipt, err := sim.TransactionReceipt(ctx, tx.Hash())
+	require.NoError(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+}
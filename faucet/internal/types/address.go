@@ -0,0 +1,190 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/filecoin-project/go-address"
+)
+
+// FirstNonSingletonActorID is the first actor ID that is not reserved for built-in singleton actors.
+const FirstNonSingletonActorID = 100
+
+// AddressError describes why an address is rejected. It matches ErrInvalidAddress.
+type AddressError struct {
+	Address string
+	Reason  string
+}
+
+func (e *AddressError) Error() string {
+	return fmt.Sprintf("invalid address %q: %s", e.Address, e.Reason)
+}
+
+func (e *AddressError) Is(target error) bool {
+	return target == ErrInvalidAddress
+}
+
+func addressError(addr, format string, args ...any) error {
+	return &AddressError{Address: addr, Reason: fmt.Sprintf(format, args...)}
+}
+
+// AddressOptions define which valid addresses are accepted as recipients.
+type AddressOptions struct {
+	// NetworkPrefix is the prefix Filecoin addresses must use. An empty prefix accepts f and t.
+	NetworkPrefix string
+	// RequireChecksum rejects 0x addresses without an EIP-55 checksum.
+	RequireChecksum bool
+}
+
+// Address is a parsed 0x, f0, f1, f3 or f4 address, optionally qualified by an IPC subnet.
+// Addresses with an Ethereum form are converted into it, f1 and f3 addresses are kept as Filecoin addresses.
+type Address struct {
+	Eth      common.Address
+	Filecoin address.Address
+	Subnet   *SubnetID
+
+	raw string
+}
+
+// ParseAddress parses the address and validates its syntax:
+// the length and characters of 0x addresses, the EIP-55 checksum of mixed-case 0x addresses,
+// and the network prefix, protocol and checksum of Filecoin addresses.
+func ParseAddress(s string) (Address, error) {
+	var a Address
+	if IsIPCAddress(s) {
+		ipcAddr, err := ParseIPCAddress(s)
+		if err != nil {
+			return Address{}, err
+		}
+		a.Subnet, s = &ipcAddr.Subnet, ipcAddr.Raw
+	}
+	a.raw = s
+
+	if s == "" {
+		return Address{}, addressError(s, "empty address")
+	}
+
+	if has0xPrefix(s) {
+		eth, err := parseHexAddress(s)
+		if err != nil {
+			return Address{}, err
+		}
+		a.Eth = eth
+		return a, nil
+	}
+
+	if p := s[:1]; p != address.MainnetPrefix && p != address.TestnetPrefix {
+		return Address{}, addressError(s, "unknown network prefix %q, expected 0x, f or t", p)
+	}
+
+	filecoinAddr, err := address.NewFromString(s)
+	if err != nil {
+		return Address{}, addressError(s, "%v", err)
+	}
+
+	switch filecoinAddr.Protocol() {
+	case address.SECP256K1, address.BLS:
+		a.Filecoin = filecoinAddr
+	case address.Actor:
+		// Actors hold balances, but the faucet only funds them by their ID or Ethereum address.
+		return Address{}, addressError(s, "the faucet doesn't fund f2 actor addresses, use the f0 ID address of the actor instead")
+	default:
+		ethAddr, err := EthAddressFromFilecoinAddress(filecoinAddr)
+		if err != nil {
+			return Address{}, addressError(s, "%v", err)
+		}
+		a.Eth = common.Address(ethAddr)
+	}
+	return a, nil
+}
+
+// Check validates the address as a recipient. It rejects Filecoin addresses of another network,
+// 0x addresses without a checksum if one is required, and the zero, precompile and built-in actor addresses.
+func (a Address) Check(opts AddressOptions) error {
+	if has0xPrefix(a.raw) {
+		if opts.RequireChecksum && a.raw[2:] != a.Eth.Hex()[2:] {
+			return addressError(a.raw, "address is not EIP-55 checksummed, expected %s", a.Eth.Hex())
+		}
+	} else if opts.NetworkPrefix != "" && !strings.HasPrefix(a.raw, opts.NetworkPrefix) {
+		return addressError(a.raw, "network prefix %q doesn't match the network prefix %q", a.raw[:1], opts.NetworkPrefix)
+	}
+
+	if a.IsFilecoin() {
+		return nil
+	}
+
+	switch {
+	case a.Eth == (common.Address{}):
+		return addressError(a.raw, "zero address")
+	case isPrecompile(a.Eth):
+		return addressError(a.raw, "precompile address")
+	case EthAddress(a.Eth).IsMaskedID():
+		if id := binary.BigEndian.Uint64(a.Eth[12:]); id < FirstNonSingletonActorID {
+			return addressError(a.raw, "reserved built-in actor f0%d", id)
+		}
+	}
+	return nil
+}
+
+// IsFilecoin reports whether the address has no Ethereum form.
+func (a Address) IsFilecoin() bool {
+	return a.Filecoin != address.Undef
+}
+
+func (a Address) String() string {
+	if a.IsFilecoin() {
+		return a.Filecoin.String()
+	}
+	return a.Eth.String()
+}
+
+func has0xPrefix(s string) bool {
+	return len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X')
+}
+
+// parseHexAddress parses a 0x address and validates the checksum if the address is mixed-case.
+func parseHexAddress(s string) (common.Address, error) {
+	hex := s[2:]
+	if len(hex) != EthAddressHexLength {
+		return common.Address{}, addressError(s, "expected %d hex characters, got %d", EthAddressHexLength, len(hex))
+	}
+
+	var lower, upper bool
+	for i, c := range hex {
+		switch {
+		case c >= '0' && c <= '9':
+		case c >= 'a' && c <= 'f':
+			lower = true
+		case c >= 'A' && c <= 'F':
+			upper = true
+		default:
+			return common.Address{}, addressError(s, "invalid hex character %q at position %d", c, i+2)
+		}
+	}
+
+	addr := common.HexToAddress(hex)
+	if lower && upper && hex != addr.Hex()[2:] {
+		return common.Address{}, addressError(s, "invalid EIP-55 checksum, expected %s", addr.Hex())
+	}
+	return addr, nil
+}
+
+// isPrecompile reports whether the address is an Ethereum precompile (0x01-0x0a)
+// or a Filecoin precompile (0xfe00..01-0xfe00..ff).
+func isPrecompile(addr common.Address) bool {
+	for _, b := range addr[1:19] {
+		if b != 0 {
+			return false
+		}
+	}
+	switch addr[0] {
+	case 0x00:
+		return addr[19] <= 0x0a
+	case 0xfe:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,91 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testChecksumAddr = "0xFFcf8FDEE72ac11b5c542428B35EEF5769C409f0"
+	testF1Addr       = "t1d2xrzcslx7xlbbylc5c3d5lvandqw4iwl6epxba"
+)
+
+func TestParseAddress(t *testing.T) {
+	a, err := ParseAddress(testChecksumAddr)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress(testChecksumAddr), a.Eth)
+	require.False(t, a.IsFilecoin())
+	require.Nil(t, a.Subnet)
+
+	a, err = ParseAddress(testSubnetAddr)
+	require.NoError(t, err)
+	require.Equal(t, common.HexToAddress("0xffcf8fdee72ac11b5c542428b35eef5769c409f0"), a.Eth)
+
+	a, err = ParseAddress("t01234")
+	require.NoError(t, err)
+	require.True(t, EthAddress(a.Eth).IsMaskedID())
+
+	a, err = ParseAddress(testF1Addr)
+	require.NoError(t, err)
+	require.True(t, a.IsFilecoin())
+	require.Equal(t, testF1Addr, a.String())
+
+	a, err = ParseAddress(testSubnet + ":" + testChecksumAddr)
+	require.NoError(t, err)
+	require.Equal(t, testSubnet, a.Subnet.String())
+	require.Equal(t, testChecksumAddr, a.String())
+}
+
+func TestParseAddressErrors(t *testing.T) {
+	for s, reason := range map[string]string{
+		"":   "empty address",
+		"0x": "expected 40 hex characters, got 0",
+		"0xFFcf8FDEE72ac11b5c542428B35EEF5769C409f":   "expected 40 hex characters, got 39",
+		"0xFFcf8FDEE72ac11b5c542428B35EEF5769C409f00": "expected 40 hex characters, got 41",
+		"0xffcf8fdee72ac11b5c542428b35eef5769c409zz":  "invalid hex character 'z' at position 40",
+		"0xFfcf8FDEE72ac11b5c542428B35EEF5769C409f0":  "invalid EIP-55 checksum, expected " + testChecksumAddr,
+		"x1234": `unknown network prefix "x"`,
+		"t2zb5nh227tbuozvafissmihwja55y3r5uuwx5cdy":    "doesn't fund f2 actor addresses",
+		"t410f77hy7xxhflarwxcuequlgxxpk5u4icpqb4cl5hq": "checksum",
+	} {
+		_, err := ParseAddress(s)
+		require.ErrorIs(t, err, ErrInvalidAddress, s)
+		require.ErrorContains(t, err, reason, s)
+	}
+}
+
+func TestCheckAddress(t *testing.T) {
+	check := func(s string, opts AddressOptions) error {
+		a, err := ParseAddress(s)
+		require.NoError(t, err, s)
+		return a.Check(opts)
+	}
+
+	require.NoError(t, check(testChecksumAddr, AddressOptions{RequireChecksum: true}))
+	require.NoError(t, check("0xffcf8fdee72ac11b5c542428b35eef5769c409f0", AddressOptions{}))
+	require.ErrorContains(t, check("0xffcf8fdee72ac11b5c542428b35eef5769c409f0", AddressOptions{RequireChecksum: true}),
+		"not EIP-55 checksummed")
+
+	require.NoError(t, check(testSubnetAddr, AddressOptions{NetworkPrefix: "t"}))
+	require.NoError(t, check(testSubnetAddr, AddressOptions{}))
+	require.ErrorContains(t, check(testSubnetAddr, AddressOptions{NetworkPrefix: "f"}), `doesn't match the network prefix "f"`)
+	require.ErrorContains(t, check("f"+testF1Addr[1:], AddressOptions{NetworkPrefix: "t"}), `doesn't match the network prefix "t"`)
+
+	for s, reason := range map[string]string{
+		"0x0000000000000000000000000000000000000000": "zero address",
+		"0x0000000000000000000000000000000000000001": "precompile address",
+		"0x000000000000000000000000000000000000000a": "precompile address",
+		"0xfe00000000000000000000000000000000000003": "precompile address",
+		"t05":  "reserved built-in actor f05",
+		"t099": "reserved built-in actor f099",
+	} {
+		err := check(s, AddressOptions{})
+		require.ErrorIs(t, err, ErrInvalidAddress, s)
+		require.ErrorContains(t, err, reason, s)
+	}
+
+	require.NoError(t, check("0x000000000000000000000000000000000000000b", AddressOptions{}))
+	require.NoError(t, check("t0100", AddressOptions{}))
+}
@@ -0,0 +1,40 @@
+package types
+
+import (
+	"github.com/filecoin-project/go-address"
+)
+
+// MainnetChainID is the EVM chain ID of Filecoin mainnet.
+const MainnetChainID = 314
+
+// NetworkPrefix returns the prefix of Filecoin addresses on the network with the chain ID.
+// Only mainnet uses the f prefix.
+func NetworkPrefix(chainID uint64) string {
+	if chainID == MainnetChainID {
+		return address.MainnetPrefix
+	}
+	return address.TestnetPrefix
+}
+
+// FilecoinAddressString returns the string form of the address with the network prefix.
+func FilecoinAddressString(addr address.Address, prefix string) string {
+	return prefix + addr.String()[1:]
+}
+
+// ProtocolName returns the name of the address protocol.
+func ProtocolName(p address.Protocol) string {
+	switch p {
+	case address.ID:
+		return "id"
+	case address.SECP256K1:
+		return "secp256k1"
+	case address.Actor:
+		return "actor"
+	case address.BLS:
+		return "bls"
+	case address.Delegated:
+		return "delegated"
+	default:
+		return "unknown"
+	}
+}
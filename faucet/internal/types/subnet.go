@@ -0,0 +1,117 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// SubnetID identifies an IPC subnet by the chain ID of the root network
+// and the route of subnet actor addresses from the root to the subnet.
+type SubnetID struct {
+	Root  uint64
+	Route []common.Address
+}
+
+// ParseSubnetID parses a subnet ID in the /r<root>/<f4 address>/... form.
+func ParseSubnetID(s string) (SubnetID, error) {
+	if !strings.HasPrefix(s, "/r") {
+		return SubnetID{}, fmt.Errorf("invalid subnet ID %q: must start with /r", s)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(s, "/"), "/")
+
+	root, err := strconv.ParseUint(strings.TrimPrefix(parts[0], "r"), 10, 64)
+	if err != nil {
+		return SubnetID{}, fmt.Errorf("invalid subnet ID %q root: %w", s, err)
+	}
+
+	id := SubnetID{Root: root, Route: make([]common.Address, 0, len(parts)-1)}
+	for _, p := range parts[1:] {
+		addr, err := EthAddressFromFilecoinAddressString(p)
+		if err != nil {
+			return SubnetID{}, fmt.Errorf("invalid subnet ID %q route: %w", s, err)
+		}
+		id.Route = append(id.Route, addr)
+	}
+
+	return id, nil
+}
+
+// String returns the /r<root>/<f4 address>/... form of the subnet ID.
+func (id SubnetID) String() string {
+	var b strings.Builder
+	b.WriteString("/r" + strconv.FormatUint(id.Root, 10))
+	for _, a := range id.Route {
+		f, err := EthAddress(a).ToFilecoinAddress()
+		if err != nil {
+			b.WriteString("/" + a.Hex())
+			continue
+		}
+		b.WriteString("/" + f.String())
+	}
+	return b.String()
+}
+
+// IsRoot reports whether the subnet ID is a root network.
+func (id SubnetID) IsRoot() bool {
+	return len(id.Route) == 0
+}
+
+// Equal reports whether both subnet IDs identify the same subnet.
+func (id SubnetID) Equal(other SubnetID) bool {
+	if id.Root != other.Root || len(id.Route) != len(other.Route) {
+		return false
+	}
+	for i := range id.Route {
+		if id.Route[i] != other.Route[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// IPCAddress is an address qualified by the subnet it belongs to.
+// IPC tooling prints it in the <subnet ID>:<address> form, e.g. /r314159/t410f...:t410f...,
+// and it is also written as a path whose last segment is the address, e.g. /r314159/t410f....
+type IPCAddress struct {
+	Subnet SubnetID
+	Raw    string
+}
+
+// IsIPCAddress reports whether the string looks like a subnet-qualified address.
+func IsIPCAddress(s string) bool {
+	return strings.HasPrefix(s, "/r")
+}
+
+// ParseIPCAddress splits a subnet-qualified address into the subnet ID and the raw address.
+// Without a colon, the last path segment is the address and the rest is the subnet ID.
+// The raw address is not validated.
+func ParseIPCAddress(s string) (IPCAddress, error) {
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		i = strings.LastIndex(s, "/")
+		if i <= 0 {
+			return IPCAddress{}, fmt.Errorf("invalid IPC address %q: expected <subnet ID>:<address> or <subnet ID>/<address>", s)
+		}
+	}
+
+	subnet, err := ParseSubnetID(s[:i])
+	if err != nil {
+		return IPCAddress{}, err
+	}
+
+	raw := s[i+1:]
+	if raw == "" {
+		return IPCAddress{}, fmt.Errorf("invalid IPC address %q: empty address", s)
+	}
+
+	return IPCAddress{Subnet: subnet, Raw: raw}, nil
+}
+
+// String returns the <subnet ID>:<address> form of the address.
+func (a IPCAddress) String() string {
+	return a.Subnet.String() + ":" + a.Raw
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSubnet     = "/r314159/t410f77hy7xxhflarwxcuequlgxxpk5u4icpqb4cl5ha"
+	testSubnetAddr = "t410f77hy7xxhflarwxcuequlgxxpk5u4icpqb4cl5ha"
+)
+
+func TestParseSubnetID(t *testing.T) {
+	id, err := ParseSubnetID(testSubnet)
+	require.NoError(t, err)
+	require.Equal(t, uint64(314159), id.Root)
+	require.Len(t, id.Route, 1)
+	require.False(t, id.IsRoot())
+	require.Equal(t, testSubnet, id.String())
+
+	root, err := ParseSubnetID("/r314159")
+	require.NoError(t, err)
+	require.True(t, root.IsRoot())
+	require.False(t, root.Equal(id))
+	require.True(t, root.Equal(SubnetID{Root: 314159}))
+
+	for _, s := range []string{"", "r314159", "/rabc", "/r314159/0x01", "/r314159/t1abc"} {
+		_, err = ParseSubnetID(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestParseIPCAddress(t *testing.T) {
+	a, err := ParseIPCAddress(testSubnet + ":" + testSubnetAddr)
+	require.NoError(t, err)
+	require.Equal(t, testSubnet, a.Subnet.String())
+	require.Equal(t, testSubnetAddr, a.Raw)
+	require.Equal(t, testSubnet+":"+testSubnetAddr, a.String())
+
+	a, err = ParseIPCAddress("/r314159:0xFFcf8FDEE72ac11b5c542428B35EEF5769C409f0")
+	require.NoError(t, err)
+	require.True(t, a.Subnet.IsRoot())
+	require.Equal(t, "0xFFcf8FDEE72ac11b5c542428B35EEF5769C409f0", a.Raw)
+
+	require.True(t, IsIPCAddress(testSubnet))
+	require.False(t, IsIPCAddress(testSubnetAddr))
+
+	a, err = ParseIPCAddress(testSubnet)
+	require.NoError(t, err)
+	require.True(t, a.Subnet.IsRoot())
+	require.Equal(t, uint64(314159), a.Subnet.Root)
+	require.Equal(t, testSubnetAddr, a.Raw)
+
+	for _, s := range []string{"/r314159", testSubnet + ":", testSubnet + "/", "/rabc:" + testSubnetAddr} {
+		_, err = ParseIPCAddress(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestSubnetIDEqual(t *testing.T) {
+	a := SubnetID{Root: 1, Route: []common.Address{common.HexToAddress("0x01")}}
+	b := SubnetID{Root: 1, Route: []common.Address{common.HexToAddress("0x01")}}
+	c := SubnetID{Root: 1, Route: []common.Address{common.HexToAddress("0x02")}}
+	require.True(t, a.Equal(b))
+	require.False(t, a.Equal(c))
+}
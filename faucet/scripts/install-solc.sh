@@ -0,0 +1,24 @@
+#!/usr/bin/env bash
+# Installs the solc release the contracts pin, to the path given as argument (/usr/local/bin/solc by default).
+# The binary is only installed if its sha256 is the one published for the release in the solc-bin list
+# of binaries.soliditylang.org, which is hosted apart from the GitHub release it is downloaded from.
+set -euo pipefail
+
+SOLC_VERSION=0.8.23
+dest=${1:-/usr/local/bin/solc}
+
+tmp=$(mktemp -d)
+trap 'rm -rf "$tmp"' EXIT
+
+curl -sSfL -o "$tmp/solc" "https://github.com/ethereum/solidity/releases/download/v$SOLC_VERSION/solc-static-linux"
+curl -sSfL -o "$tmp/list.json" "https://binaries.soliditylang.org/linux-amd64/list.json"
+
+expected=$(jq -r --arg v "$SOLC_VERSION" '.builds[] | select(.version == $v) | .sha256' "$tmp/list.json")
+expected=${expected#0x}
+if [ -z "$expected" ]; then
+  echo "no sha256 published for solc $SOLC_VERSION" >&2
+  exit 1
+fi
+echo "$expected  $tmp/solc" | sha256sum --check --strict -
+
+install -m 0755 "$tmp/solc" "$dest"
@@ -1,4 +1,5 @@
 const FAUCET_BACKEND="{{.}}";
+const FAUCET_API=FAUCET_BACKEND.substring(0, FAUCET_BACKEND.lastIndexOf("/"));
 // When DOM is loaded this
 // function will get executed
 $(() => {
@@ -18,26 +19,134 @@ $(() => {
             data: data,
             timeout: 120_000,
             success: function(data, status, xhr) {
-                successAlert();
+                if (data.voucher) {
+                    voucherAlert(data.voucher, data.next_reset);
+                } else {
+                    successAlert(data.next_reset);
+                }
+                accountInfo($('#address').val());
             },
             error: function(jqXhr, textStatus, errorThrown) {
                 console.log("ajax error: ", errorThrown)
                 if (jqXhr != null && jqXhr.responseText != null ) {
                     resp = $.parseJSON(jqXhr.responseText);
-                    errorAlert(resp.errors[0]);
+                    reset = jqXhr.getResponseHeader("X-RateLimit-Reset");
+                    if (jqXhr.status == 429 && reset != null) {
+                        errorAlert(resp.errors[0] + resetNote(new Date(reset * 1000)));
+                    } else {
+                        errorAlert(resp.errors[0]);
+                    }
                 } else {
                     errorAlert(errorThrown);
                 }
             }
         });
-    });});
+    });
+
+    $('#checkBtn').on('click', function(e){
+        e.preventDefault();
+        accountInfo($('#address').val());
+    });
+
+    $('#convertBtn').on('click', function(e){
+        e.preventDefault();
+        convertAddress($('#address').val());
+    });
+});
+
+function accountInfo(address) {
+    if (!address) {
+        return;
+    }
+    $.ajax({
+        type: "GET",
+        url: FAUCET_API + "/accounts/" + encodeURIComponent(address.trim()),
+        crossDomain: true,
+        timeout: 30_000,
+        success: function(data, status, xhr) {
+            accountPanel(data);
+        },
+        error: function(jqXhr, textStatus, errorThrown) {
+            console.log("ajax error: ", errorThrown)
+            $('#account-info').html("");
+        }
+    });
+}
+
+function accountPanel(info) {
+    lastGrant = info.last_grant ? new Date(info.last_grant).toLocaleString() : "never";
+    nextReset = info.next_reset ? new Date(info.next_reset).toLocaleString() : "-";
+    $('#account-info').html(`<table class="table table-dark table-sm text-start">
+  <tr><th>Address</th><td>${info.address}<br>${info.filecoin_address}</td></tr>
+  <tr><th>Balance (attoFIL)</th><td>${info.balance}</td></tr>
+  <tr><th>Received in current window</th><td>${info.received}</td></tr>
+  <tr><th>You can still claim</th><td>${info.remaining}</td></tr>
+  <tr><th>Next reset</th><td>${nextReset}</td></tr>
+  <tr><th>Last grant</th><td>${lastGrant} ${info.last_tx_hash || ""}</td></tr>
+  <tr><th>Faucet budget left</th><td>${info.total_remaining}</td></tr>
+</table>`);
+}
 
-function successAlert() {
+function convertAddress(address) {
+    if (!address) {
+        return;
+    }
+    $.ajax({
+        type: "GET",
+        url: FAUCET_API + "/convert/" + encodeURIComponent(address.trim()),
+        crossDomain: true,
+        timeout: 30_000,
+        success: function(data, status, xhr) {
+            addressPanel(data);
+        },
+        error: function(jqXhr, textStatus, errorThrown) {
+            console.log("ajax error: ", errorThrown)
+            $('#address-forms').html("");
+            if (jqXhr != null && jqXhr.responseText != null) {
+                errorAlert($.parseJSON(jqXhr.responseText).errors[0]);
+            }
+        }
+    });
+}
+
+function addressPanel(forms) {
+    rows = [
+        ["Type", forms.type],
+        ["Ethereum", forms.ethereum],
+        ["Filecoin", forms.filecoin],
+        ["Mainnet", forms.mainnet],
+        ["Testnet", forms.testnet],
+        ["ID", forms.id],
+        ["Masked ID", forms.masked_id],
+    ].filter(row => row[1]).map(row => `<tr><th>${row[0]}</th><td>${row[1]}</td></tr>`);
+    $('#address-forms').html(`<table class="table table-dark table-sm text-start">
+  ${rows.join("\n  ")}
+</table>`);
+}
+
+function successAlert(nextReset) {
     $('#result-msg').html(`<div class="alert alert-success" role="alert">
   Congratulations! Your Mycelium Calibration funds are on their way! 👾
+  ${resetNote(nextReset)}
   </div>`);
 }
 
+function voucherAlert(voucher, nextReset) {
+    $('#result-msg').html(`<div class="alert alert-success text-break" role="alert">
+  Your voucher is ready! Redeem it before ${new Date(voucher.expiry * 1000).toLocaleString()}
+  by sending a transaction to <code>${voucher.contract}</code> with the data below. 👾
+  <textarea class="form-control mt-2" rows="4" readonly>${voucher.calldata}</textarea>
+  ${resetNote(nextReset)}
+  </div>`);
+}
+
+function resetNote(nextReset) {
+    if (!nextReset) {
+        return "";
+    }
+    return `<br>Your limit resets at ${new Date(nextReset).toLocaleString()}.`;
+}
+
 function errorAlert(err) {
     $('#result-msg').html(`<div class="alert alert-danger" role="alert">
   Error requesting token funds: ${err} 🫠